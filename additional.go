@@ -0,0 +1,48 @@
+package schemator
+
+import (
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// WithAdditionalProperties returns an Option allowing properties not
+// declared by struct type T in objects of T, for types that intentionally
+// carry vendor extension fields. Other structs keep rejecting unknown
+// properties with "additionalProperties": false.
+//
+//	gen := schemator.NewGenerator(ctx,
+//		schemator.WithAdditionalProperties[Extensible](),
+//	)
+func WithAdditionalProperties[T any]() Option {
+	t := derefType(reflect.TypeFor[T]())
+	return func(g *generator) {
+		if g.additionalProperties == nil {
+			g.additionalProperties = make(map[reflect.Type]bool)
+		}
+		g.additionalProperties[t] = true
+	}
+}
+
+// additionalPropertiesDirective allows undeclared properties in a struct
+// type from its doc comment, as an alternative to WithAdditionalProperties:
+//
+//	// Extensible carries vendor extensions next to its declared fields.
+//	// schemator:additionalproperties
+//	type Extensible struct{ ... }
+const additionalPropertiesDirective = schematorDirective + "additionalproperties"
+
+// applyAdditionalProperties is the schema pass removing
+// "additionalProperties": false from the structs allowed undeclared
+// properties.
+func (g *generator) applyAdditionalProperties(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if ts.AdditionalProperties != jsonschema.FalseSchema {
+			return nil
+		}
+		if g.additionalProperties[t] || hasMarker(rf.markers[typeKey(t)], additionalPropertiesDirective) {
+			ts.AdditionalProperties = nil
+		}
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// OpenVendorData carries vendor extension fields.
+// schemator:additionalproperties
+type OpenVendorData struct {
+	Vendor string `json:"vendor"`
+}
+
+// OpenMetadata is allowed extra properties by option.
+type OpenMetadata struct {
+	Name string `json:"name"`
+}
+
+// OpenClosed rejects unknown properties.
+type OpenClosed struct {
+	ID string `json:"id"`
+}
+
+// OpenDocument mixes open and closed types.
+type OpenDocument struct {
+	Vendor   OpenVendorData `json:"vendor"`
+	Metadata *OpenMetadata  `json:"metadata"`
+	Closed   OpenClosed     `json:"closed"`
+}
+
+func TestAdditionalProperties(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithAdditionalProperties[*OpenMetadata]()).Generate(OpenDocument{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		AdditionalProperties *bool                                           `json:"additionalProperties"`
+		Defs                 map[string]struct{ AdditionalProperties *bool } `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.AdditionalProperties == nil || *doc.AdditionalProperties {
+		t.Errorf("root additionalProperties = %v, want false", doc.AdditionalProperties)
+	}
+	for name, open := range map[string]bool{"OpenVendorData": true, "OpenMetadata": true, "OpenClosed": false} {
+		ap := doc.Defs[name].AdditionalProperties
+		if open && ap != nil {
+			t.Errorf("%s additionalProperties = %v, want unset", name, *ap)
+		}
+		if !open && (ap == nil || *ap) {
+			t.Errorf("%s additionalProperties = %v, want false", name, ap)
+		}
+	}
+}
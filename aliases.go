@@ -0,0 +1,252 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// AliasPolicy selects how fields declared with a type alias
+// (type UserID = uuid.UUID) are described. Aliases are invisible to
+// reflection, so schemator finds them in the source code.
+type AliasPolicy int
+
+const (
+	// AliasInline describes a field of an alias type like a field of its
+	// target type, as the alias does not exist at run time (default).
+	AliasInline AliasPolicy = iota
+	// AliasDefinition gives every alias used by a field a definition of its
+	// own, named after the alias and described by its doc comment, which
+	// refers to or describes the target type. Fields of the alias refer to
+	// it.
+	AliasDefinition
+)
+
+func (p AliasPolicy) String() string {
+	switch p {
+	case AliasInline:
+		return "inline"
+	case AliasDefinition:
+		return "definition"
+	}
+	return fmt.Sprintf("AliasPolicy(%d)", int(p))
+}
+
+// fieldType is the type a struct field is declared with in the source.
+type fieldType struct {
+	// key is the CommentMap key of the declared type.
+	key string
+	// elem reports whether the field is a slice of the type.
+	elem bool
+}
+
+// addAliases records the type aliases declared in f and the named types its
+// struct fields are declared with, so fields of an alias can be told from
+// fields of its target.
+func (c *goComments) addAliases(pkgPath string, f *ast.File) {
+	imports := make(map[string]string)
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = p
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			key := pkgPath + "." + ts.Name.Name
+			if ts.Assign.IsValid() && ts.TypeParams == nil {
+				c.aliases[key] = true
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				ft, ok := declaredType(pkgPath, imports, field.Type)
+				if !ok {
+					continue
+				}
+				for _, name := range field.Names {
+					c.fieldTypes[key+"."+name.Name] = ft
+				}
+			}
+		}
+	}
+}
+
+// declaredType returns the named type expr spells, looking through pointers
+// and into slices.
+func declaredType(pkgPath string, imports map[string]string, expr ast.Expr) (fieldType, bool) {
+	var ft fieldType
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+			continue
+		case *ast.ArrayType:
+			if e.Len != nil || ft.elem {
+				return ft, false
+			}
+			ft.elem = true
+			expr = e.Elt
+			continue
+		case *ast.Ident:
+			ft.key = pkgPath + "." + e.Name
+		case *ast.SelectorExpr:
+			pkg, ok := e.X.(*ast.Ident)
+			if !ok || imports[pkg.Name] == "" {
+				return ft, false
+			}
+			ft.key = imports[pkg.Name] + "." + e.Sel.Name
+		default:
+			return ft, false
+		}
+		return ft, true
+	}
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName returns the name a package is imported under by default,
+// guessed from its path: example.com/uuid/v2 is uuid and gopkg.in/yaml.v3 is
+// yaml.
+func importName(p string) string {
+	name := path.Base(p)
+	if majorVersion.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// applyAliasDefinitions is the schema pass moving the schemas of fields
+// declared with a type alias into a definition of the alias.
+func (g *generator) applyAliasDefinitions(rf *reflection, s *jsonschema.Schema) error {
+	type aliasField struct {
+		fv    fieldVisit
+		alias fieldType
+	}
+	// Definitions are added while referring to them, so collect the fields
+	// first.
+	var fields []aliasField
+	_ = rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if alias, ok := rf.aliases[typeKey(fv.owner)+"."+fv.field.Name]; ok {
+				fields = append(fields, aliasField{fv, alias})
+			}
+			return nil
+		})
+	})
+	added := make(map[string]bool)
+	for _, af := range fields {
+		fv, alias := af.fv, af.alias
+		node, ft := fv.schema, derefType(fv.field.Type)
+		if alias.elem {
+			if node.Items == nil || ft.Kind() != reflect.Slice {
+				continue
+			}
+			node, ft = node.Items, derefType(ft.Elem())
+		}
+		name := alias.key[strings.LastIndexByte(alias.key, '.')+1:]
+		if !added[name] {
+			if err := g.addAliasDefinition(rf, s, name, alias.key, node, ft); err != nil {
+				return fmt.Errorf("%s: %w", fieldRef(fv.owner, fv.field), err)
+			}
+			added[name] = true
+		}
+		if err := referAlias(node, s.Definitions[name], name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAliasDefinition adds the definition name of the alias key, whose target
+// type ft node describes.
+func (g *generator) addAliasDefinition(rf *reflection, s *jsonschema.Schema, name, key string, node *jsonschema.Schema, ft reflect.Type) error {
+	if _, exists := s.Definitions[name]; exists {
+		return fmt.Errorf("alias %s collides with the definition %s", key, name)
+	}
+	if s.Definitions == nil {
+		s.Definitions = make(jsonschema.Definitions)
+	}
+	def := &jsonschema.Schema{Ref: node.Ref}
+	if node.Ref == "" {
+		// The target is described inline, so describe it on its own, which
+		// a Reflector only does for types other than structs when it does
+		// not expand the root.
+		ts, err := g.reflectWith(reflect.New(ft).Elem().Interface(), func(r *jsonschema.Reflector) {
+			r.ExpandedStruct = false
+		})
+		if err != nil {
+			return fmt.Errorf("alias %s: %w", key, err)
+		}
+		for defName, d := range ts.Definitions {
+			if _, exists := s.Definitions[defName]; !exists {
+				s.Definitions[defName] = d
+			}
+		}
+		def = ts
+		def.Definitions = nil
+		def.Version = ""
+		def.ID = ""
+		def.Title = ""
+	}
+	if doc := rf.CommentMap[key]; doc != "" {
+		def.Description = doc
+	}
+	s.Definitions[name] = def
+	return nil
+}
+
+// referAlias turns node into a reference to the alias definition def named
+// name, keeping the keywords the field adds to the description of the type.
+func referAlias(node, def *jsonschema.Schema, name string) error {
+	var own, shared map[string]any
+	for _, v := range []struct {
+		s *jsonschema.Schema
+		m *map[string]any
+	}{{node, &own}, {def, &shared}} {
+		*v.m = make(map[string]any)
+		if isBooleanSchema(v.s) {
+			continue
+		}
+		b, err := json.Marshal(v.s)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, v.m); err != nil {
+			return err
+		}
+	}
+	for k, v := range own {
+		if k == "$ref" || compactJSON(v) == compactJSON(shared[k]) {
+			delete(own, k)
+		}
+	}
+	own["$ref"] = "#/$defs/" + name
+	b, err := json.Marshal(own)
+	if err != nil {
+		return err
+	}
+	*node = jsonschema.Schema{}
+	return json.Unmarshal(b, node)
+}
@@ -0,0 +1,96 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// AliasUserID identifies a user.
+type AliasUserID = string
+
+// AliasStamp is when something happened.
+type AliasStamp = time.Time
+
+// AliasAccount is an account.
+type AliasAccount struct {
+	Name string `json:"name"`
+}
+
+// AliasOwner is the owning account.
+type AliasOwner = AliasAccount
+
+type AliasRecord struct {
+	// ID of the record owner.
+	ID       AliasUserID   `json:"id" jsonschema:"minLength=3"`
+	Members  []AliasUserID `json:"members"`
+	Created  *AliasStamp   `json:"created,omitempty"`
+	Owner    AliasOwner    `json:"owner"`
+	Account  AliasAccount  `json:"account"`
+	Nickname string        `json:"nickname"`
+}
+
+func TestAliasPolicy(t *testing.T) {
+	generate := func(opts ...Option) map[string]any {
+		t.Helper()
+		out, err := NewGenerator(context.Background(), opts...).Generate(AliasRecord{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	props := func(doc map[string]any) map[string]any {
+		return doc["properties"].(map[string]any)
+	}
+
+	doc := generate()
+	if got := compactJSON(props(doc)["id"]); got != `{"description":"ID of the record owner.","minLength":3,"type":"string"}` {
+		t.Errorf("inline id = %s", got)
+	}
+	if _, ok := doc["$defs"].(map[string]any)["AliasUserID"]; ok {
+		t.Error("AliasInline added a definition of the alias")
+	}
+
+	doc = generate(WithAliasPolicy(AliasDefinition))
+	defs := doc["$defs"].(map[string]any)
+	for prop, want := range map[string]string{
+		"id":       `{"$ref":"#/$defs/AliasUserID","description":"ID of the record owner.","minLength":3}`,
+		"members":  `{"items":{"$ref":"#/$defs/AliasUserID"},"type":"array"}`,
+		"created":  `{"$ref":"#/$defs/AliasStamp"}`,
+		"owner":    `{"$ref":"#/$defs/AliasOwner"}`,
+		"account":  `{"$ref":"#/$defs/AliasAccount"}`,
+		"nickname": `{"type":"string"}`,
+	} {
+		if got := compactJSON(props(doc)[prop]); got != want {
+			t.Errorf("%s = %s, want %s", prop, got, want)
+		}
+	}
+	for name, want := range map[string]string{
+		"AliasUserID": `{"description":"AliasUserID identifies a user.","type":"string"}`,
+		"AliasStamp":  `{"description":"AliasStamp is when something happened.","format":"date-time","type":"string"}`,
+		"AliasOwner":  `{"$ref":"#/$defs/AliasAccount","description":"AliasOwner is the owning account."}`,
+	} {
+		if got := compactJSON(defs[name]); got != want {
+			t.Errorf("$defs/%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestImportName(t *testing.T) {
+	for p, want := range map[string]string{
+		"github.com/google/uuid": "uuid",
+		"example.com/uuid/v2":    "uuid",
+		"gopkg.in/yaml.v3":       "yaml",
+		"example.com/go-semver":  "go_semver",
+		"encoding/json":          "json",
+	} {
+		if got := importName(p); got != want {
+			t.Errorf("importName(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
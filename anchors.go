@@ -0,0 +1,74 @@
+package schemator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// RefMode selects how schemas refer to definitions.
+type RefMode int
+
+const (
+	// RefPointer refers with JSON pointers into $defs, such as
+	// {"$ref": "#/$defs/User"} (default).
+	RefPointer RefMode = iota
+	// RefAnchor names every definition with "$anchor" and refers to it by
+	// name, such as {"$ref": "#User"}.
+	RefAnchor
+	// RefDynamicAnchor names every definition with "$dynamicAnchor" and
+	// refers to it with "$dynamicRef", such as {"$dynamicRef": "#User"}.
+	RefDynamicAnchor
+)
+
+func (m RefMode) String() string {
+	switch m {
+	case RefPointer:
+		return "pointer"
+	case RefAnchor:
+		return "anchor"
+	case RefDynamicAnchor:
+		return "dynamic-anchor"
+	}
+	return fmt.Sprintf("RefMode(%d)", int(m))
+}
+
+// anchorName matches the plain names $anchor and $dynamicAnchor accept.
+var anchorName = regexp.MustCompile(`^[A-Za-z_][-A-Za-z0-9._]*$`)
+
+// anchorRefs rewrites the references into s.Definitions to refer to anchors
+// as mode says. Definitions whose names cannot be anchors keep pointer
+// references.
+func anchorRefs(s *jsonschema.Schema, mode RefMode) {
+	if mode == RefPointer {
+		return
+	}
+	for name, def := range s.Definitions {
+		if !anchorName.MatchString(name) || isBooleanSchema(def) {
+			continue
+		}
+		if mode == RefAnchor {
+			def.Anchor = name
+		} else {
+			setExtra(def, "$dynamicAnchor", name)
+		}
+	}
+	_ = walkSchema(s, func(n *jsonschema.Schema) error {
+		name, ok := strings.CutPrefix(n.Ref, "#/$defs/")
+		if !ok {
+			return nil
+		}
+		def := s.Definitions[name]
+		if def == nil || isBooleanSchema(def) || !anchorName.MatchString(name) {
+			return nil
+		}
+		if mode == RefAnchor {
+			n.Ref = "#" + name
+		} else {
+			n.Ref, n.DynamicRef = "", "#"+name
+		}
+		return nil
+	})
+}
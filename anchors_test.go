@@ -0,0 +1,38 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRefMode(t *testing.T) {
+	for _, tc := range []struct {
+		mode       RefMode
+		ref        string
+		definition string
+	}{
+		{RefPointer, `{"$ref":"#/$defs/NamerAddress"}`, `"required"`},
+		{RefAnchor, `{"$ref":"#NamerAddress"}`, `"$anchor":"NamerAddress"`},
+		{RefDynamicAnchor, `{"$dynamicRef":"#NamerAddress"}`, `"$dynamicAnchor":"NamerAddress"`},
+	} {
+		out, err := NewGenerator(context.Background(), WithRefMode(tc.mode)).Generate(NamerCustomer{})
+		if err != nil {
+			t.Fatalf("%s: Generate() error = %v", tc.mode, err)
+		}
+		var doc struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Defs       map[string]json.RawMessage `json:"$defs"`
+		}
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got := compactJSON(doc.Properties["address"]); got != tc.ref {
+			t.Errorf("%s: address = %s, want %s", tc.mode, got, tc.ref)
+		}
+		if def := compactJSON(doc.Defs["NamerAddress"]); !strings.Contains(def, tc.definition) {
+			t.Errorf("%s: definition = %s, want %s", tc.mode, def, tc.definition)
+		}
+	}
+}
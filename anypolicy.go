@@ -0,0 +1,103 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// AnyPolicy selects how fields of interface types without a schema, such as
+// any and interface{}, are described. Fields described by a jsonschema tag,
+// a marker, a registered union or a `schemator:"any=..."` tag are left
+// alone.
+type AnyPolicy int
+
+const (
+	// AnyPermissive accepts any value, the `true` schema (default).
+	AnyPermissive AnyPolicy = iota
+	// AnyObject accepts any JSON object, {"type": "object"}.
+	AnyObject
+	// AnyError fails generation, so every such field has to be described.
+	AnyError
+)
+
+func (p AnyPolicy) String() string {
+	switch p {
+	case AnyPermissive:
+		return "permissive"
+	case AnyObject:
+		return "object"
+	case AnyError:
+		return "error"
+	}
+	return fmt.Sprintf("AnyPolicy(%d)", int(p))
+}
+
+// parseAnyPolicy parses the name of an AnyPolicy.
+func parseAnyPolicy(name string) (AnyPolicy, error) {
+	for _, p := range []AnyPolicy{AnyPermissive, AnyObject, AnyError} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown any policy %q", name)
+}
+
+// applyAnyPolicy is the schema pass describing the interface fields, and
+// elements of slice fields, that accept any value as g.anyPolicy or their
+// `schemator:"any=..."` tag says.
+func (g *generator) applyAnyPolicy(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if ts.Properties == nil {
+			return nil
+		}
+		// The shared true schema of a property cannot be changed in
+		// place; an empty schema marshals the same. Those left unchanged
+		// are put back, so other passes keep skipping them.
+		swapped := make(map[*jsonschema.Schema]bool)
+		for pair := ts.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value == jsonschema.TrueSchema {
+				pair.Value = &jsonschema.Schema{}
+				swapped[pair.Value] = true
+			}
+		}
+		defer func() {
+			for pair := ts.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if swapped[pair.Value] && pair.Value.Type == "" {
+					pair.Value = jsonschema.TrueSchema
+				}
+			}
+		}()
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			policy := g.anyPolicy
+			if tag, ok := fv.field.Tag.Lookup("schemator"); ok {
+				st, err := parseSchematorTag(tag)
+				if err != nil {
+					return fmt.Errorf("%s: %w", fieldRef(fv.owner, fv.field), err)
+				}
+				if st.anyPolicy != nil {
+					policy = *st.anyPolicy
+				}
+			}
+			if policy == AnyPermissive {
+				return nil
+			}
+			node, ft := fv.schema, derefType(fv.field.Type)
+			if k := ft.Kind(); (k == reflect.Slice || k == reflect.Array) && node.Items != nil {
+				if node.Items == jsonschema.TrueSchema {
+					node.Items = &jsonschema.Schema{}
+				}
+				node, ft = node.Items, derefType(ft.Elem())
+			}
+			if ft.Kind() != reflect.Interface || !acceptsAnything(node) {
+				return nil
+			}
+			if policy == AnyError {
+				return fmt.Errorf("%s: %s accepts any value; describe it with a tag or marker", fieldRef(fv.owner, fv.field), ft)
+			}
+			node.Type = "object"
+			return nil
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type AnyPayload struct {
+	Data    any            `json:"data"`
+	Items   []any          `json:"items"`
+	Meta    any            `json:"meta" schemator:"any=permissive"`
+	Typed   any            `json:"typed" jsonschema:"type=string"`
+	Name    string         `json:"name"`
+	Options map[string]any `json:"options"`
+}
+
+func TestAnyPolicy(t *testing.T) {
+	generate := func(opts ...Option) (map[string]any, error) {
+		out, err := NewGenerator(context.Background(), opts...).Generate(AnyPayload{})
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc["properties"].(map[string]any), nil
+	}
+	for _, tc := range []struct {
+		opts []Option
+		want map[string]string
+	}{
+		{nil, map[string]string{
+			"data":  `true`,
+			"items": `{"items":true,"type":"array"}`,
+			"meta":  `true`,
+			"typed": `{"type":"string"}`,
+		}},
+		{[]Option{WithAnyPolicy(AnyObject)}, map[string]string{
+			"data":    `{"type":"object"}`,
+			"items":   `{"items":{"type":"object"},"type":"array"}`,
+			"meta":    `true`,
+			"typed":   `{"type":"string"}`,
+			"options": `{"type":"object"}`,
+		}},
+	} {
+		props, err := generate(tc.opts...)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		for name, want := range tc.want {
+			if got := compactJSON(props[name]); got != want {
+				t.Errorf("%s = %s, want %s", name, got, want)
+			}
+		}
+	}
+
+	_, err := generate(WithAnyPolicy(AnyError))
+	if err == nil || !strings.Contains(err.Error(), "AnyPayload.Data: interface {} accepts any value") {
+		t.Fatalf("AnyError: error = %v", err)
+	}
+}
+
+type AnyTagged struct {
+	Data any `json:"data" schemator:"any=object"`
+}
+
+func TestAnyPolicyTag(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithAnyPolicy(AnyError)).Generate(AnyTagged{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(out), `"data": {
+      "type": "object"
+    }`) {
+		t.Errorf("schema = %s", out)
+	}
+	if _, err := parseSchematorTag("any=strict"); err == nil {
+		t.Error("unknown any policy accepted")
+	}
+}
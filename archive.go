@@ -0,0 +1,75 @@
+package schemator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+
+	"pkt.systems/logport"
+)
+
+func (g *generator) WriteSchemasArchive(archivePath string, models ...any) error {
+	l := logport.LoggerFromContext(g.ctx).With("archive", archivePath, "models", models)
+	filenames, err := g.schemaFilenames(models)
+	if err != nil {
+		return err
+	}
+	created, err := sourceDate()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	addFile := func(name string, content []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(g.perms.file),
+			Size:     int64(len(content)),
+			ModTime:  created,
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+	results, genErr := g.generateEach(models, filenames, "")
+	if results == nil {
+		return genErr
+	}
+	m := &Manifest{Artifacts: []Artifact{}}
+	for i, model := range models {
+		filename := filenames[i]
+		if filename == "" {
+			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", model)
+			continue
+		}
+		if results[i].err != nil {
+			continue
+		}
+		out := results[i].out
+		if err := addFile(filename, g.fileContent(out)); err != nil {
+			return err
+		}
+		a := g.newArtifact(filename, model, JSONSchemaFormat.Name, out)
+		a.Created = created
+		m.Artifacts = append(m.Artifacts, a)
+	}
+	manifest, err := encodeManifest(m)
+	if err != nil {
+		return err
+	}
+	if err := addFile(ManifestFilename, manifest); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return errors.Join(genErr, g.write(archivePath, buf.Bytes()))
+}
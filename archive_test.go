@@ -0,0 +1,76 @@
+package schemator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteSchemasArchive(t *testing.T) {
+	t.Setenv(sourceDateEpochEnv, "1700000000")
+	archive := filepath.Join(t.TempDir(), "dist", "schemas.tar.gz")
+	gen := NewGenerator(context.Background(), WithVersion("1.0.0"))
+	if err := gen.WriteSchemasArchive(archive, OrderedBase{}, OrderedRecord{}); err != nil {
+		t.Fatalf("WriteSchemasArchive() error = %v", err)
+	}
+	first, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(zr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !h.ModTime.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("%s modified %v", h.Name, h.ModTime)
+		}
+		if files[h.Name], err = io.ReadAll(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := gen.Generate(OrderedBase{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := files["OrderedBase.1.0.0.schema.json"]; string(got) != string(want)+"\n" {
+		t.Errorf("OrderedBase.1.0.0.schema.json = %s", got)
+	}
+	var m Manifest
+	if err := json.Unmarshal(files[ManifestFilename], &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 2 || m.Artifacts[0].File != "OrderedBase.1.0.0.schema.json" || m.Artifacts[0].SHA256 != checksum(files["OrderedBase.1.0.0.schema.json"]) {
+		t.Errorf("manifest = %+v", m.Artifacts)
+	}
+	if len(files) != 3 {
+		t.Errorf("archive holds %d files, want 3", len(files))
+	}
+
+	if err := gen.WriteSchemasArchive(archive, OrderedBase{}, OrderedRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("archive differs between runs")
+	}
+}
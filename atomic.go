@@ -0,0 +1,52 @@
+package schemator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes data to name with permissions perm through a
+// temporary file in the same directory renamed into place, so name is never
+// left truncated by a failed or interrupted write. A file already holding
+// data with permissions perm is left alone, keeping its modification time for
+// incremental build tools.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) (err error) {
+	if unchangedFile(name, data, perm) {
+		return nil
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err := f.Write(data); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	if err := f.Chmod(perm); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// unchangedFile reports whether name is a regular file with permissions perm
+// holding data.
+func unchangedFile(name string, data []byte, perm os.FileMode) bool {
+	info, err := os.Stat(name)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm() != perm || info.Size() != int64(len(data)) {
+		return false
+	}
+	existing, err := os.ReadFile(name)
+	return err == nil && bytes.Equal(existing, data)
+}
@@ -0,0 +1,73 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "Model.schema.json")
+	if err := writeFileAtomic(name, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 || info.Size() != 3 {
+		t.Errorf("wrote %v of %d bytes", info.Mode().Perm(), info.Size())
+	}
+
+	// Renaming onto a non-empty directory fails after the data is written.
+	blocked := filepath.Join(dir, "Blocked.schema.json")
+	if err := os.MkdirAll(filepath.Join(blocked, "child"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(blocked, []byte("{}\n"), 0o644); err == nil {
+		t.Fatal("writeFileAtomic() onto a directory succeeded")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "Model.schema.json" && e.Name() != "Blocked.schema.json" {
+			t.Errorf("left %s behind", e.Name())
+		}
+	}
+}
+
+func TestUnchangedFilesAreNotRewritten(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "OrderedBase.schema.json")
+	if err := NewGenerator(context.Background()).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(name, past, past); err != nil {
+		t.Fatal(err)
+	}
+	modTime := func() time.Time {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.ModTime()
+	}
+	if err := NewGenerator(context.Background()).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if got := modTime(); !got.Equal(past) {
+		t.Errorf("unchanged file rewritten at %v", got)
+	}
+	if err := NewGenerator(context.Background(), WithCompact()).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if got := modTime(); got.Equal(past) {
+		t.Error("changed file not rewritten")
+	}
+}
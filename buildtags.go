@@ -0,0 +1,51 @@
+package schemator
+
+import (
+	"go/build"
+	"io/fs"
+	"runtime/debug"
+	"strings"
+)
+
+// buildContext returns the build context deciding which source files
+// comments are read from: that of the running binary, built for its GOOS
+// and GOARCH with its build tags, adjusted by WithBuildConstraints.
+func buildContext(goos, goarch string, tags []string) *build.Context {
+	bc := build.Default
+	if goos != "" {
+		bc.GOOS = goos
+	}
+	if goarch != "" {
+		bc.GOARCH = goarch
+	}
+	bc.BuildTags = append(binaryBuildTags(), tags...)
+	return &bc
+}
+
+// binaryBuildTags returns the -tags the running binary was built with.
+func binaryBuildTags() []string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "-tags" && s.Value != "" {
+			return strings.Split(s.Value, ",")
+		}
+	}
+	return nil
+}
+
+// sourceFilter returns the parser.ParseDir filter keeping the files of dir
+// bc builds, or nil to keep all files.
+func sourceFilter(bc *build.Context, dir string) func(fs.FileInfo) bool {
+	if bc == nil {
+		return nil
+	}
+	return func(fi fs.FileInfo) bool {
+		ok, err := bc.MatchFile(dir, fi.Name())
+		// Files whose constraints cannot be read are parsed, the parser
+		// reports what is wrong with them.
+		return ok || err != nil
+	}
+}
@@ -0,0 +1,70 @@
+package schemator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildConstraints(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "types.go"), `package foo
+
+// Host describes the host.
+type Host struct {
+	Name string
+}
+`)
+	writeFile(t, filepath.Join(dir, "host_linux.go"), `package foo
+
+// Platform is the Linux platform.
+type Platform struct {
+	// Cgroup is the cgroup version.
+	Cgroup int
+}
+`)
+	writeFile(t, filepath.Join(dir, "host_windows.go"), `package foo
+
+// Platform is the Windows platform.
+type Platform struct {
+	// Build is the Windows build number.
+	Build int
+}
+`)
+	writeFile(t, filepath.Join(dir, "extra.go"), `//go:build extra
+
+package foo
+
+// Extra is only built with the extra tag.
+type Extra struct{}
+`)
+	for _, tt := range []struct {
+		goos      string
+		tags      []string
+		platform  string
+		field     string
+		withExtra bool
+	}{
+		{"linux", nil, "Platform is the Linux platform.", "Cgroup", false},
+		{"windows", nil, "Platform is the Windows platform.", "Build", false},
+		{"linux", []string{"extra"}, "Platform is the Linux platform.", "Cgroup", true},
+	} {
+		gen := NewGenerator(context.Background(), WithBuildConstraints(tt.goos, "amd64", tt.tags...)).(*generator)
+		c, err := gen.comments.extract(context.Background(), ImportPath{ModuleImportPath: "example.com/foo", SourceDirectory: dir}, nil)
+		if err != nil {
+			t.Fatalf("%s: extract() error = %v", tt.goos, err)
+		}
+		if got := c.text["example.com/foo.Platform"]; got != tt.platform {
+			t.Errorf("%s: Platform = %q, want %q", tt.goos, got, tt.platform)
+		}
+		if _, ok := c.text["example.com/foo.Platform."+tt.field]; !ok {
+			t.Errorf("%s: %s has no comment", tt.goos, tt.field)
+		}
+		if got := c.text["example.com/foo.Host"]; got != "Host describes the host." {
+			t.Errorf("%s: Host = %q", tt.goos, got)
+		}
+		if _, ok := c.text["example.com/foo.Extra"]; ok != tt.withExtra {
+			t.Errorf("%s %v: Extra documented = %v", tt.goos, tt.tags, ok)
+		}
+	}
+}
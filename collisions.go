@@ -0,0 +1,170 @@
+package schemator
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FilenameCollisionPolicy selects what happens when models of different
+// packages are written to the same file, such as v1.User and v2.User both to
+// User.schema.json.
+type FilenameCollisionPolicy int
+
+const (
+	// FilenameCollisionError fails the write (default).
+	FilenameCollisionError FilenameCollisionPolicy = iota
+	// FilenameCollisionQualify puts the package name and a dot in front of
+	// the filename of every colliding model (v1.User.schema.json), adding
+	// more of the import path while the names still collide.
+	FilenameCollisionQualify
+)
+
+func (p FilenameCollisionPolicy) String() string {
+	switch p {
+	case FilenameCollisionError:
+		return "error"
+	case FilenameCollisionQualify:
+		return "qualify"
+	}
+	return fmt.Sprintf("FilenameCollisionPolicy(%d)", int(p))
+}
+
+// schemaFilenames returns the filename of the schema of every model, "" for
+// models without one, with collisions handled as configured.
+func (g *generator) schemaFilenames(models []any) ([]string, error) {
+	names := make([]string, len(models))
+	for i, model := range models {
+		names[i] = g.schemaFilename(model)
+	}
+	return g.uniqueFilenames(models, names)
+}
+
+// uniqueFilenames checks that no two models of different types share a name
+// in names, the filename of each model, and returns names with collisions
+// qualified for FilenameCollisionQualify.
+func (g *generator) uniqueFilenames(models []any, names []string) ([]string, error) {
+	if g.strict {
+		for i, name := range names {
+			if name == "" {
+				return nil, unnamedModelError(models[i])
+			}
+		}
+	}
+	types := make(map[string][]reflect.Type)
+	for i, name := range names {
+		if t := derefType(reflect.TypeOf(models[i])); name != "" && !slices.Contains(types[name], t) {
+			types[name] = append(types[name], t)
+		}
+	}
+	var collisions []string
+	qualified := make(map[reflect.Type]string)
+	for _, name := range slices.Sorted(maps.Keys(types)) {
+		owners := types[name]
+		if len(owners) < 2 {
+			continue
+		}
+		if g.filenameCollisions == FilenameCollisionQualify {
+			if renamed, ok := qualifyFilenames(name, owners, types); ok {
+				for i, t := range owners {
+					qualified[t] = renamed[i]
+				}
+				continue
+			}
+		}
+		verb := "are all"
+		if len(owners) == 2 {
+			verb = "are both"
+		}
+		collisions = append(collisions, fmt.Sprintf("%s %s written to %s", typeList(owners), verb, name))
+	}
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("filenames collide: %s", strings.Join(collisions, "; "))
+	}
+	out := slices.Clone(names)
+	for i, name := range names {
+		if renamed, ok := qualified[derefType(reflect.TypeOf(models[i]))]; ok && name != "" {
+			out[i] = renamed
+		}
+	}
+	return out, nil
+}
+
+// qualifyFilenames returns name with its base prefixed by as many trailing
+// elements of the import path of each of owners as it takes to tell them
+// apart from each other and from the other names in taken.
+func qualifyFilenames(name string, owners []reflect.Type, taken map[string][]reflect.Type) ([]string, bool) {
+	paths := make([][]string, len(owners))
+	longest := 0
+	for i, t := range owners {
+		paths[i] = strings.Split(typePkgPath(t), "/")
+		longest = max(longest, len(paths[i]))
+	}
+	for depth := 1; depth <= longest; depth++ {
+		renamed := make([]string, len(owners))
+		for i, elems := range paths {
+			renamed[i] = path.Join(path.Dir(name), strings.Join(elems[max(0, len(elems)-depth):], ".")+"."+path.Base(name))
+		}
+		unique := true
+		for i, r := range renamed {
+			_, exists := taken[r]
+			unique = unique && !exists && !slices.Contains(renamed[:i], r)
+		}
+		if unique {
+			return renamed, true
+		}
+	}
+	return nil, false
+}
+
+// typePkgPath returns the import path of the package declaring t, or of its
+// element type for unnamed slices, arrays and maps.
+func typePkgPath(t reflect.Type) string {
+	for t != nil && t.Name() == "" && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = derefType(t.Elem())
+	}
+	if t == nil {
+		return ""
+	}
+	return t.PkgPath()
+}
+
+// typeList spells out types as "a, b and c", named types with their import
+// path.
+func typeList(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+		if t.PkgPath() != "" {
+			names[i] = t.PkgPath() + "." + t.Name()
+		}
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// unnamedModelError describes why no filename can be derived for model, for
+// WithStrict.
+func unnamedModelError(model any) error {
+	t := derefType(reflect.TypeOf(model))
+	var reason string
+	switch {
+	case t == nil:
+		reason = "it is nil"
+	case t.Name() != "" && !token.IsExported(t.Name()):
+		reason = "its type is unexported"
+	case t.Name() != "":
+		reason = "its type is predeclared"
+	case t.Kind() == reflect.Struct:
+		reason = "it is an anonymous struct"
+	default:
+		reason = "its type " + t.String() + " has no name"
+	}
+	return fmt.Errorf("%w %T: %s", ErrUnnameableModel, model, reason)
+}
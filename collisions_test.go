@@ -0,0 +1,39 @@
+package schemator
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+func TestFilenameCollisions(t *testing.T) {
+	dir := t.TempDir()
+	models := []any{token.Position{}, &token.Position{}, scanner.Position{}, OrderedBase{}}
+	err := NewGenerator(context.Background()).WriteSchemas(dir, models...)
+	if err == nil || !strings.Contains(err.Error(), "go/token.Position and text/scanner.Position are both written to Position.schema.json") {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("wrote %d files despite the collision", len(entries))
+	}
+	if err := NewGenerator(context.Background()).WriteAll(dir, Output{Model: token.Position{}}, Output{Model: scanner.Position{}, Formats: []Format{XSDFormat}}); err != nil {
+		t.Errorf("WriteAll() with distinct extensions error = %v", err)
+	}
+
+	gen := NewGenerator(context.Background(), WithFilenameCollisions(FilenameCollisionQualify))
+	schemas, err := gen.GenerateAll(models...)
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	for _, name := range []string{"token.Position.schema.json", "scanner.Position.schema.json", "OrderedBase.schema.json"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("GenerateAll() = %d schemas, missing %s", len(schemas), name)
+		}
+	}
+	if len(schemas) != 3 {
+		t.Errorf("GenerateAll() = %d schemas, want 3", len(schemas))
+	}
+}
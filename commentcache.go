@@ -0,0 +1,83 @@
+package schemator
+
+import (
+	"context"
+	"go/build"
+	"path/filepath"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// commentCache holds the comments extracted from packages, so a generator
+// parses each package once however many models it generates. The cached
+// comments are shared and must not be modified. A generator and its
+// WithConcurrency workers share one cache.
+type commentCache struct {
+	// build decides which files of a package are parsed.
+	build    *build.Context
+	mu       sync.Mutex
+	packages map[packageSource]*goComments
+}
+
+// packageSource is a package directory parsed as the package pkgPath.
+type packageSource struct {
+	pkgPath, dir string
+}
+
+func newCommentCache(bc *build.Context) *commentCache {
+	return &commentCache{build: bc, packages: make(map[packageSource]*goComments)}
+}
+
+// extract returns the comments of the packages below ip.SourceDirectory,
+// limited to the packages in wanted unless it is nil, parsing each package
+// on first use. A nil cache parses them every time, from all files. The walk
+// stops when ctx is done.
+func (c *commentCache) extract(ctx context.Context, ip ImportPath, wanted map[string]bool) (*goComments, error) {
+	comments := newGoComments()
+	err := walkPackageDirs(ip.ModuleImportPath, filepath.Clean(ip.SourceDirectory), wanted, func(pkgPath, dir string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pc, err := c.extractPackage(ctx, packageSource{pkgPath: pkgPath, dir: dir})
+		if err != nil {
+			return err
+		}
+		comments.merge(pc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// extractPackage returns the comments of src, parsing it unless cached.
+func (c *commentCache) extractPackage(ctx context.Context, src packageSource) (*goComments, error) {
+	if c == nil {
+		return parsePackageComments(ctx, src, nil)
+	}
+	c.mu.Lock()
+	comments, ok := c.packages[src]
+	c.mu.Unlock()
+	if ok {
+		return comments, nil
+	}
+	comments, err := parsePackageComments(ctx, src, c.build)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.packages[src] = comments
+	return comments, nil
+}
+
+// parsePackageComments is extractPackageComments traced as a child of the
+// span in ctx.
+func parsePackageComments(ctx context.Context, src packageSource, bc *build.Context) (*goComments, error) {
+	_, span := startSpan(ctx, "schemator.ParseComments", attribute.String("schemator.package", src.pkgPath), attribute.String("schemator.dir", src.dir))
+	comments, err := extractPackageComments(src.pkgPath, src.dir, bc)
+	endSpan(span, err)
+	return comments, err
+}
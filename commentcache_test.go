@@ -0,0 +1,48 @@
+package schemator
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestCommentCache(t *testing.T) {
+	gen := NewGenerator(context.Background(), WithCommentFormat(CommentMarkdown)).(*generator)
+	first, err := gen.Generate(EnumPalette{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	packages := maps.Clone(gen.comments.packages)
+	if len(packages) == 0 {
+		t.Fatal("Generate() cached no comments")
+	}
+	// Rendering the cached comments must leave them as extracted, or the
+	// second schema would render them twice.
+	second, err := gen.Generate(EnumPalette{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("second Generate() = %s, want %s", second, first)
+	}
+	for src, comments := range gen.comments.packages {
+		if packages[src] != comments {
+			t.Errorf("comments of %s extracted again", src.pkgPath)
+		}
+	}
+}
+
+func TestCommentsOfReferencedPackages(t *testing.T) {
+	gen := NewGenerator(context.Background()).(*generator)
+	if _, err := gen.Generate(OrderedBase{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var parsed []string
+	for src := range gen.comments.packages {
+		parsed = append(parsed, src.pkgPath)
+	}
+	if !slices.Contains(parsed, "pkt.systems/schemator") || slices.Contains(parsed, "pkt.systems/schemator/schematord") {
+		t.Errorf("parsed %v, want pkt.systems/schemator only", parsed)
+	}
+}
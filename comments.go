@@ -0,0 +1,377 @@
+package schemator
+
+import (
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// goComments holds the doc comments of the types and exported fields of a
+// source tree, keyed like jsonschema.Reflector.CommentMap
+// ("<import path>.<Type>" and "<import path>.<Type>.<Field>").
+type goComments struct {
+	text map[string]string
+	// markers are the comment lines starting with "+" (controller-gen and
+	// kubebuilder markers such as "+optional") and schemator directives
+	// ("schemator:ignore"), removed from text.
+	markers map[string][]string
+	// deprecated holds the text of "Deprecated:" paragraphs, which stay in
+	// text.
+	deprecated map[string]string
+	// fields maps the keys of field comments to the field names.
+	fields map[string]string
+	// enums holds the exported constants of named types, keyed like text.
+	enums map[string][]enumValue
+	// dirs maps import paths to their source directories.
+	dirs map[string]string
+	// aliases holds the keys of the type aliases.
+	aliases map[string]bool
+	// fieldTypes holds the named types fields are declared with, keyed like
+	// the field comments.
+	fieldTypes map[string]fieldType
+}
+
+func newGoComments() *goComments {
+	return &goComments{
+		text:       make(map[string]string),
+		markers:    make(map[string][]string),
+		deprecated: make(map[string]string),
+		fields:     make(map[string]string),
+		enums:      make(map[string][]enumValue),
+		dirs:       make(map[string]string),
+		aliases:    make(map[string]bool),
+		fieldTypes: make(map[string]fieldType),
+	}
+}
+
+// extractGoComments parses every package below dir, treating dir as the
+// source of modulePath. It replaces jsonschema.Reflector.AddGoComments, which
+// keys comments by the walked path and keeps marker lines in descriptions.
+func extractGoComments(modulePath, dir string) (*goComments, error) {
+	c := newGoComments()
+	err := walkPackageDirs(modulePath, dir, nil, func(pkgPath, p string) error {
+		pc, err := extractPackageComments(pkgPath, p, nil)
+		if err != nil {
+			return err
+		}
+		c.merge(pc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// walkPackageDirs calls fn with the import path and directory of every
+// directory below dir that may hold a package, treating dir as the source of
+// modulePath. A non-nil wanted limits the walk to the packages in it,
+// skipping directory trees that hold none of them.
+func walkPackageDirs(modulePath, dir string, wanted map[string]bool, fn func(pkgPath, dir string) error) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != dir {
+			name := d.Name()
+			if name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		pkgPath := path.Join(modulePath, filepath.ToSlash(rel))
+		if wanted == nil || wanted[pkgPath] {
+			return fn(pkgPath, p)
+		}
+		for w := range wanted {
+			if strings.HasPrefix(w, pkgPath+"/") {
+				return nil
+			}
+		}
+		return filepath.SkipDir
+	})
+}
+
+// extractPackageComments parses the package in dir, not descending into
+// subdirectories, treating it as pkgPath. Only the files bc builds are
+// parsed, unless it is nil.
+func extractPackageComments(pkgPath, dir string, bc *build.Context) (*goComments, error) {
+	c := newGoComments()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, sourceFilter(bc, dir), parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) > 0 {
+		c.dirs[pkgPath] = dir
+	}
+	for _, pkg := range pkgs {
+		names := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		files := make([]*ast.File, len(names))
+		for i, name := range names {
+			files[i] = pkg.Files[name]
+			c.addFile(pkgPath, files[i])
+			c.addAliases(pkgPath, files[i])
+		}
+		c.addEnums(fset, pkgPath, files)
+	}
+	return c, nil
+}
+
+// merge adds the comments of o to c.
+func (c *goComments) merge(o *goComments) {
+	maps.Copy(c.text, o.text)
+	maps.Copy(c.markers, o.markers)
+	maps.Copy(c.deprecated, o.deprecated)
+	maps.Copy(c.fields, o.fields)
+	maps.Copy(c.enums, o.enums)
+	maps.Copy(c.dirs, o.dirs)
+	maps.Copy(c.aliases, o.aliases)
+	maps.Copy(c.fieldTypes, o.fieldTypes)
+}
+
+func (c *goComments) addFile(pkgPath string, f *ast.File) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		// A doc comment on a single-spec declaration documents the type.
+		declDoc := gd.Doc
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			// Unexported types are kept: the Reflector describes them
+			// too when exported fields use them.
+			group := ts.Doc
+			if group == nil {
+				group, declDoc = declDoc, nil
+			}
+			key := pkgPath + "." + ts.Name.Name
+			text, markers := commentText(group)
+			c.set(key, new(doc.Package).Synopsis(text), markers)
+			c.setDeprecated(key, text)
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				c.addFields(key, st)
+			}
+		}
+	}
+}
+
+func (c *goComments) addFields(typeKey string, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		text, markers := commentText(field.Doc)
+		lineText, lineMarkers := commentText(field.Comment)
+		if field.Doc == nil {
+			text = lineText
+		}
+		markers = append(markers, lineMarkers...)
+		for _, name := range field.Names {
+			if name.IsExported() {
+				c.fields[typeKey+"."+name.Name] = name.Name
+				c.set(typeKey+"."+name.Name, text, markers)
+				c.setDeprecated(typeKey+"."+name.Name, text)
+			}
+		}
+		if len(field.Names) == 0 {
+			// Embedded fields are documented under their type name.
+			if name := embeddedFieldName(field.Type); name != "" {
+				c.set(typeKey+"."+name, text, markers)
+				c.setDeprecated(typeKey+"."+name, text)
+			}
+		}
+	}
+}
+
+func (c *goComments) set(key, text string, markers []string) {
+	if text = strings.TrimSpace(text); text != "" {
+		c.text[key] = text
+	}
+	if len(markers) > 0 {
+		c.markers[key] = markers
+	}
+}
+
+// setDeprecated records the "Deprecated:" paragraph of the full comment
+// text, which for types is more than the synopsis kept in c.text.
+func (c *goComments) setDeprecated(key, text string) {
+	if reason, ok := deprecationNote(text); ok {
+		c.deprecated[key] = reason
+	}
+}
+
+// deprecationNote returns the text following "Deprecated:" in the paragraph
+// of a Go doc comment that starts with it, the convention for marking
+// identifiers deprecated.
+func deprecationNote(text string) (string, bool) {
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if note, ok := strings.CutPrefix(para, "Deprecated:"); ok {
+			return strings.Join(strings.Fields(note), " "), true
+		}
+	}
+	return "", false
+}
+
+// commentText returns the prose and markers of a comment group. Besides
+// "+" markers, "schemator:" directives are markers too, both as
+// "// schemator:ignore" and in the directive form "//schemator:ignore" that
+// go/ast drops from the text.
+func commentText(group *ast.CommentGroup) (string, []string) {
+	text, markers := splitMarkers(group.Text())
+	if group == nil {
+		return text, markers
+	}
+	for _, c := range group.List {
+		if directive, ok := strings.CutPrefix(c.Text, "//"+schematorDirective); ok {
+			markers = append(markers, schematorDirective+strings.TrimSpace(directive))
+		}
+	}
+	return text, markers
+}
+
+// splitMarkers separates marker lines ("+kubebuilder:validation:Minimum=0")
+// from the prose of a comment.
+func splitMarkers(text string) (string, []string) {
+	if !strings.Contains(text, "+") && !strings.Contains(text, schematorDirective) {
+		return text, nil
+	}
+	var prose []string
+	var markers []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) > 1 && trimmed[0] == '+' && isMarkerStart(trimmed[1]) {
+			markers = append(markers, trimmed[1:])
+			continue
+		}
+		if strings.HasPrefix(trimmed, schematorDirective) {
+			markers = append(markers, trimmed)
+			continue
+		}
+		prose = append(prose, line)
+	}
+	return strings.Join(prose, "\n"), markers
+}
+
+// schematorDirective prefixes comment lines addressed to schemator, such as
+// "schemator:ignore".
+const schematorDirective = "schemator:"
+
+func isMarkerStart(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func embeddedFieldName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(x.X)
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.Ident:
+		if x.IsExported() {
+			return x.Name
+		}
+	case *ast.IndexExpr:
+		return embeddedFieldName(x.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(x.X)
+	}
+	return ""
+}
+
+// CommentFormat selects how doc comments are rendered into descriptions.
+type CommentFormat int
+
+const (
+	// CommentText keeps the paragraphs, lists and code blocks of a doc
+	// comment as plain text, joining the lines of each paragraph (default).
+	CommentText CommentFormat = iota
+	// CommentMarkdown converts doc comments to Markdown, the way pkg.go.dev
+	// renders them.
+	CommentMarkdown
+	// CommentFlatten collapses all whitespace into single spaces, producing
+	// one-line descriptions.
+	CommentFlatten
+)
+
+// commentPackage returns the import path of a comment map key
+// ("<import path>.<Type>[.<Field>]").
+func commentPackage(key string) string {
+	slash := strings.LastIndex(key, "/") + 1
+	if dot := strings.Index(key[slash:], "."); dot >= 0 {
+		return key[:slash+dot]
+	}
+	return key
+}
+
+// godocBaseURL is where CommentMarkdown points doc links ("[Type]").
+const godocBaseURL = "https://pkg.go.dev"
+
+// formatComment renders the doc comment text of a declaration in package
+// pkgPath. Doc links ("[Type]", "[pkg.Func]") become their plain names, or
+// links to pkg.go.dev in Markdown.
+func formatComment(text, pkgPath string, format CommentFormat) string {
+	if !strings.ContainsAny(text, "\n[") {
+		return sanitizeCommentText(text)
+	}
+	p := comment.Parser{
+		LookupSym: func(recv, name string) bool { return token.IsExported(name) },
+	}
+	d := p.Parse(text)
+	pr := comment.Printer{
+		TextWidth: -1,
+		DocLinkURL: func(link *comment.DocLink) string {
+			if link.ImportPath == "" {
+				local := *link
+				local.ImportPath = pkgPath
+				return local.DefaultURL(godocBaseURL)
+			}
+			return link.DefaultURL(godocBaseURL)
+		},
+	}
+	switch format {
+	case CommentMarkdown:
+		return strings.TrimSpace(string(pr.Markdown(d)))
+	case CommentFlatten:
+		return sanitizeCommentText(string(pr.Text(d)))
+	}
+	return strings.TrimSpace(string(pr.Text(d)))
+}
+
+// stripFieldName removes the "<field> is" or "<field> are" that Go doc
+// comments of fields conventionally start with, capitalizing what follows:
+// "Port is the listen port." becomes "The listen port.". Other comments are
+// returned unchanged.
+func stripFieldName(field, text string) string {
+	rest, ok := strings.CutPrefix(text, field+" ")
+	if !ok {
+		return text
+	}
+	for _, verb := range []string{"is ", "are "} {
+		if rest, ok := strings.CutPrefix(rest, verb); ok && rest != "" {
+			r, size := utf8.DecodeRuneInString(rest)
+			return string(unicode.ToUpper(r)) + rest[size:]
+		}
+	}
+	return text
+}
@@ -0,0 +1,92 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatComment(t *testing.T) {
+	text := "Total is the order total, computed\nfrom the line items.\n\nUse it like:\n\n\tx := Total()\n\nModes:\n  - fast\n  - slow\n"
+	tests := []struct {
+		format CommentFormat
+		want   string
+	}{
+		{CommentText, "Total is the order total, computed from the line items.\n\nUse it like:\n\n\tx := Total()\n\nModes:\n  - fast\n  - slow"},
+		{CommentMarkdown, "Total is the order total, computed from the line items.\n\nUse it like:\n\n\tx := Total()\n\nModes:\n\n  - fast\n  - slow"},
+		{CommentFlatten, "Total is the order total, computed from the line items. Use it like: x := Total() Modes: - fast - slow"},
+	}
+	for _, tt := range tests {
+		if got := formatComment(text, "example.com/pkg", tt.format); got != tt.want {
+			t.Errorf("formatComment(%d) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := formatComment("One  line.", "example.com/pkg", CommentText); got != "One line." {
+		t.Errorf("formatComment(one line) = %q", got)
+	}
+}
+
+func TestFormatCommentDocLinks(t *testing.T) {
+	text := "Total mirrors [Order.Total] as a [time.Duration], see https://example.com/docs."
+	tests := []struct {
+		format CommentFormat
+		want   string
+	}{
+		{CommentText, "Total mirrors Order.Total as a time.Duration, see https://example.com/docs."},
+		{CommentFlatten, "Total mirrors Order.Total as a time.Duration, see https://example.com/docs."},
+		{CommentMarkdown, "Total mirrors [Order.Total](https://pkg.go.dev/example.com/pkg#Order.Total) as a [time.Duration](https://pkg.go.dev/time#Duration), see [https://example.com/docs](https://example.com/docs)."},
+	}
+	for _, tt := range tests {
+		if got := formatComment(text, "example.com/pkg", tt.format); got != tt.want {
+			t.Errorf("formatComment(%d) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := formatComment("Use [optional] fields.", "example.com/pkg", CommentText); got != "Use [optional] fields." {
+		t.Errorf("unexported name rendered as a link: %q", got)
+	}
+	if got := commentPackage("example.com/a.b/pkg.Type.Field"); got != "example.com/a.b/pkg" {
+		t.Errorf("commentPackage() = %q", got)
+	}
+}
+
+func TestStripFieldName(t *testing.T) {
+	tests := []struct{ field, text, want string }{
+		{"Port", "Port is the listen port.", "The listen port."},
+		{"Peers", "Peers are the known nodes.", "The known nodes."},
+		{"Port", "Port to listen on.", "Port to listen on."},
+		{"Port", "Portability is not a concern.", "Portability is not a concern."},
+		{"Port", "Port is", "Port is"},
+		{"Port", "Port is 8080 by default.", "8080 by default."},
+	}
+	for _, tt := range tests {
+		if got := stripFieldName(tt.field, tt.text); got != tt.want {
+			t.Errorf("stripFieldName(%q, %q) = %q, want %q", tt.field, tt.text, got, tt.want)
+		}
+	}
+}
+
+// StrippedServer documents fields the conventional way.
+type StrippedServer struct {
+	// Port is the listen port.
+	Port int `json:"port"`
+}
+
+func TestWithStripFieldNames(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithStripFieldNames()).Generate(StrippedServer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Description string                    `json:"description"`
+		Properties  map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Properties["port"]["description"]; got != "The listen port." {
+		t.Fatalf("port description = %v", got)
+	}
+	if doc.Description != "StrippedServer documents fields the conventional way." {
+		t.Fatalf("type description = %q", doc.Description)
+	}
+}
@@ -0,0 +1,140 @@
+package schemator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// generated is the outcome of generating the schema of one model.
+type generated struct {
+	out SchemaBytes
+	rf  *reflection
+	// err is why the model failed, with its name attached.
+	err error
+}
+
+// generateEach generates the schema of every model with a filename in
+// filenames, up to WithConcurrency models at a time, and returns the results
+// in the order of models. Models without a filename get a zero result. dir
+// is the directory the files are written to, or "" if not to disk.
+// Generating models one at a time stops at the first failing model unless
+// WithContinueOnError is set. Otherwise all models are generated, and the
+// errors of the failing ones are joined in the order of models. The results
+// are returned with the joined error under WithContinueOnError, for the
+// caller to go on with the models that succeeded.
+func (g *generator) generateEach(models []any, filenames []string, dir string) ([]generated, error) {
+	outputFile := func(i int) string {
+		if dir == "" {
+			return ""
+		}
+		return filepath.Join(dir, filepath.FromSlash(filenames[i]))
+	}
+	results := make([]generated, len(models))
+	progress := g.modelProgress(filenames)
+	if g.concurrency <= 1 {
+		var errs []error
+		for i, model := range models {
+			if filenames[i] == "" {
+				continue
+			}
+			if err := g.ctxErr(); err != nil {
+				return nil, err
+			}
+			start := progress.start(model)
+			out, rf, err := g.generateTo(model, outputFile(i))
+			progress.finish(model, start, err)
+			if err != nil && !g.continueOnError {
+				return nil, err
+			}
+			if err != nil {
+				results[i].err = fmt.Errorf("%s: %w", typeName(model), err)
+				errs = append(errs, results[i].err)
+				continue
+			}
+			results[i] = generated{out: out, rf: rf}
+		}
+		return g.generatedResults(results, errors.Join(errs...))
+	}
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Resolve the import paths of all models up front, so the workers only
+	// read them.
+	if _, err := g.resolveImportPaths(ctx, models...); err != nil {
+		return nil, err
+	}
+	// Warnings are reported one at a time, in the order the workers find
+	// them.
+	report := g.warningHandler
+	if report != nil {
+		var mu sync.Mutex
+		report = func(w Warning) {
+			mu.Lock()
+			defer mu.Unlock()
+			g.warningHandler(w)
+		}
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(g.concurrency, len(models)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := g.worker()
+			worker.warningHandler = report
+			for i := range indexes {
+				start := progress.start(models[i])
+				out, rf, err := worker.generateTo(models[i], outputFile(i))
+				progress.finish(models[i], start, err)
+				if err != nil {
+					results[i].err = fmt.Errorf("%s: %w", typeName(models[i]), err)
+					continue
+				}
+				results[i] = generated{out: out, rf: rf}
+			}
+		}()
+	}
+	// Models are no longer handed out once the context is done.
+	for i := 0; i < len(models) && ctx.Err() == nil; i++ {
+		if filenames[i] != "" {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+			}
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(results))
+	for i, r := range results {
+		errs[i] = r.err
+	}
+	return g.generatedResults(results, errors.Join(errs...))
+}
+
+// generatedResults returns what generateEach returns for results failing
+// with err.
+func (g *generator) generatedResults(results []generated, err error) ([]generated, error) {
+	if err != nil && !g.continueOnError {
+		return nil, err
+	}
+	return results, err
+}
+
+// worker returns a copy of g generating models alongside others: it has its
+// own import paths and named schemas in progress, and shares the read-only
+// configuration.
+func (g *generator) worker() *generator {
+	w := *g
+	w.importPaths = slices.Clone(g.importPaths)
+	w.namedInProgress = nil
+	return &w
+}
@@ -0,0 +1,100 @@
+package schemator
+
+import (
+	"context"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+type ConcurrencyUnsupported struct {
+	Done chan int `json:"done"`
+}
+
+func TestConcurrency(t *testing.T) {
+	models := []any{token.Position{}, scanner.Position{}, OrderedBase{}, UnsupportedKinds{}, Named{}, Manifest{}}
+	wantWarnings := 0
+	want, err := NewGenerator(context.Background(), WithFilenameCollisions(FilenameCollisionQualify), WithWarningHandler(func(Warning) {
+		wantWarnings++
+	})).GenerateAll(models...)
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	warnings := 0
+	gen := NewGenerator(context.Background(), WithFilenameCollisions(FilenameCollisionQualify), WithConcurrency(4), WithWarningHandler(func(Warning) {
+		warnings++
+	}))
+	got, err := gen.GenerateAll(models...)
+	if err != nil {
+		t.Fatalf("GenerateAll() with WithConcurrency error = %v", err)
+	}
+	if !maps.EqualFunc(got, want, func(a, b SchemaBytes) bool { return string(a) == string(b) }) {
+		t.Errorf("GenerateAll() with WithConcurrency differs from one model at a time")
+	}
+	if warnings != wantWarnings {
+		t.Errorf("warnings = %d, want %d", warnings, wantWarnings)
+	}
+
+	dir := t.TempDir()
+	if err := gen.WriteSchemas(dir, models...); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	for name, schema := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || strings.TrimSuffix(string(data), "\n") != string(schema) {
+			t.Errorf("%s = %s, %v", name, data, err)
+		}
+	}
+
+	strict := NewGenerator(context.Background(), WithStrict(), WithConcurrency(2))
+	err = strict.WriteSchemas(t.TempDir(), OrderedBase{}, UnsupportedKinds{}, ConcurrencyUnsupported{})
+	if err == nil {
+		t.Fatal("WriteSchemas() error = nil")
+	}
+	msg := err.Error()
+	first := strings.Index(msg, "UnsupportedKinds.Done")
+	second := strings.Index(msg, "ConcurrencyUnsupported.Done")
+	if first < 0 || second < first {
+		t.Errorf("WriteSchemas() error = %v, want the errors of both models in order", err)
+	}
+}
+
+func TestContinueOnError(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background()).WriteSchemas(dir, UnsupportedKinds{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	err := NewGenerator(context.Background(), WithStrict()).WriteSchemas(dir, UnsupportedKinds{}, OrderedBase{}, ConcurrencyUnsupported{})
+	if err == nil || strings.Contains(err.Error(), "ConcurrencyUnsupported") {
+		t.Errorf("WriteSchemas() error = %v, want the error of the first model only", err)
+	}
+	for _, concurrency := range []int{1, 2} {
+		gen := NewGenerator(context.Background(), WithStrict(), WithPrune(), WithConcurrency(concurrency), WithContinueOnError())
+		err := gen.WriteSchemas(dir, UnsupportedKinds{}, OrderedBase{}, ConcurrencyUnsupported{})
+		if err == nil {
+			t.Fatalf("WriteSchemas() with concurrency %d error = nil", concurrency)
+		}
+		msg := err.Error()
+		first := strings.Index(msg, "UnsupportedKinds: ")
+		second := strings.Index(msg, "ConcurrencyUnsupported: ")
+		if first < 0 || second < first {
+			t.Errorf("WriteSchemas() with concurrency %d error = %v, want the errors of both models in order", concurrency, err)
+		}
+		for _, name := range []string{"OrderedBase.schema.json", "UnsupportedKinds.schema.json"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("with concurrency %d: %v", concurrency, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "ConcurrencyUnsupported.schema.json")); !os.IsNotExist(err) {
+			t.Errorf("ConcurrencyUnsupported.schema.json exists with concurrency %d: %v", concurrency, err)
+		}
+		schemas, err := gen.GenerateAll(UnsupportedKinds{}, OrderedBase{})
+		if err == nil || len(schemas) != 1 || schemas["OrderedBase.schema.json"] == nil {
+			t.Errorf("GenerateAll() with concurrency %d = %v, %v", concurrency, schemas, err)
+		}
+	}
+}
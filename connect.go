@@ -0,0 +1,267 @@
+package schemator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// ConnectRoutesFilename is the routing manifest WriteConnectSchemas writes.
+const ConnectRoutesFilename = "connect-routes.json"
+
+// Procedure is a Connect-RPC procedure together with its generated Go
+// request and response message types.
+type Procedure struct {
+	// Service is the fully-qualified protobuf service name, e.g.
+	// "acme.user.v1.UserService".
+	Service string
+	// Method is the RPC name, e.g. "GetUser".
+	Method   string
+	Request  any
+	Response any
+}
+
+// Path returns the HTTP path Connect serves the procedure at.
+func (p Procedure) Path() string {
+	return "/" + p.Service + "/" + p.Method
+}
+
+// ConnectRoutes is the routing manifest mapping procedure paths to the
+// schemas of their JSON request and response bodies, for gateways that
+// validate Connect traffic.
+type ConnectRoutes struct {
+	Procedures []ConnectRoute `json:"procedures"`
+}
+
+// ConnectRoute describes one procedure in ConnectRoutes. Schema paths are
+// relative to the manifest.
+type ConnectRoute struct {
+	Path           string `json:"path"`
+	Service        string `json:"service"`
+	Method         string `json:"method"`
+	RequestType    string `json:"requestType"`
+	RequestSchema  string `json:"requestSchema"`
+	ResponseType   string `json:"responseType"`
+	ResponseSchema string `json:"responseSchema"`
+}
+
+// GenerateProtoJSON generates the JSON schema of a protoc-gen-go message as
+// encoded by protojson (and thus Connect's JSON codec): lowerCamelCase
+// field names from the protobuf tags, 64-bit integers as strings or
+// numbers, enums as names or numbers and the well-known types in their JSON
+// form. Oneof fields cannot be described from the struct alone; they are
+// left out and the message accepts additional properties.
+func (g *generator) GenerateProtoJSON(model any) (SchemaBytes, error) {
+	_, s, err := g.reflectModel(g.ctx, model, func(r *jsonschema.Reflector) {
+		mapper := r.Mapper
+		r.Mapper = func(t reflect.Type) *jsonschema.Schema {
+			if s := protoWellKnownSchema(t); s != nil {
+				return s
+			}
+			if mapper != nil {
+				return mapper(t)
+			}
+			return nil
+		}
+	}, applyProtoJSON)
+	if err != nil {
+		return nil, err
+	}
+	return g.marshalJSON(s)
+}
+
+func (g *generator) WriteConnectSchemas(outputDir string, procedures ...Procedure) error {
+	routes := ConnectRoutes{Procedures: []ConnectRoute{}}
+	for _, p := range procedures {
+		if p.Service == "" || p.Method == "" || p.Request == nil || p.Response == nil {
+			return fmt.Errorf("procedure %s needs a service, a method, a request and a response", p.Path())
+		}
+		route := ConnectRoute{
+			Path:           p.Path(),
+			Service:        p.Service,
+			Method:         p.Method,
+			RequestType:    typeName(p.Request),
+			RequestSchema:  path.Join(p.Service, p.Method+".request.schema.json"),
+			ResponseType:   typeName(p.Response),
+			ResponseSchema: path.Join(p.Service, p.Method+".response.schema.json"),
+		}
+		for file, model := range map[string]any{route.RequestSchema: p.Request, route.ResponseSchema: p.Response} {
+			out, err := g.GenerateProtoJSON(model)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.Path(), err)
+			}
+			if err := g.writeFile(filepath.Join(outputDir, filepath.FromSlash(file)), out, "procedure", p.Path()); err != nil {
+				return err
+			}
+		}
+		routes.Procedures = append(routes.Procedures, route)
+	}
+	out, err := g.marshalJSON(routes)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filepath.Join(outputDir, ConnectRoutesFilename), out)
+}
+
+// typeName returns "<import path>.<Type>" for the type of v.
+func typeName(v any) string {
+	t := derefType(reflect.TypeOf(v))
+	if id := typeID(t); id != "" {
+		return id
+	}
+	return t.String()
+}
+
+// protoTag is the parsed `protobuf:"varint,1,opt,name=user_id,json=userId,proto3"`
+// tag protoc-gen-go writes on message fields.
+type protoTag struct {
+	encoding string
+	jsonName string
+	enum     bool
+}
+
+func parseProtoTag(tag string) protoTag {
+	opts := strings.Split(tag, ",")
+	pt := protoTag{encoding: opts[0]}
+	for _, opt := range opts[1:] {
+		switch {
+		case strings.HasPrefix(opt, "json="):
+			pt.jsonName = strings.TrimPrefix(opt, "json=")
+		case strings.HasPrefix(opt, "enum="):
+			pt.enum = true
+		}
+	}
+	return pt
+}
+
+// applyProtoJSON rewrites a schema reflected from protoc-gen-go messages into
+// the protojson encoding.
+func applyProtoJSON(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		renames := make(map[string]string)
+		var oneofs []string
+		err := rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if _, ok := fv.field.Tag.Lookup("protobuf_oneof"); ok {
+				oneofs = append(oneofs, fv.name)
+				return nil
+			}
+			tag, ok := fv.field.Tag.Lookup("protobuf")
+			if !ok {
+				return nil
+			}
+			pt := parseProtoTag(tag)
+			if pt.jsonName != "" && pt.jsonName != fv.name {
+				renames[fv.name] = pt.jsonName
+			}
+			target, ft := fv.schema, derefType(fv.field.Type)
+			if ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 && target.Items != nil {
+				target, ft = target.Items, ft.Elem()
+			}
+			switch {
+			case pt.enum:
+				*target = jsonschema.Schema{
+					Description: target.Description,
+					AnyOf:       []*jsonschema.Schema{{Type: "string"}, {Type: "integer"}},
+				}
+			case ft.Kind() == reflect.Int64 || ft.Kind() == reflect.Uint64:
+				pattern := "^-?[0-9]+$"
+				if ft.Kind() == reflect.Uint64 {
+					pattern = "^[0-9]+$"
+				}
+				*target = jsonschema.Schema{
+					Description: target.Description,
+					AnyOf:       []*jsonschema.Schema{{Type: "integer"}, {Type: "string", Pattern: pattern}},
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range oneofs {
+			ts.Properties.Delete(name)
+			ts.Required = removeString(ts.Required, name)
+			ts.AdditionalProperties = nil
+		}
+		if len(renames) > 0 {
+			renameProperties(ts, renames)
+		}
+		return nil
+	})
+}
+
+// renameProperties renames the properties of s keeping their order.
+func renameProperties(s *jsonschema.Schema, renames map[string]string) {
+	props := jsonschema.NewProperties()
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		if to, ok := renames[name]; ok {
+			name = to
+		}
+		props.Set(name, pair.Value)
+	}
+	s.Properties = props
+	for i, name := range s.Required {
+		if to, ok := renames[name]; ok {
+			s.Required[i] = to
+		}
+	}
+}
+
+// protoKnownTypes is the import path prefix of the protobuf well-known
+// types.
+const protoKnownTypes = "google.golang.org/protobuf/types/known/"
+
+// protoWellKnownSchema returns the protojson schema of the protobuf
+// well-known types, matched by import path so no protobuf dependency is
+// needed.
+func protoWellKnownSchema(t reflect.Type) *jsonschema.Schema {
+	pkg, ok := strings.CutPrefix(t.PkgPath(), protoKnownTypes)
+	if !ok {
+		return nil
+	}
+	return protoWellKnown(pkg + "." + t.Name())
+}
+
+// protoWellKnown returns the protojson schema of the well-known type name
+// ("timestamppb.Timestamp").
+func protoWellKnown(name string) *jsonschema.Schema {
+	int64Schema := &jsonschema.Schema{AnyOf: []*jsonschema.Schema{{Type: "integer"}, {Type: "string", Pattern: "^-?[0-9]+$"}}}
+	switch name {
+	case "timestamppb.Timestamp":
+		return &jsonschema.Schema{Type: "string", Format: "date-time"}
+	case "durationpb.Duration":
+		return &jsonschema.Schema{Type: "string", Pattern: `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+	case "fieldmaskpb.FieldMask":
+		return &jsonschema.Schema{Type: "string"}
+	case "structpb.Struct":
+		return &jsonschema.Schema{Type: "object"}
+	case "structpb.ListValue":
+		return &jsonschema.Schema{Type: "array"}
+	case "structpb.Value":
+		return &jsonschema.Schema{}
+	case "emptypb.Empty":
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: jsonschema.FalseSchema}
+	case "anypb.Any":
+		props := jsonschema.NewProperties()
+		props.Set("@type", &jsonschema.Schema{Type: "string"})
+		return &jsonschema.Schema{Type: "object", Properties: props, Required: []string{"@type"}}
+	case "wrapperspb.StringValue":
+		return &jsonschema.Schema{Type: "string"}
+	case "wrapperspb.BytesValue":
+		return &jsonschema.Schema{Type: "string", ContentEncoding: "base64"}
+	case "wrapperspb.BoolValue":
+		return &jsonschema.Schema{Type: "boolean"}
+	case "wrapperspb.Int32Value", "wrapperspb.UInt32Value":
+		return &jsonschema.Schema{Type: "integer"}
+	case "wrapperspb.Int64Value", "wrapperspb.UInt64Value":
+		return int64Schema
+	case "wrapperspb.FloatValue", "wrapperspb.DoubleValue":
+		return &jsonschema.Schema{Type: "number"}
+	}
+	return nil
+}
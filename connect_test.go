@@ -0,0 +1,103 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// ProtoStatus mimics a protoc-gen-go enum.
+type ProtoStatus int32
+
+// GetUserRequest mimics a protoc-gen-go message.
+type GetUserRequest struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	// UserId identifies the user.
+	UserId  int64       `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Status  ProtoStatus `protobuf:"varint,2,opt,name=status,proto3,enum=acme.user.v1.Status" json:"status,omitempty"`
+	Friends []uint64    `protobuf:"varint,3,rep,packed,name=friend_ids,json=friendIds,proto3" json:"friend_ids,omitempty"`
+	// Types that are valid to be assigned to Lookup:
+	//
+	//	*GetUserRequest_Email
+	Lookup isGetUserRequest_Lookup `protobuf_oneof:"lookup"`
+}
+
+type isGetUserRequest_Lookup interface{ isGetUserRequest_Lookup() }
+
+// GetUserResponse mimics a protoc-gen-go message.
+type GetUserResponse struct {
+	DisplayName string `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+}
+
+func TestWriteConnectSchemas(t *testing.T) {
+	outDir := t.TempDir()
+	err := New(context.Background(), nil).WriteConnectSchemas(outDir, Procedure{
+		Service:  "acme.user.v1.UserService",
+		Method:   "GetUser",
+		Request:  &GetUserRequest{},
+		Response: &GetUserResponse{},
+	})
+	if err != nil {
+		t.Fatalf("WriteConnectSchemas() error = %v", err)
+	}
+	var routes ConnectRoutes
+	readJSON(t, filepath.Join(outDir, ConnectRoutesFilename), &routes)
+	want := ConnectRoute{
+		Path:           "/acme.user.v1.UserService/GetUser",
+		Service:        "acme.user.v1.UserService",
+		Method:         "GetUser",
+		RequestType:    "pkt.systems/schemator.GetUserRequest",
+		RequestSchema:  "acme.user.v1.UserService/GetUser.request.schema.json",
+		ResponseType:   "pkt.systems/schemator.GetUserResponse",
+		ResponseSchema: "acme.user.v1.UserService/GetUser.response.schema.json",
+	}
+	if len(routes.Procedures) != 1 || routes.Procedures[0] != want {
+		t.Fatalf("routes = %+v", routes)
+	}
+
+	var req struct {
+		Properties           map[string]map[string]any `json:"properties"`
+		AdditionalProperties any                       `json:"additionalProperties"`
+	}
+	readJSON(t, filepath.Join(outDir, want.RequestSchema), &req)
+	var names []string
+	for name := range req.Properties {
+		names = append(names, name)
+	}
+	if len(names) != 3 || req.Properties["userId"] == nil || req.Properties["status"] == nil || req.Properties["friendIds"] == nil {
+		t.Fatalf("request properties = %v", names)
+	}
+	if req.AdditionalProperties != nil {
+		t.Fatalf("message with a oneof must accept additional properties, got %v", req.AdditionalProperties)
+	}
+	userID := req.Properties["userId"]
+	if userID["description"] != "UserId identifies the user." || len(userID["anyOf"].([]any)) != 2 {
+		t.Fatalf("userId = %v", userID)
+	}
+	status := req.Properties["status"]["anyOf"].([]any)
+	if !reflect.DeepEqual(status[0], map[string]any{"type": "string"}) {
+		t.Fatalf("status = %v", status)
+	}
+	items := req.Properties["friendIds"]["items"].(map[string]any)
+	if items["anyOf"].([]any)[1].(map[string]any)["pattern"] != "^[0-9]+$" {
+		t.Fatalf("friendIds items = %v", items)
+	}
+
+	b, err := os.ReadFile(filepath.Join(outDir, want.ResponseSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp["properties"].(map[string]any)["displayName"]; !ok {
+		t.Fatalf("response = %s", b)
+	}
+}
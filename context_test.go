@@ -0,0 +1,45 @@
+package schemator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, concurrency := range []int{1, 4} {
+		dir := t.TempDir()
+		err := NewGenerator(ctx, WithConcurrency(concurrency)).WriteSchemas(dir, OrderedBase{}, EnumPalette{})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("concurrency %d: WriteSchemas() error = %v, want context.Canceled", concurrency, err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("concurrency %d: wrote %d files after cancellation", concurrency, len(entries))
+		}
+	}
+
+	// The generator is resolved before the context is cancelled: writes
+	// still stop.
+	ctx, cancel = context.WithCancel(context.Background())
+	gen := NewGenerator(ctx).(*generator)
+	if _, err := gen.Generate(OrderedBase{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	cancel()
+	if err := gen.WriteSchema(OrderedBase{}, t.TempDir()+"/OrderedBase.schema.json"); !errors.Is(err, context.Canceled) {
+		t.Errorf("WriteSchema() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSubprocessTimeout(t *testing.T) {
+	ctx := withLoaderConfig(context.Background(), loaderConfig{timeout: time.Nanosecond})
+	_, err := loadPackageDirs(ctx, []string{"fmt", "net/http"}, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "go command timed out after 1ns") {
+		t.Errorf("loadPackageDirs() error = %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package schemator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crdManifest is an apiextensions.k8s.io/v1 CustomResourceDefinition reduced
+// to the fields schemator fills in. Field order is the manifest order.
+type crdManifest struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   crdMetadata `json:"metadata"`
+	Spec       crdSpec     `json:"spec"`
+}
+
+type crdMetadata struct {
+	Name string `json:"name"`
+}
+
+type crdSpec struct {
+	Group    string       `json:"group"`
+	Names    crdNames     `json:"names"`
+	Scope    string       `json:"scope"`
+	Versions []crdVersion `json:"versions"`
+}
+
+type crdNames struct {
+	Kind     string `json:"kind"`
+	ListKind string `json:"listKind"`
+	Plural   string `json:"plural"`
+	Singular string `json:"singular"`
+}
+
+type crdVersion struct {
+	Name         string           `json:"name"`
+	Served       bool             `json:"served"`
+	Storage      bool             `json:"storage"`
+	Schema       crdSchema        `json:"schema"`
+	Subresources *crdSubresources `json:"subresources,omitempty"`
+}
+
+type crdSchema struct {
+	OpenAPIV3Schema *jsonschema.Schema `json:"openAPIV3Schema"`
+}
+
+type crdSubresources struct {
+	Status struct{} `json:"status"`
+}
+
+func (g *generator) GenerateCRD(gvk schema.GroupVersionKind, spec, status any) (SchemaBytes, error) {
+	if gvk.Group == "" || gvk.Version == "" || gvk.Kind == "" {
+		return nil, fmt.Errorf("CRD requires group, version and kind, got %q", gvk.String())
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("CRD %s requires a spec model", gvk.Kind)
+	}
+	root := &jsonschema.Schema{
+		Type:       "object",
+		Properties: jsonschema.NewProperties(),
+	}
+	root.Properties.Set("apiVersion", &jsonschema.Schema{Type: "string"})
+	root.Properties.Set("kind", &jsonschema.Schema{Type: "string"})
+	root.Properties.Set("metadata", &jsonschema.Schema{Type: "object"})
+	for _, part := range []struct {
+		name  string
+		model any
+	}{{"spec", spec}, {"status", status}} {
+		if part.model == nil {
+			continue
+		}
+		s, err := g.reflect(part.model)
+		if err != nil {
+			return nil, err
+		}
+		structural, err := structuralSchema(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s of %s: %w", part.name, gvk.Kind, err)
+		}
+		root.Properties.Set(part.name, structural)
+	}
+	plural := pluralize(strings.ToLower(gvk.Kind))
+	version := crdVersion{
+		Name:    gvk.Version,
+		Served:  true,
+		Storage: true,
+		Schema:  crdSchema{OpenAPIV3Schema: root},
+	}
+	if status != nil {
+		version.Subresources = &crdSubresources{}
+	}
+	manifest := crdManifest{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Metadata:   crdMetadata{Name: plural + "." + gvk.Group},
+		Spec: crdSpec{
+			Group: gvk.Group,
+			Names: crdNames{
+				Kind:     gvk.Kind,
+				ListKind: gvk.Kind + "List",
+				Plural:   plural,
+				Singular: strings.ToLower(gvk.Kind),
+			},
+			Scope:    "Namespaced",
+			Versions: []crdVersion{version},
+		},
+	}
+	return jsonToYAML(manifest)
+}
+
+func (g *generator) WriteCRD(gvk schema.GroupVersionKind, spec, status any, filenamePath string) error {
+	out, err := g.GenerateCRD(gvk, spec, status)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filenamePath, out, "kind", gvk.Kind)
+}
+
+// structuralSchema rewrites a reflected schema into a Kubernetes structural
+// schema: references are inlined, keywords apiextensions/v1 rejects are
+// dropped, nullable unions become `nullable: true` and nodes without a type
+// preserve unknown fields instead of being pruned.
+func structuralSchema(s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	inlined, err := inlineRefs(s, s.Definitions)
+	if err != nil {
+		return nil, err
+	}
+	return structuralize(inlined), nil
+}
+
+func structuralize(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if s == jsonschema.TrueSchema {
+		return preserveUnknownFields(&jsonschema.Schema{})
+	}
+	if isBooleanSchema(s) {
+		return nil
+	}
+	if nonNull, ok := nullableUnion(s); ok {
+		merged := copySchemaNode(nonNull)
+		if merged.Description == "" {
+			merged.Description = s.Description
+		}
+		merged = structuralize(merged)
+		setExtra(merged, "nullable", true)
+		return merged
+	}
+	s.Version = ""
+	s.ID = ""
+	s.Anchor = ""
+	s.Comments = ""
+	s.Definitions = nil
+	s.DynamicRef = ""
+	s.Deprecated = false
+	s.ReadOnly = false
+	s.WriteOnly = false
+	s.ContentEncoding = ""
+	s.ContentMediaType = ""
+	s.ContentSchema = nil
+	s.PrefixItems = nil
+	s.Contains = nil
+	s.DependentSchemas = nil
+	s.DependentRequired = nil
+	s.PropertyNames = nil
+	s.If, s.Then, s.Else = nil, nil, nil
+	// Value validation via unions is allowed in structural schemas but the
+	// generated unions carry types, which structural schemas forbid.
+	s.OneOf, s.AnyOf, s.AllOf, s.Not = nil, nil, nil, nil
+	if s.Const != nil {
+		s.Enum = []any{s.Const}
+		s.Const = nil
+	}
+	if len(s.Examples) > 0 {
+		setExtra(s, "example", s.Examples[0])
+		s.Examples = nil
+	}
+	for k := range s.Extras {
+		if !strings.HasPrefix(k, "x-kubernetes-") && k != "nullable" && k != "example" {
+			delete(s.Extras, k)
+		}
+	}
+	// OpenAPI v3 spells exclusive bounds as booleans next to the bound.
+	if s.ExclusiveMinimum != "" {
+		s.Minimum, s.ExclusiveMinimum = s.ExclusiveMinimum, ""
+		setExtra(s, "exclusiveMinimum", true)
+	}
+	if s.ExclusiveMaximum != "" {
+		s.Maximum, s.ExclusiveMaximum = s.ExclusiveMaximum, ""
+		setExtra(s, "exclusiveMaximum", true)
+	}
+	if len(s.PatternProperties) > 0 && s.AdditionalProperties == nil {
+		for _, v := range s.PatternProperties {
+			s.AdditionalProperties = v
+			break
+		}
+	}
+	s.PatternProperties = nil
+	if s.Properties != nil && s.Properties.Len() > 0 {
+		// properties and additionalProperties are mutually exclusive; unknown
+		// fields are pruned instead of rejected.
+		s.AdditionalProperties = nil
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			pair.Value = structuralize(pair.Value)
+		}
+	} else {
+		s.Properties = nil
+		switch s.AdditionalProperties {
+		case nil, jsonschema.FalseSchema:
+			s.AdditionalProperties = nil
+			if s.Type == "object" {
+				preserveUnknownFields(s)
+			}
+		case jsonschema.TrueSchema:
+			s.AdditionalProperties = nil
+			preserveUnknownFields(s)
+		default:
+			s.AdditionalProperties = structuralize(s.AdditionalProperties)
+		}
+	}
+	s.Items = structuralize(s.Items)
+	if s.Type == "array" && s.Items == nil {
+		s.Items = preserveUnknownFields(&jsonschema.Schema{Type: "object"})
+	}
+	if s.Type == "" && s.Ref == "" && s.Extras["x-kubernetes-int-or-string"] != true {
+		preserveUnknownFields(s)
+	}
+	return s
+}
+
+// nullableUnion recognises {oneOf: [T, {type: null}]} as produced for
+// nullable fields and returns T.
+func nullableUnion(s *jsonschema.Schema) (*jsonschema.Schema, bool) {
+	alts := s.OneOf
+	if len(alts) == 0 {
+		alts = s.AnyOf
+	}
+	if len(alts) != 2 || s.Type != "" {
+		return nil, false
+	}
+	for i, alt := range alts {
+		if alt != nil && alt.Type == "null" {
+			other := alts[1-i]
+			return other, other != nil && !isBooleanSchema(other)
+		}
+	}
+	return nil, false
+}
+
+func preserveUnknownFields(s *jsonschema.Schema) *jsonschema.Schema {
+	setExtra(s, "x-kubernetes-preserve-unknown-fields", true)
+	return s
+}
+
+func setExtra(s *jsonschema.Schema, key string, value any) {
+	if s.Extras == nil {
+		s.Extras = make(map[string]any)
+	}
+	s.Extras[key] = value
+}
+
+// pluralize implements the English pluralization rules controller-gen uses
+// for resource names.
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// jsonToYAML renders v as block-style YAML, keeping the key order of its
+// JSON encoding.
+func jsonToYAML(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func resetYAMLStyle(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		// JSON strings are double quoted; let the encoder quote only when
+		// needed.
+		if n.Style == yaml.DoubleQuotedStyle {
+			n.Style = 0
+		}
+	} else {
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
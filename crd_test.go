@@ -0,0 +1,77 @@
+package schemator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"pkt.systems/schemator/example"
+)
+
+func TestGenerateCRD(t *testing.T) {
+	gen := New(context.Background(), nil)
+	gvk := schema.GroupVersionKind{Group: "example.pkt.systems", Version: "v1alpha1", Kind: "Policy"}
+	out, err := gen.GenerateCRD(gvk, example.Example{}, example.Subject{})
+	if err != nil {
+		t.Fatalf("GenerateCRD() error = %v", err)
+	}
+	manifest := out.String()
+	for _, want := range []string{
+		"kind: CustomResourceDefinition",
+		"name: policies.example.pkt.systems",
+		"plural: policies",
+		"openAPIV3Schema:",
+		"subresources:",
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Fatalf("manifest missing %q:\n%s", want, manifest)
+		}
+	}
+	for _, forbidden := range []string{"$ref", "$defs", "$schema", "additionalProperties: false"} {
+		if strings.Contains(manifest, forbidden) {
+			t.Fatalf("manifest contains %q, which structural schemas forbid:\n%s", forbidden, manifest)
+		}
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v", err)
+	}
+}
+
+func TestStructuralizeNullableAndFreeForm(t *testing.T) {
+	type free struct {
+		Labels map[string]any `json:"labels"`
+		Any    any            `json:"any"`
+		Name   *string        `json:"name" jsonschema:"nullable"`
+	}
+	s, err := New(context.Background(), nil).(*generator).reflect(free{})
+	if err != nil {
+		t.Fatalf("reflect() error = %v", err)
+	}
+	st, err := structuralSchema(s)
+	if err != nil {
+		t.Fatalf("structuralSchema() error = %v", err)
+	}
+	labels, _ := st.Properties.Get("labels")
+	if labels.Extras["x-kubernetes-preserve-unknown-fields"] != true {
+		t.Fatalf("map[string]any should preserve unknown fields: %+v", labels)
+	}
+	anyProp, _ := st.Properties.Get("any")
+	if anyProp.Extras["x-kubernetes-preserve-unknown-fields"] != true {
+		t.Fatalf("any should preserve unknown fields: %+v", anyProp)
+	}
+	name, _ := st.Properties.Get("name")
+	if name.Type != "string" || name.Extras["nullable"] != true || len(name.OneOf) != 0 {
+		t.Fatalf("nullable union not rewritten: %+v", name)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	for in, want := range map[string]string{"policy": "policies", "gateway": "gateways", "class": "classes", "node": "nodes"} {
+		if got := pluralize(in); got != want {
+			t.Fatalf("pluralize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// applyDefaultTags sets the default of a property from its `default:"..."`
+// struct tag, typed after the field like example tags (see tagValue). Struct
+// types with a Defaults method returning a populated value of the type, e.g.
+//
+//	func (Config) Defaults() Config { return Config{Port: 8080} }
+//
+// contribute the non-zero fields of that value as defaults of properties
+// without a default tag.
+func applyDefaultTags(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		defaults, err := structDefaults(t)
+		if err != nil {
+			return fmt.Errorf("%s.Defaults: %w", typeKey(t), err)
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if raw, ok := fv.field.Tag.Lookup("default"); ok {
+				v, err := tagValue(fv.field.Type, fv.schema, raw)
+				if err != nil {
+					return fmt.Errorf("%s.%s: default: %w", typeKey(fv.owner), fv.field.Name, err)
+				}
+				fv.schema.Default = v
+				return nil
+			}
+			if defaults.IsValid() && fv.schema.Default == nil {
+				v, err := fieldDefault(defaults, fv.field.Name)
+				if err != nil {
+					return fmt.Errorf("%s.%s: default: %w", typeKey(fv.owner), fv.field.Name, err)
+				}
+				if v != nil {
+					fv.schema.Default = v
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// structDefaults returns the value of t's Defaults method, or an invalid
+// value if t has none. The method may have a value or pointer receiver and
+// return t or *t.
+func structDefaults(t reflect.Type) (reflect.Value, error) {
+	recv := reflect.New(t)
+	m := recv.MethodByName("Defaults")
+	if !m.IsValid() {
+		return reflect.Value{}, nil
+	}
+	mt := m.Type()
+	if mt.NumIn() != 0 || mt.NumOut() != 1 || derefType(mt.Out(0)) != t {
+		return reflect.Value{}, nil
+	}
+	v := m.Call(nil)[0]
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("returned nil")
+		}
+		v = v.Elem()
+	}
+	return v, nil
+}
+
+// fieldDefault returns the JSON value of the field name of the struct v, or
+// nil if it is the zero value.
+func fieldDefault(v reflect.Value, name string) (any, error) {
+	f, ok := v.Type().FieldByName(name)
+	if !ok {
+		return nil, nil
+	}
+	fv, err := v.FieldByIndexErr(f.Index)
+	if err != nil || fv.IsZero() || !fv.CanInterface() {
+		// Fields of nil embedded pointers have no default.
+		return nil, nil
+	}
+	b, err := json.Marshal(fv.Interface())
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(strings.NewReader(string(b)))
+	d.UseNumber()
+	var out any
+	if err := d.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
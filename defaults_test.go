@@ -0,0 +1,52 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// DefaultedConfig has default tags and a Defaults method.
+type DefaultedConfig struct {
+	Host    string            `json:"host" default:"localhost"`
+	Port    int               `json:"port"`
+	Debug   bool              `json:"debug" default:"true"`
+	Ratio   float64           `json:"ratio" default:"0.25"`
+	Tags    []string          `json:"tags" default:"a,b"`
+	Labels  map[string]string `json:"labels"`
+	Timeout int               `json:"timeout" default:"30"`
+	Empty   string            `json:"empty"`
+}
+
+func (DefaultedConfig) Defaults() *DefaultedConfig {
+	return &DefaultedConfig{Port: 8080, Labels: map[string]string{"env": "dev"}, Timeout: 10}
+}
+
+func TestDefaultValues(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(DefaultedConfig{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"host":    "localhost",
+		"port":    8080.0,
+		"debug":   true,
+		"ratio":   0.25,
+		"tags":    []any{"a", "b"},
+		"labels":  map[string]any{"env": "dev"},
+		"timeout": 30.0, // the tag wins over Defaults
+		"empty":   nil,
+	}
+	for prop, v := range want {
+		if got := doc.Properties[prop]["default"]; !reflect.DeepEqual(got, v) {
+			t.Fatalf("%s default = %#v, want %#v", prop, got, v)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package schemator
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// deprecatedReasonExtension holds the deprecation note when
+// WithDeprecationReasons is set.
+const deprecatedReasonExtension = "x-deprecated-reason"
+
+// applyDeprecations marks types and fields whose doc comment has a
+// "Deprecated:" paragraph as deprecated.
+func (g *generator) applyDeprecations(rf *reflection, s *jsonschema.Schema) error {
+	if len(rf.deprecated) == 0 {
+		return nil
+	}
+	err := rf.forEachType(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if reason, ok := rf.deprecated[typeKey(t)]; ok {
+			g.deprecate(ts, reason)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if reason, ok := rf.deprecated[typeKey(fv.owner)+"."+fv.field.Name]; ok {
+				g.deprecate(fv.schema, reason)
+			}
+			return nil
+		})
+	})
+}
+
+func (g *generator) deprecate(s *jsonschema.Schema, reason string) {
+	s.Deprecated = true
+	if !g.deprecationReasons || reason == "" {
+		return
+	}
+	setExtra(s, deprecatedReasonExtension, reason)
+	s.Description = strings.TrimSpace(strings.Replace(s.Description, "Deprecated: "+reason, "", 1))
+}
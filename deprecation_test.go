@@ -0,0 +1,69 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// LegacyAddress is kept for old clients.
+//
+// Deprecated: use [Location].
+type LegacyAddress struct {
+	Street string `json:"street"`
+}
+
+// DeprecatingOrder has deprecated fields.
+type DeprecatingOrder struct {
+	// Total is the order total.
+	//
+	// Deprecated: use TotalCents, which
+	// avoids rounding.
+	Total float64 `json:"total"`
+	// TotalCents is the order total in cents.
+	TotalCents int           `json:"totalCents"`
+	Address    LegacyAddress `json:"address"`
+}
+
+func TestDeprecatedComments(t *testing.T) {
+	type property struct {
+		Description string `json:"description"`
+		Deprecated  bool   `json:"deprecated"`
+		Reason      string `json:"x-deprecated-reason"`
+	}
+	generate := func(opts ...Option) (doc struct {
+		Properties map[string]property `json:"properties"`
+		Defs       map[string]property `json:"$defs"`
+	}) {
+		t.Helper()
+		out, err := NewGenerator(context.Background(), opts...).Generate(DeprecatingOrder{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	doc := generate()
+	total := doc.Properties["total"]
+	if !total.Deprecated || total.Reason != "" || total.Description != "Total is the order total.\n\nDeprecated: use TotalCents, which avoids rounding." {
+		t.Fatalf("total = %+v", total)
+	}
+	if doc.Properties["totalCents"].Deprecated {
+		t.Fatal("totalCents deprecated")
+	}
+	if !doc.Defs["LegacyAddress"].Deprecated {
+		t.Fatalf("LegacyAddress not deprecated: %+v", doc.Defs)
+	}
+
+	doc = generate(WithDeprecationReasons())
+	total = doc.Properties["total"]
+	if !total.Deprecated || total.Reason != "use TotalCents, which avoids rounding." || total.Description != "Total is the order total." {
+		t.Fatalf("total with reasons = %+v", total)
+	}
+	if legacy := doc.Defs["LegacyAddress"]; legacy.Reason != "use Location." || legacy.Description != "LegacyAddress is kept for old clients." {
+		t.Fatalf("LegacyAddress with reasons = %+v", legacy)
+	}
+}
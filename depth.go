@@ -0,0 +1,67 @@
+package schemator
+
+import (
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// applyMaxDepth is the schema pass cutting the definitions more than
+// g.maxDepth references away from the root. References to them become
+// schemas accepting any value, keeping the description next to the
+// reference, and the definitions only they used are dropped.
+func (g *generator) applyMaxDepth(rf *reflection, s *jsonschema.Schema) error {
+	defs := s.Definitions
+	if len(defs) == 0 {
+		return nil
+	}
+	// walk walks n without descending into the definitions of the root,
+	// which are visited on their own.
+	walk := func(n *jsonschema.Schema, fn func(*jsonschema.Schema) error) {
+		saved := n.Definitions
+		if n == s {
+			n.Definitions = nil
+		}
+		_ = walkSchema(n, fn)
+		n.Definitions = saved
+	}
+	refs := func(n *jsonschema.Schema) []string {
+		var names []string
+		walk(n, func(c *jsonschema.Schema) error {
+			if name, ok := strings.CutPrefix(c.Ref, "#/$defs/"); ok && defs[name] != nil {
+				names = append(names, name)
+			}
+			return nil
+		})
+		return names
+	}
+	// Breadth first, so every definition gets its shortest distance.
+	depth := make(map[string]int)
+	queue := []*jsonschema.Schema{s}
+	level := map[*jsonschema.Schema]int{s: 0}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, name := range refs(n) {
+			if _, seen := depth[name]; seen {
+				continue
+			}
+			depth[name] = level[n] + 1
+			level[defs[name]] = depth[name]
+			queue = append(queue, defs[name])
+		}
+	}
+	for n, d := range level {
+		if d != g.maxDepth {
+			continue
+		}
+		walk(n, func(c *jsonschema.Schema) error {
+			if name, ok := strings.CutPrefix(c.Ref, "#/$defs/"); ok && depth[name] > g.maxDepth {
+				c.Ref = ""
+			}
+			return nil
+		})
+	}
+	pruneDefinitions(s)
+	return nil
+}
@@ -0,0 +1,78 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+type DepthLeaf struct {
+	Value string `json:"value"`
+}
+
+type DepthInner struct {
+	// Leaf is as deep as it gets.
+	Leaf  DepthLeaf    `json:"leaf"`
+	Outer *DepthMiddle `json:"outer,omitempty"`
+}
+
+type DepthMiddle struct {
+	// Inner is cut at depth 1.
+	Inner DepthInner `json:"inner"`
+}
+
+type DepthRoot struct {
+	Middle DepthMiddle `json:"middle"`
+	Leaf   DepthLeaf   `json:"leaf"`
+}
+
+func TestMaxDepth(t *testing.T) {
+	generate := func(opts ...Option) map[string]any {
+		t.Helper()
+		out, err := NewGenerator(context.Background(), opts...).Generate(DepthRoot{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	defNames := func(doc map[string]any) []string {
+		var names []string
+		for name := range doc["$defs"].(map[string]any) {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := defNames(generate()); !slices.Equal(got, []string{"DepthInner", "DepthLeaf", "DepthMiddle"}) {
+		t.Errorf("unlimited $defs = %v", got)
+	}
+
+	doc := generate(WithMaxDepth(1))
+	if got := defNames(doc); !slices.Equal(got, []string{"DepthLeaf", "DepthMiddle"}) {
+		t.Errorf("depth 1 $defs = %v", got)
+	}
+	middle := doc["$defs"].(map[string]any)["DepthMiddle"].(map[string]any)
+	if got := compactJSON(middle["properties"].(map[string]any)["inner"]); got != `{"description":"Inner is cut at depth 1."}` {
+		t.Errorf("DepthMiddle.inner = %s", got)
+	}
+
+	// DepthLeaf is one reference away from the root, so it stays even
+	// where DepthInner refers to it from further away.
+	doc = generate(WithMaxDepth(2))
+	if got := defNames(doc); !slices.Equal(got, []string{"DepthInner", "DepthLeaf", "DepthMiddle"}) {
+		t.Errorf("depth 2 $defs = %v", got)
+	}
+	inner := doc["$defs"].(map[string]any)["DepthInner"].(map[string]any)["properties"].(map[string]any)
+	if got := compactJSON(inner["leaf"]); got != `{"$ref":"#/$defs/DepthLeaf","description":"Leaf is as deep as it gets."}` {
+		t.Errorf("DepthInner.leaf = %s", got)
+	}
+	if got := compactJSON(inner["outer"]); got != `{"$ref":"#/$defs/DepthMiddle"}` {
+		t.Errorf("DepthInner.outer = %s", got)
+	}
+}
@@ -0,0 +1,263 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// TypeDescriptor is schemator's own description of a Go type, built by the
+// same extraction pipeline as the JSON schemas but independent of them.
+type TypeDescriptor struct {
+	// Root is the described type.
+	Root TypeRef `json:"root"`
+	// Types holds every named type reachable from Root, keyed by ID.
+	Types map[string]*NamedType `json:"types"`
+	// Packages holds the packages of Types, keyed by import path.
+	Packages map[string]PackageInfo `json:"packages"`
+}
+
+// TypeRef describes the structure of a type. Named types refer to their
+// entry in TypeDescriptor.Types instead of repeating it, which also keeps
+// recursive types finite.
+type TypeRef struct {
+	// GoType is the type as written in Go, e.g. "[]*time.Time".
+	GoType string `json:"goType"`
+	// Kind is the reflect.Kind, e.g. "struct", "slice" or "string".
+	Kind string `json:"kind"`
+	// Named is the ID of the named type in TypeDescriptor.Types.
+	Named string `json:"named,omitempty"`
+	// Elem is the element type of pointers, slices, arrays, maps and
+	// channels.
+	Elem *TypeRef `json:"elem,omitempty"`
+	// Key is the key type of maps.
+	Key *TypeRef `json:"key,omitempty"`
+	// Len is the length of arrays.
+	Len int `json:"len,omitempty"`
+	// Fields are the fields of anonymous structs.
+	Fields []FieldDescriptor `json:"fields,omitempty"`
+}
+
+// NamedType describes a defined type.
+type NamedType struct {
+	// ID is "<import path>.<Name>".
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Kind    string `json:"kind"`
+	// Doc is the type's doc comment as used for descriptions.
+	Doc string `json:"doc,omitempty"`
+	// Markers are the "+" marker lines of the doc comment.
+	Markers []string `json:"markers,omitempty"`
+	// Fields are the exported and embedded fields of structs.
+	Fields []FieldDescriptor `json:"fields,omitempty"`
+	// Underlying is the structure of non-struct types.
+	Underlying *TypeRef `json:"underlying,omitempty"`
+}
+
+// FieldDescriptor describes a struct field.
+type FieldDescriptor struct {
+	Name     string            `json:"name"`
+	Type     TypeRef           `json:"type"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Doc      string            `json:"doc,omitempty"`
+	Markers  []string          `json:"markers,omitempty"`
+	Embedded bool              `json:"embedded,omitempty"`
+}
+
+// PackageInfo locates a package in a module.
+type PackageInfo struct {
+	Path string `json:"path"`
+	// Module is the module path, "std" for the standard library.
+	Module string `json:"module,omitempty"`
+	// Version is the module version from the build information, the Go
+	// version for the standard library and "(devel)" for the main module.
+	Version string `json:"version,omitempty"`
+}
+
+func (g *generator) DescribeType(model any) (*TypeDescriptor, error) {
+	if model == nil {
+		return nil, fmt.Errorf("cannot describe nil model")
+	}
+	rf, err := g.newReflector(g.ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	d := &describer{
+		comments: rf.CommentMap,
+		markers:  rf.markers,
+		modules:  buildModules(),
+		desc: &TypeDescriptor{
+			Types:    make(map[string]*NamedType),
+			Packages: make(map[string]PackageInfo),
+		},
+	}
+	d.desc.Root = d.ref(reflect.TypeOf(model))
+	return d.desc, nil
+}
+
+type describer struct {
+	comments map[string]string
+	markers  map[string][]string
+	modules  []*debug.Module
+	desc     *TypeDescriptor
+}
+
+// ref returns the TypeRef of t, describing named types into d.desc.Types.
+func (d *describer) ref(t reflect.Type) TypeRef {
+	// Instantiations of a generic type are types of their own sharing the
+	// comments of the generic type.
+	id, key := typeID(t), typeKey(t)
+	if id == "" {
+		return d.structure(t, "")
+	}
+	if _, ok := d.desc.Types[id]; !ok {
+		nt := &NamedType{
+			ID:      id,
+			Name:    t.Name(),
+			Package: t.PkgPath(),
+			Kind:    t.Kind().String(),
+			Doc:     d.comments[key],
+			Markers: d.markers[key],
+		}
+		// Register before descending so recursive references terminate.
+		d.desc.Types[id] = nt
+		d.addPackage(t.PkgPath())
+		if t.Kind() == reflect.Struct {
+			nt.Fields = d.fields(t, key)
+		} else {
+			u := d.structure(t, key)
+			nt.Underlying = &u
+		}
+	}
+	return TypeRef{GoType: t.String(), Kind: t.Kind().String(), Named: id}
+}
+
+// structure describes t without regard to its name. key is the CommentMap
+// key of t when it is named.
+func (d *describer) structure(t reflect.Type, key string) TypeRef {
+	r := TypeRef{GoType: t.String(), Kind: t.Kind().String()}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Chan:
+		elem := d.ref(t.Elem())
+		r.Elem = &elem
+	case reflect.Array:
+		elem := d.ref(t.Elem())
+		r.Elem = &elem
+		r.Len = t.Len()
+	case reflect.Map:
+		key, elem := d.ref(t.Key()), d.ref(t.Elem())
+		r.Key, r.Elem = &key, &elem
+	case reflect.Struct:
+		r.Fields = d.fields(t, key)
+	}
+	return r
+}
+
+func (d *describer) fields(t reflect.Type, key string) []FieldDescriptor {
+	var out []FieldDescriptor
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		fd := FieldDescriptor{
+			Name:     f.Name,
+			Type:     d.ref(f.Type),
+			Tags:     parseStructTag(f.Tag),
+			Embedded: f.Anonymous,
+		}
+		if key != "" {
+			fd.Doc = d.comments[key+"."+f.Name]
+			fd.Markers = d.markers[key+"."+f.Name]
+		}
+		out = append(out, fd)
+	}
+	return out
+}
+
+func (d *describer) addPackage(pkgPath string) {
+	if _, ok := d.desc.Packages[pkgPath]; ok {
+		return
+	}
+	info := PackageInfo{Path: pkgPath}
+	info.Module, info.Version = packageModule(pkgPath, d.modules)
+	d.desc.Packages[pkgPath] = info
+}
+
+// packageModule returns the path and version of the module among modules
+// providing the package pkgPath, "std" and the Go version for the standard
+// library, or empty strings if none does.
+func packageModule(pkgPath string, modules []*debug.Module) (module, version string) {
+	if first, _, _ := strings.Cut(pkgPath, "/"); !strings.Contains(first, ".") {
+		// Standard library import paths have no dot in the first element.
+		return "std", runtime.Version()
+	}
+	for _, m := range modules {
+		// The longest matching module path wins: nested modules shadow
+		// their parents.
+		if len(m.Path) > len(module) && (pkgPath == m.Path || strings.HasPrefix(pkgPath, m.Path+"/")) {
+			module, version = m.Path, m.Version
+		}
+	}
+	return module, version
+}
+
+// buildModules returns the main module and dependencies of the running
+// binary.
+func buildModules() []*debug.Module {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	modules := make([]*debug.Module, 0, len(bi.Deps)+1)
+	if bi.Main.Path != "" {
+		main := bi.Main
+		if main.Version == "" {
+			main.Version = "(devel)"
+		}
+		modules = append(modules, &main)
+	}
+	for _, m := range bi.Deps {
+		if m.Replace != nil {
+			m = &debug.Module{Path: m.Path, Version: m.Replace.Version, Sum: m.Replace.Sum}
+		}
+		modules = append(modules, m)
+	}
+	return modules
+}
+
+// parseStructTag splits a conventional `key:"value" key2:"value2"` tag.
+func parseStructTag(tag reflect.StructTag) map[string]string {
+	var out map[string]string
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			break
+		}
+		key := s[:i]
+		s = s[i+1:]
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			break
+		}
+		s = s[len(quoted):]
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			break
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+// DescribedNode is a recursive type used to test DescribeType.
+type DescribedNode struct {
+	// Children of the node.
+	Children []*DescribedNode `json:"children,omitempty" validate:"dive"`
+}
+
+func TestDescribeType(t *testing.T) {
+	desc, err := New(context.Background(), nil).DescribeType(example.Example{})
+	if err != nil {
+		t.Fatalf("DescribeType() error = %v", err)
+	}
+	const exampleID = "pkt.systems/schemator/example.Example"
+	if desc.Root.Named != exampleID {
+		t.Fatalf("Root = %+v", desc.Root)
+	}
+	ex := desc.Types[exampleID]
+	if ex == nil || len(ex.Fields) != 3 {
+		t.Fatalf("Example = %+v", ex)
+	}
+	subject := ex.Fields[1]
+	if subject.Name != "Subject" || subject.Tags["json"] != "subject" || subject.Doc != "A subject identifies an entity in the system." {
+		t.Fatalf("Subject field = %+v", subject)
+	}
+	if desc.Types["time.Time"] == nil || desc.Packages["time"].Module != "std" {
+		t.Fatalf("time.Time not described: %+v", desc.Packages["time"])
+	}
+	if mod := desc.Packages["k8s.io/apimachinery/pkg/apis/meta/v1"]; mod.Module != "k8s.io/apimachinery" || mod.Version == "" {
+		t.Fatalf("apimachinery package info = %+v", mod)
+	}
+	if _, err := json.Marshal(desc); err != nil {
+		t.Fatalf("descriptor does not marshal: %v", err)
+	}
+}
+
+func TestDescribeTypeRecursive(t *testing.T) {
+	desc, err := New(context.Background(), nil).DescribeType(&DescribedNode{})
+	if err != nil {
+		t.Fatalf("DescribeType() error = %v", err)
+	}
+	if desc.Root.Kind != "ptr" || desc.Root.Elem.Named != "pkt.systems/schemator.DescribedNode" {
+		t.Fatalf("Root = %+v", desc.Root)
+	}
+	children := desc.Types["pkt.systems/schemator.DescribedNode"].Fields[0]
+	if children.Type.Kind != "slice" || children.Type.Elem.Elem.Named != "pkt.systems/schemator.DescribedNode" {
+		t.Fatalf("Children = %+v", children.Type)
+	}
+	if want := map[string]string{"json": "children,omitempty", "validate": "dive"}; !reflect.DeepEqual(children.Tags, want) {
+		t.Fatalf("Tags = %v, want %v", children.Tags, want)
+	}
+}
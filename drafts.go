@@ -0,0 +1,244 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Draft is a JSON Schema specification version, ordered from oldest to
+// newest.
+type Draft int
+
+const (
+	Draft04 Draft = iota
+	Draft06
+	Draft07
+	Draft2019_09
+	Draft2020_12
+)
+
+var draftNames = []string{"draft-04", "draft-06", "draft-07", "2019-09", "2020-12"}
+
+var draftURIs = []string{
+	"http://json-schema.org/draft-04/schema#",
+	"http://json-schema.org/draft-06/schema#",
+	draft07,
+	"https://json-schema.org/draft/2019-09/schema",
+	"https://json-schema.org/draft/2020-12/schema",
+}
+
+func (d Draft) String() string {
+	if d < 0 || int(d) >= len(draftNames) {
+		return "Draft(" + strconv.Itoa(int(d)) + ")"
+	}
+	return draftNames[d]
+}
+
+// URI returns the $schema URI of d.
+func (d Draft) URI() string {
+	if d < 0 || int(d) >= len(draftURIs) {
+		return ""
+	}
+	return draftURIs[d]
+}
+
+func (d Draft) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Draft) UnmarshalText(text []byte) error {
+	i := slices.Index(draftNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown JSON Schema draft %q", text)
+	}
+	*d = Draft(i)
+	return nil
+}
+
+// DraftFeature is a use of a keyword that requires Draft or later.
+type DraftFeature struct {
+	// Path is the JSON pointer of the schema using the keyword.
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Draft   Draft  `json:"draft"`
+}
+
+func (f DraftFeature) String() string {
+	path := f.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s requires %s", path, f.Keyword, f.Draft)
+}
+
+// DraftReport is the result of AnalyzeDraft.
+type DraftReport struct {
+	// Minimum is the oldest draft every keyword of the schema exists in.
+	Minimum Draft `json:"minimum"`
+	// Features lists the keywords newer than draft-04, in document order.
+	Features []DraftFeature `json:"features,omitempty"`
+}
+
+// Lost returns the features a schema downgraded to target would lose or
+// have interpreted differently by target's validators.
+func (r *DraftReport) Lost(target Draft) []DraftFeature {
+	var lost []DraftFeature
+	for _, f := range r.Features {
+		if f.Draft > target {
+			lost = append(lost, f)
+		}
+	}
+	return lost
+}
+
+// draftKeywords maps keywords to the draft introducing them. Keywords of
+// draft-04 are absent.
+var draftKeywords = map[string]Draft{
+	"$id":                   Draft06,
+	"const":                 Draft06,
+	"contains":              Draft06,
+	"propertyNames":         Draft06,
+	"examples":              Draft06,
+	"$comment":              Draft07,
+	"if":                    Draft07,
+	"then":                  Draft07,
+	"else":                  Draft07,
+	"contentEncoding":       Draft07,
+	"contentMediaType":      Draft07,
+	"readOnly":              Draft07,
+	"writeOnly":             Draft07,
+	"$defs":                 Draft2019_09,
+	"$anchor":               Draft2019_09,
+	"$recursiveRef":         Draft2019_09,
+	"$recursiveAnchor":      Draft2019_09,
+	"dependentRequired":     Draft2019_09,
+	"dependentSchemas":      Draft2019_09,
+	"unevaluatedProperties": Draft2019_09,
+	"unevaluatedItems":      Draft2019_09,
+	"minContains":           Draft2019_09,
+	"maxContains":           Draft2019_09,
+	"deprecated":            Draft2019_09,
+	"contentSchema":         Draft2019_09,
+	"prefixItems":           Draft2020_12,
+	"$dynamicRef":           Draft2020_12,
+	"$dynamicAnchor":        Draft2020_12,
+}
+
+// refSiblings are the keywords older drafts accept next to $ref. Before
+// 2019-09 every other keyword beside $ref is ignored.
+var refSiblings = []string{
+	"$ref", "$schema", "$id", "$comment", "$defs", "definitions",
+	"title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly",
+}
+
+var (
+	schemaKeywords      = []string{"not", "if", "then", "else", "contains", "additionalProperties", "propertyNames", "contentSchema", "unevaluatedProperties", "unevaluatedItems", "additionalItems"}
+	schemaListKeywords  = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords   = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+	schemaMaybeKeywords = []string{"items", "dependencies"}
+)
+
+// AnalyzeDraft reports the oldest JSON Schema draft the JSON schema
+// document can be expressed in, and the keywords that need newer drafts.
+func AnalyzeDraft(schema []byte) (*DraftReport, error) {
+	var doc any
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, err
+	}
+	r := &DraftReport{}
+	r.walk("", doc)
+	for _, f := range r.Features {
+		r.Minimum = max(r.Minimum, f.Draft)
+	}
+	return r, nil
+}
+
+func (r *DraftReport) add(path, keyword string, d Draft) {
+	r.Features = append(r.Features, DraftFeature{Path: path, Keyword: keyword, Draft: d})
+}
+
+func (r *DraftReport) walk(path string, node any) {
+	s, ok := node.(map[string]any)
+	if !ok {
+		if _, isBool := node.(bool); isBool {
+			r.add(path, "boolean schema", Draft06)
+		}
+		return
+	}
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if d, ok := draftKeywords[k]; ok {
+			r.add(path, k, d)
+		}
+	}
+	for _, k := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+		if _, numeric := s[k].(float64); numeric {
+			r.add(path, k+" (number)", Draft06)
+		}
+	}
+	if _, ok := s["$ref"]; ok {
+		for _, k := range keys {
+			if !slices.Contains(refSiblings, k) {
+				r.add(path, "$ref beside "+k, Draft2019_09)
+				break
+			}
+		}
+	}
+	for _, k := range keys {
+		child := path + "/" + escapeJSONPointer(k)
+		switch v := s[k]; {
+		case slices.Contains(schemaKeywords, k):
+			r.walk(child, v)
+		case slices.Contains(schemaListKeywords, k):
+			items, _ := v.([]any)
+			for i, item := range items {
+				r.walk(child+"/"+strconv.Itoa(i), item)
+			}
+		case slices.Contains(schemaMapKeywords, k):
+			m, _ := v.(map[string]any)
+			for _, name := range sortedKeys(m) {
+				r.walk(child+"/"+escapeJSONPointer(name), m[name])
+			}
+		case slices.Contains(schemaMaybeKeywords, k):
+			switch v := v.(type) {
+			case []any:
+				for i, item := range v {
+					r.walk(child+"/"+strconv.Itoa(i), item)
+				}
+			case map[string]any:
+				if k == "items" {
+					r.walk(child, v)
+					continue
+				}
+				// draft-04 dependencies map to schemas or required lists.
+				for _, name := range sortedKeys(v) {
+					r.walk(child+"/"+escapeJSONPointer(name), v[name])
+				}
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (g *generator) AnalyzeDraft(model any) (*DraftReport, error) {
+	out, err := g.Generate(model)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeDraft(out)
+}
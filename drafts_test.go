@@ -0,0 +1,68 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestAnalyzeDraft(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   Draft
+		lost   []string
+	}{
+		{"draft-04", `{"type":"object","properties":{"const":{"type":"string","minLength":1}},"required":["const"]}`, Draft04, nil},
+		{"const", `{"properties":{"a":{"const":1}}}`, Draft06, []string{"/properties/a: const requires draft-06"}},
+		{"exclusive bound", `{"exclusiveMinimum":0}`, Draft06, []string{"/: exclusiveMinimum (number) requires draft-06"}},
+		{"boolean exclusive bound", `{"minimum":0,"exclusiveMinimum":true}`, Draft04, nil},
+		{"if", `{"allOf":[{"if":{"required":["a"]},"then":{"required":["b"]}}]}`, Draft07, []string{"/allOf/0: if requires draft-07", "/allOf/0: then requires draft-07"}},
+		{"dependentRequired", `{"dependentRequired":{"a":["b"]}}`, Draft2019_09, []string{"/: dependentRequired requires 2019-09"}},
+		{"ref siblings", `{"$ref":"#/$defs/A","$defs":{"A":{"$ref":"#/$defs/B","minLength":1},"B":{"type":"string"}}}`, Draft2019_09, []string{"/: $defs requires 2019-09", "/$defs/A: $ref beside minLength requires 2019-09"}},
+		{"prefixItems", `{"items":{"prefixItems":[true]}}`, Draft2020_12, []string{"/items: prefixItems requires 2020-12", "/items/prefixItems/0: boolean schema requires draft-06"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := AnalyzeDraft([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("AnalyzeDraft() error = %v", err)
+			}
+			if r.Minimum != tt.want {
+				t.Fatalf("Minimum = %s, want %s (%v)", r.Minimum, tt.want, r.Features)
+			}
+			var lost []string
+			for _, f := range r.Lost(Draft04) {
+				lost = append(lost, f.String())
+			}
+			if !slices.Equal(lost, tt.lost) {
+				t.Fatalf("Lost(draft-04) = %q, want %q", lost, tt.lost)
+			}
+		})
+	}
+}
+
+func TestGeneratorAnalyzeDraft(t *testing.T) {
+	r, err := New(context.Background(), nil).AnalyzeDraft(example.Subject{})
+	if err != nil {
+		t.Fatalf("AnalyzeDraft() error = %v", err)
+	}
+	// Subject is expanded without $defs; only $id needs more than draft-04.
+	if r.Minimum != Draft06 {
+		t.Fatalf("Minimum = %s, want draft-06 (%v)", r.Minimum, r.Features)
+	}
+	if len(r.Lost(r.Minimum)) != 0 {
+		t.Fatalf("Lost(%s) = %v, want none", r.Minimum, r.Lost(r.Minimum))
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back DraftReport
+	if err := json.Unmarshal(b, &back); err != nil || back.Minimum != r.Minimum {
+		t.Fatalf("round trip = %+v, %v", back, err)
+	}
+}
@@ -0,0 +1,81 @@
+package schemator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"pkt.systems/logport"
+)
+
+// WriteAction is what a write does to a file.
+type WriteAction int
+
+const (
+	// WriteCreate creates a file that does not exist yet.
+	WriteCreate WriteAction = iota
+	// WriteUpdate replaces the content or permissions of a file.
+	WriteUpdate
+	// WriteUnchanged leaves a file that already has the content alone.
+	WriteUnchanged
+	// WriteRemove removes a stale file (see WithPrune).
+	WriteRemove
+)
+
+func (a WriteAction) String() string {
+	switch a {
+	case WriteCreate:
+		return "create"
+	case WriteUpdate:
+		return "update"
+	case WriteUnchanged:
+		return "unchanged"
+	case WriteRemove:
+		return "remove"
+	}
+	return fmt.Sprintf("WriteAction(%d)", int(a))
+}
+
+// PlannedWrite is a file a write in dry-run mode would have touched.
+type PlannedWrite struct {
+	// File is the path of the file.
+	File string
+	// Action is what the write would have done to it.
+	Action WriteAction
+}
+
+// write writes data to name as configured, or reports what writing it
+// would do in dry-run mode.
+func (g *generator) write(name string, data []byte) error {
+	if err := g.ctxErr(); err != nil {
+		return err
+	}
+	if !g.dryRun {
+		_, span := g.startSpan(g.ctx, "schemator.Write", attribute.String("schemator.file", name), attribute.Int("schemator.bytes", len(data)))
+		err := g.perms.write(name, data)
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
+		g.written(name, len(data))
+		return nil
+	}
+	action := WriteUpdate
+	if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+		action = WriteCreate
+	} else if unchangedFile(name, data, g.perms.file) {
+		action = WriteUnchanged
+	}
+	g.plan(PlannedWrite{File: name, Action: action})
+	return nil
+}
+
+// plan reports a planned write to the WithDryRun callback, or logs it.
+func (g *generator) plan(p PlannedWrite) {
+	if g.dryRunReport != nil {
+		g.dryRunReport(p)
+		return
+	}
+	logport.LoggerFromContext(g.ctx).Info("Dry run", "file", p.File, "action", p.Action.String())
+}
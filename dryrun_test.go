@@ -0,0 +1,52 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithDryRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background()).WriteSchemas(dir, OrderedBase{}, OrderedRecord{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "OrderedRecord.schema.json"), "{}")
+	writeFile(t, filepath.Join(dir, "Stale.schema.json"), "{}")
+
+	planned := make(map[string]WriteAction)
+	gen := NewGenerator(context.Background(), WithManifest(), WithPrune(), WithDryRun(func(p PlannedWrite) {
+		rel, err := filepath.Rel(dir, p.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		planned[rel] = p.Action
+	}))
+	if err := gen.WriteSchemas(dir, OrderedBase{}, OrderedRecord{}, HTTPServerV2{}); err != nil {
+		t.Fatalf("WriteSchemas(dry run) error = %v", err)
+	}
+	want := map[string]WriteAction{
+		"OrderedBase.schema.json":   WriteUnchanged,
+		"OrderedRecord.schema.json": WriteUpdate,
+		"HTTPServerV2.schema.json":  WriteCreate,
+		ManifestFilename:            WriteCreate,
+		"Stale.schema.json":         WriteRemove,
+	}
+	for file, action := range want {
+		if planned[file] != action {
+			t.Errorf("%s: planned %v, want %v", file, planned[file], action)
+		}
+	}
+	if len(planned) != len(want) {
+		t.Errorf("planned = %v", planned)
+	}
+	for _, file := range []string{"HTTPServerV2.schema.json", ManifestFilename} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			t.Errorf("dry run wrote %s", file)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Stale.schema.json")); err != nil {
+		t.Errorf("dry run pruned: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+// DurationFormat selects how time.Duration fields are described.
+type DurationFormat int
+
+const (
+	// DurationString describes durations as strings in the form
+	// time.ParseDuration accepts, such as "1h30m" (default).
+	DurationString DurationFormat = iota
+	// DurationISO8601 describes durations as ISO 8601 strings, such as
+	// "PT1H30M", with format "duration".
+	DurationISO8601
+	// DurationNanoseconds describes durations as integers of nanoseconds,
+	// which is how encoding/json writes a time.Duration that has no
+	// MarshalJSON of its own.
+	DurationNanoseconds
+)
+
+func (f DurationFormat) String() string {
+	switch f {
+	case DurationString:
+		return "string"
+	case DurationISO8601:
+		return "iso8601"
+	case DurationNanoseconds:
+		return "nanoseconds"
+	}
+	return fmt.Sprintf("DurationFormat(%d)", int(f))
+}
+
+// goDurationPattern matches the strings time.ParseDuration accepts.
+const goDurationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// durationSchema returns the schema of time.Duration in format, or nil to
+// reflect it as an integer.
+func durationSchema(format DurationFormat) *jsonschema.Schema {
+	switch format {
+	case DurationString:
+		return &jsonschema.Schema{Type: "string", Pattern: goDurationPattern}
+	case DurationISO8601:
+		return &jsonschema.Schema{Type: "string", Format: "duration"}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// DurationConfig has durations.
+type DurationConfig struct {
+	Timeout time.Duration   `json:"timeout"`
+	Backoff []time.Duration `json:"backoff"`
+}
+
+func TestDurationFormat(t *testing.T) {
+	for format, want := range map[DurationFormat]string{
+		DurationString:      `{"type":"string","pattern":"` + jsonEscape(goDurationPattern) + `"}`,
+		DurationISO8601:     `{"type":"string","format":"duration"}`,
+		DurationNanoseconds: `{"type":"integer"}`,
+	} {
+		out, err := NewGenerator(context.Background(), WithDurationFormat(format)).Generate(DurationConfig{})
+		if err != nil {
+			t.Fatalf("Generate(%s) error = %v", format, err)
+		}
+		var props struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(out, &props); err != nil {
+			t.Fatal(err)
+		}
+		if got := compactJSON(props.Properties["timeout"]); got != want {
+			t.Errorf("%s: timeout = %s, want %s", format, got, want)
+		}
+		if got := compactJSON(props.Properties["backoff"]); got != `{"items":`+want+`,"type":"array"}` {
+			t.Errorf("%s: backoff = %s", format, got)
+		}
+	}
+}
+
+func TestGoDurationPattern(t *testing.T) {
+	re := regexp.MustCompile(goDurationPattern)
+	for _, d := range []time.Duration{0, time.Nanosecond, 1500 * time.Nanosecond, -2 * time.Second, 90 * time.Minute, 26*time.Hour + 3*time.Millisecond} {
+		if !re.MatchString(d.String()) {
+			t.Errorf("pattern rejects %q", d.String())
+		}
+	}
+	for _, s := range []string{".5h", "1.h", "+3s", "1h1h"} {
+		if _, err := time.ParseDuration(s); err != nil || !re.MatchString(s) {
+			t.Errorf("pattern rejects %q (ParseDuration error %v)", s, err)
+		}
+	}
+	for _, s := range []string{"5", "1 h", "h", ".h", "1d", ""} {
+		if re.MatchString(s) {
+			t.Errorf("pattern accepts %q", s)
+		}
+	}
+}
+
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
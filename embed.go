@@ -0,0 +1,189 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// EmbedPolicy selects how the fields of embedded structs are described.
+type EmbedPolicy int
+
+const (
+	// EmbedFlatten lists the fields of embedded structs among the properties
+	// of the embedding struct, as encoding/json writes them (default).
+	EmbedFlatten EmbedPolicy = iota
+	// EmbedNested describes an embedded struct as a property named after its
+	// type, referring to the definition of the type, as yaml.v3 and the
+	// MongoDB driver write embedded structs that are not inlined.
+	EmbedNested
+	// EmbedAllOf describes the embedding struct as allOf the definition of
+	// each embedded struct and its own properties, modelling inheritance.
+	// As each part only knows its own properties, the embedded definitions
+	// allow additional properties and the embedding struct rejects unknown
+	// properties with "unevaluatedProperties" instead.
+	EmbedAllOf
+)
+
+func (p EmbedPolicy) String() string {
+	switch p {
+	case EmbedFlatten:
+		return "flatten"
+	case EmbedNested:
+		return "nested"
+	case EmbedAllOf:
+		return "allof"
+	}
+	return fmt.Sprintf("EmbedPolicy(%d)", int(p))
+}
+
+// applyEmbedPolicy is the schema pass replacing the flattened properties of
+// embedded structs as g.embedPolicy says.
+func (g *generator) applyEmbedPolicy(rf *reflection, s *jsonschema.Schema) error {
+	type structSchema struct {
+		t  reflect.Type
+		ts *jsonschema.Schema
+	}
+	// Definitions are added while embedding, so collect the structs first.
+	var structs []structSchema
+	_ = rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		structs = append(structs, structSchema{t, ts})
+		return nil
+	})
+	for _, st := range structs {
+		for i := 0; i < st.t.NumField(); i++ {
+			f := st.t.Field(i)
+			if err := g.embed(rf, s, st.t, st.ts, i); err != nil {
+				return fmt.Errorf("%s: %w", fieldRef(st.t, f), err)
+			}
+		}
+	}
+	return nil
+}
+
+// embed replaces the properties the embedded struct field index contributes
+// to ts, the schema of t. The fields of t before index are already embedded.
+func (g *generator) embed(rf *reflection, s *jsonschema.Schema, t reflect.Type, ts *jsonschema.Schema, index int) error {
+	f := t.Field(index)
+	if !isEmbeddedStruct(rf, f) || ts.Properties == nil {
+		return nil
+	}
+	ft := derefType(f.Type)
+	names := flattenedNames(rf, t, ts, f)
+	defName := rf.definitionName(ft)
+	if err := g.addNamedDefinition(rf, s, defName, reflect.New(ft).Elem().Interface()); err != nil {
+		return err
+	}
+	ref := &jsonschema.Schema{Ref: "#/$defs/" + defName}
+	var key string
+	if g.embedPolicy == EmbedNested {
+		key = nestedKey(rf, f)
+		if _, exists := ts.Properties.Get(key); exists {
+			return fmt.Errorf("embedded %s collides with property %s", ft, key)
+		}
+	}
+	// Rebuild the properties in the declaration order of the fields of t,
+	// putting a nested property at the position of its embedded field.
+	props := jsonschema.NewProperties()
+	add := func(name string) {
+		if _, done := props.Get(name); done || slices.Contains(names, name) {
+			return
+		}
+		if prop, ok := ts.Properties.Get(name); ok {
+			props.Set(name, prop)
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		switch {
+		case i == index:
+			if key != "" {
+				props.Set(key, ref)
+			}
+		case isEmbeddedStruct(rf, field) && i < index && g.embedPolicy == EmbedNested:
+			add(nestedKey(rf, field))
+		case isEmbeddedStruct(rf, field):
+			for _, name := range flattenedNames(rf, t, ts, field) {
+				add(name)
+			}
+		default:
+			name, _ := rf.fieldName(field)
+			add(name)
+		}
+	}
+	// Properties that are not fields of t, e.g. added by other passes, follow.
+	for pair := ts.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		add(pair.Key)
+	}
+	ts.Properties = props
+	ts.Required = slices.DeleteFunc(ts.Required, func(name string) bool {
+		return slices.Contains(names, name)
+	})
+	if g.embedPolicy == EmbedNested {
+		_, opts, _ := splitJSONTag(f.Tag.Get(rf.nameTag()))
+		if g.requiredPolicy.requires(f, opts) {
+			ts.Required = append(ts.Required, key)
+		}
+		return nil
+	}
+	ts.AllOf = append(ts.AllOf, ref)
+	if ts.AdditionalProperties == jsonschema.FalseSchema {
+		ts.AdditionalProperties = nil
+		setExtra(ts, "unevaluatedProperties", false)
+	}
+	if def := s.Definitions[defName]; def != nil && !isBooleanSchema(def) {
+		if def.AdditionalProperties == jsonschema.FalseSchema {
+			def.AdditionalProperties = nil
+		}
+		delete(def.Extras, "unevaluatedProperties")
+	}
+	return nil
+}
+
+// isEmbeddedStruct reports whether f is an embedded struct of a named type
+// whose fields are flattened into the embedding struct.
+func isEmbeddedStruct(rf *reflection, f reflect.StructField) bool {
+	if !f.Anonymous {
+		return false
+	}
+	if name, embedded := rf.fieldName(f); name != "" || !embedded {
+		return false
+	}
+	return derefType(f.Type).Name() != ""
+}
+
+// flattenedNames returns the properties of ts the embedded struct field f of
+// t contributes when flattened. Fields of t shadow the fields of embedded
+// structs.
+func flattenedNames(rf *reflection, t reflect.Type, ts *jsonschema.Schema, f reflect.StructField) []string {
+	own := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if name, _ := rf.fieldName(t.Field(i)); name != "" {
+			own[name] = true
+		}
+	}
+	var names []string
+	_ = rf.forEachField(derefType(f.Type), ts, func(fv fieldVisit) error {
+		if !own[fv.name] {
+			names = append(names, fv.name)
+		}
+		return nil
+	})
+	return names
+}
+
+// nestedKey returns the name of the property EmbedNested describes the
+// embedded struct field f with.
+func nestedKey(rf *reflection, f reflect.StructField) string {
+	key := f.Name
+	if tag := rf.nameTag(); tag == yamlTag || tag == bsonTag {
+		key = strings.ToLower(key)
+	}
+	if rf.KeyNamer != nil {
+		key = rf.KeyNamer(key)
+	}
+	return key
+}
@@ -0,0 +1,107 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// EmbedResource is the base of every resource.
+type EmbedResource struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// EmbedAudit records changes.
+type EmbedAudit struct {
+	UpdatedBy string `json:"updatedBy,omitempty"`
+}
+
+// EmbedDocument embeds EmbedResource and EmbedAudit.
+type EmbedDocument struct {
+	EmbedResource
+	*EmbedAudit
+	Title string `json:"title"`
+	// Kind shadows EmbedResource.Kind.
+	Kind int `json:"kind"`
+}
+
+type embedOutput struct {
+	Required              []string                   `json:"required"`
+	Properties            map[string]json.RawMessage `json:"properties"`
+	AllOf                 []json.RawMessage          `json:"allOf"`
+	AdditionalProperties  *bool                      `json:"additionalProperties"`
+	UnevaluatedProperties *bool                      `json:"unevaluatedProperties"`
+	Defs                  map[string]struct {
+		Properties           map[string]json.RawMessage `json:"properties"`
+		AdditionalProperties *bool                      `json:"additionalProperties"`
+	} `json:"$defs"`
+}
+
+func generateEmbedded(t *testing.T, policy EmbedPolicy) embedOutput {
+	t.Helper()
+	out, err := NewGenerator(context.Background(), WithEmbedPolicy(policy)).Generate(EmbedDocument{})
+	if err != nil {
+		t.Fatalf("Generate(%s) error = %v", policy, err)
+	}
+	var doc embedOutput
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func propertyNames(props map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestEmbedFlatten(t *testing.T) {
+	doc := generateEmbedded(t, EmbedFlatten)
+	if got, want := propertyNames(doc.Properties), []string{"id", "kind", "title", "updatedBy"}; !slices.Equal(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+}
+
+func TestEmbedNested(t *testing.T) {
+	doc := generateEmbedded(t, EmbedNested)
+	if got, want := propertyNames(doc.Properties), []string{"EmbedAudit", "EmbedResource", "kind", "title"}; !slices.Equal(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+	if got := compactJSON(doc.Properties["EmbedResource"]); got != `{"$ref":"#/$defs/EmbedResource"}` {
+		t.Errorf("EmbedResource = %s", got)
+	}
+	// Each property is at the position of its field, also kind, which
+	// shadows EmbedResource.Kind.
+	if got, want := doc.Required, []string{"EmbedResource", "EmbedAudit", "title", "kind"}; !slices.Equal(got, want) {
+		t.Errorf("required = %v, want %v", got, want)
+	}
+	if got, want := propertyNames(doc.Defs["EmbedResource"].Properties), []string{"id", "kind"}; !slices.Equal(got, want) {
+		t.Errorf("EmbedResource properties = %v, want %v", got, want)
+	}
+}
+
+func TestEmbedAllOf(t *testing.T) {
+	doc := generateEmbedded(t, EmbedAllOf)
+	if got, want := propertyNames(doc.Properties), []string{"kind", "title"}; !slices.Equal(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+	var refs []string
+	for _, s := range doc.AllOf {
+		refs = append(refs, compactJSON(s))
+	}
+	if want := []string{`{"$ref":"#/$defs/EmbedResource"}`, `{"$ref":"#/$defs/EmbedAudit"}`}; !slices.Equal(refs, want) {
+		t.Errorf("allOf = %v, want %v", refs, want)
+	}
+	if doc.AdditionalProperties != nil || doc.UnevaluatedProperties == nil || *doc.UnevaluatedProperties {
+		t.Errorf("additionalProperties = %v, unevaluatedProperties = %v", doc.AdditionalProperties, doc.UnevaluatedProperties)
+	}
+	if ap := doc.Defs["EmbedResource"].AdditionalProperties; ap != nil {
+		t.Errorf("EmbedResource additionalProperties = %v, want unset", *ap)
+	}
+}
@@ -0,0 +1,173 @@
+package schemator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// enumValue is an exported constant of a named string or integer type.
+type enumValue struct {
+	value any
+	// doc is the comment of the constant with markers removed.
+	doc string
+}
+
+// noImporter fails every import, so type checking a package for its
+// constants never loads other packages. Constants depending on imported
+// declarations are left without a value.
+type noImporter struct{}
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, fmt.Errorf("import %q not loaded", path)
+}
+
+// addEnums records the exported constants of the package made of files,
+// keyed by the CommentMap key of their type, in declaration order.
+func (c *goComments) addEnums(fset *token.FileSet, pkgPath string, files []*ast.File) {
+	docs := make(map[string]string)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				group := vs.Doc
+				if group == nil {
+					group = vs.Comment
+				}
+				if group == nil && len(gd.Specs) == 1 {
+					group = gd.Doc
+				}
+				text, _ := commentText(group)
+				for _, name := range vs.Names {
+					docs[name.Name] = text
+				}
+			}
+		}
+	}
+	conf := types.Config{Importer: noImporter{}, Error: func(error) {}}
+	pkg, _ := conf.Check(pkgPath, fset, files, nil)
+	if pkg == nil {
+		return
+	}
+	var consts []*types.Const
+	for _, name := range pkg.Scope().Names() {
+		if k, ok := pkg.Scope().Lookup(name).(*types.Const); ok && k.Exported() {
+			consts = append(consts, k)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+	if standardPackage(pkgPath) {
+		// Constants of the standard library are units and flags
+		// (time.Second, os.ModeDir) as often as enums.
+		return
+	}
+	for _, k := range consts {
+		named, ok := k.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() != pkg {
+			continue
+		}
+		value, ok := enumConstant(k.Val())
+		if !ok {
+			continue
+		}
+		key := pkgPath + "." + named.Obj().Name()
+		c.enums[key] = append(c.enums[key], enumValue{value: value, doc: docs[k.Name()]})
+	}
+}
+
+// enumConstant returns the JSON value of a string or integer constant.
+func enumConstant(v constant.Value) (any, bool) {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v), true
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i, true
+		}
+		if u, ok := constant.Uint64Val(v); ok {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// enumType returns the JSON Schema type of a constant value.
+func enumType(v any) string {
+	if _, ok := v.(string); ok {
+		return "string"
+	}
+	return "integer"
+}
+
+// standardPackage reports whether pkgPath looks like a standard library
+// package, whose first path element has no dot.
+func standardPackage(pkgPath string) bool {
+	return !strings.Contains(strings.SplitN(pkgPath, "/", 2)[0], ".")
+}
+
+// applyConstEnums is the schema pass turning named string and integer types
+// with exported constants into enums of those values. The comments of the
+// constants become "x-enum-descriptions", aligned with "enum". Enums set by
+// markers or tags, which run later, take precedence.
+func applyConstEnums(rf *reflection, s *jsonschema.Schema) error {
+	if len(rf.enums) == 0 {
+		return nil
+	}
+	return rf.forEachType(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if t.Kind() != reflect.Struct {
+			rf.applyTypeEnums(t, ts)
+			return nil
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			rf.applyTypeEnums(fv.field.Type, fv.schema)
+			return nil
+		})
+	})
+}
+
+// applyTypeEnums applies the constants of t, or of the element types of t,
+// to the schema t was reflected into.
+func (rf *reflection) applyTypeEnums(t reflect.Type, s *jsonschema.Schema) {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) || s.Ref != "" {
+		return
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values := rf.enums[typeKey(t)]
+		if len(values) == 0 || len(s.Enum) > 0 || s.Type != enumType(values[0].value) {
+			return
+		}
+		s.Enum = make([]any, len(values))
+		descriptions := make([]any, len(values))
+		described := false
+		for i, v := range values {
+			s.Enum[i] = v.value
+			descriptions[i] = v.doc
+			described = described || v.doc != ""
+		}
+		if described {
+			setExtra(s, "x-enum-descriptions", descriptions)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			rf.applyTypeEnums(t.Elem(), s.Items)
+		}
+	case reflect.Map:
+		rf.applyTypeEnums(t.Elem(), s.AdditionalProperties)
+	}
+}
@@ -0,0 +1,67 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// EnumColor is a color.
+type EnumColor string
+
+const (
+	// EnumRed is warm.
+	EnumRed EnumColor = "red"
+	// EnumBlue is cool.
+	EnumBlue  EnumColor = "blue"
+	enumGreen EnumColor = "green"
+)
+
+// EnumLevel is a log level.
+type EnumLevel int
+
+const (
+	EnumDebug EnumLevel = iota - 1
+	EnumInfo
+	EnumWarn
+)
+
+// EnumPalette uses types with const blocks.
+type EnumPalette struct {
+	Primary EnumColor            `json:"primary"`
+	Others  []EnumColor          `json:"others"`
+	Levels  map[string]EnumLevel `json:"levels"`
+	Fixed   EnumColor            `json:"fixed" validate:"oneof=red"`
+}
+
+func TestConstEnums(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(EnumPalette{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Enum                 []any           `json:"enum"`
+			Descriptions         []any           `json:"x-enum-descriptions"`
+			Items                json.RawMessage `json:"items"`
+			AdditionalProperties json.RawMessage `json:"additionalProperties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	primary := doc.Properties["primary"]
+	if !reflect.DeepEqual(primary.Enum, []any{"red", "blue"}) || !reflect.DeepEqual(primary.Descriptions, []any{"EnumRed is warm.", "EnumBlue is cool."}) {
+		t.Fatalf("primary = %+v", primary)
+	}
+	if got := compactJSON(doc.Properties["others"].Items); got != `{"type":"string","enum":["red","blue"],"x-enum-descriptions":["EnumRed is warm.","EnumBlue is cool."]}` {
+		t.Fatalf("others items = %s", got)
+	}
+	if got := compactJSON(doc.Properties["levels"].AdditionalProperties); got != `{"type":"integer","enum":[-1,0,1]}` {
+		t.Fatalf("levels values = %s", got)
+	}
+	if fixed := doc.Properties["fixed"]; !reflect.DeepEqual(fixed.Enum, []any{"red"}) {
+		t.Fatalf("fixed = %+v, want the validate tag to win", fixed)
+	}
+}
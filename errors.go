@@ -0,0 +1,43 @@
+package schemator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors callers can test for with errors.Is.
+var (
+	// ErrModuleNotFound is returned when no go.mod is found in a directory
+	// or any of its parents.
+	ErrModuleNotFound = errors.New("go.mod not found")
+	// ErrNoGoFiles is returned when a directory expected to hold a package
+	// has no Go source files.
+	ErrNoGoFiles = errors.New("no go source files found")
+	// ErrUnnameableModel is returned in strict mode for models no filename
+	// can be derived for.
+	ErrUnnameableModel = errors.New("cannot derive a filename for model")
+	// ErrGoListFailed is returned when the go command fails to load a
+	// package. The error is a *GoListError.
+	ErrGoListFailed = errors.New("go list failed")
+)
+
+// GoListError is returned when the go command, run through
+// golang.org/x/tools/go/packages, fails to load packages. It matches
+// ErrGoListFailed, and the error of the loader through Err.
+type GoListError struct {
+	// Packages are the import paths that were loaded.
+	Packages []string
+	// Output is what the go command reported.
+	Output string
+	// Err is the error of the loader, if any.
+	Err error
+}
+
+func (e *GoListError) Error() string {
+	return fmt.Sprintf("load %s: %s", strings.Join(e.Packages, " "), e.Output)
+}
+
+func (e *GoListError) Unwrap() []error {
+	return []error{ErrGoListFailed, e.Err}
+}
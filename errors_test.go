@@ -0,0 +1,35 @@
+package schemator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	if _, err := inferLocalImportPath(context.Background(), t.TempDir()); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("inferLocalImportPath() outside a module error = %v, want ErrModuleNotFound", err)
+	}
+	if _, err := detectPackageName(t.TempDir()); !errors.Is(err, ErrNoGoFiles) {
+		t.Errorf("detectPackageName() error = %v, want ErrNoGoFiles", err)
+	}
+	err := NewGenerator(context.Background(), WithStrict()).WriteSchemas(t.TempDir(), map[string]int{})
+	if !errors.Is(err, ErrUnnameableModel) {
+		t.Errorf("WriteSchemas() error = %v, want ErrUnnameableModel", err)
+	}
+
+	_, err = ensureSourceDirectory(context.Background(), ImportPath{ModuleImportPath: "example.com/does/not/exist"})
+	var goListErr *GoListError
+	if !errors.Is(err, ErrGoListFailed) || !errors.As(err, &goListErr) {
+		t.Fatalf("ensureSourceDirectory() error = %v, want a *GoListError", err)
+	}
+	if len(goListErr.Packages) != 1 || goListErr.Packages[0] != "example.com/does/not/exist" || goListErr.Output == "" {
+		t.Errorf("GoListError = %+v", goListErr)
+	}
+	var pkgErr packages.Error
+	if !errors.As(err, &pkgErr) {
+		t.Errorf("ensureSourceDirectory() error = %v, want the packages.Error", err)
+	}
+}
@@ -0,0 +1,155 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// applyExampleTags adds the values of `example:"..."` struct tags to the
+// examples of a property. A tag may be repeated for several examples:
+//
+//	Port  int      `json:"port" example:"8080" example:"443"`
+//	Hosts []string `json:"hosts" example:"a.example.com,b.example.com"`
+//
+// Values are typed after the field, see tagValue.
+func applyExampleTags(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			for _, raw := range lookupAll(fv.field.Tag, "example") {
+				v, err := tagValue(fv.field.Type, fv.schema, raw)
+				if err != nil {
+					return fmt.Errorf("%s.%s: example: %w", typeKey(fv.owner), fv.field.Name, err)
+				}
+				fv.schema.Examples = append(fv.schema.Examples, v)
+			}
+			return nil
+		})
+	})
+}
+
+// lookupAll returns the values of every occurrence of key in tag, which
+// reflect.StructTag.Lookup limits to the first.
+func lookupAll(tag reflect.StructTag, key string) []string {
+	var values []string
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		// Scan to colon. A space, a quote or a control character is a
+		// syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+		if name == key {
+			if value, err := strconv.Unquote(qvalue); err == nil {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// tagValue converts the struct tag value raw to a JSON value of type t:
+// numbers and booleans are parsed, slices and arrays take a JSON array or a
+// comma separated list of elements, and maps and structs take JSON. Fields
+// reflected as strings (time.Time, text marshalers) keep raw as is.
+func tagValue(t reflect.Type, s *jsonschema.Schema, raw string) (any, error) {
+	t = derefType(t)
+	if s != nil && s.Type == "string" {
+		return raw, nil
+	}
+	switch kind := t.Kind(); {
+	case kind == reflect.String:
+		return raw, nil
+	case kind == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return b, nil
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		if _, err := strconv.ParseInt(raw, 10, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", raw, t)
+		}
+		return json.Number(raw), nil
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		if _, err := strconv.ParseUint(raw, 10, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", raw, t)
+		}
+		return json.Number(raw), nil
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		if _, err := strconv.ParseFloat(raw, t.Bits()); err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", raw, t)
+		}
+		return json.Number(raw), nil
+	case kind == reflect.Slice || kind == reflect.Array:
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			return jsonTagValue(raw)
+		}
+		var items *jsonschema.Schema
+		if s != nil {
+			items = s.Items
+		}
+		out := []any{}
+		if raw == "" {
+			return out, nil
+		}
+		for _, item := range splitEscaped(raw, ',') {
+			v, err := tagValue(t.Elem(), items, strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case kind == reflect.Interface:
+		if v, err := jsonTagValue(raw); err == nil {
+			return v, nil
+		}
+		return raw, nil
+	}
+	return jsonTagValue(raw)
+}
+
+func jsonTagValue(raw string) (any, error) {
+	d := json.NewDecoder(strings.NewReader(raw))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%q is not valid JSON: %w", raw, err)
+	}
+	if d.More() {
+		return nil, fmt.Errorf("%q is not a single JSON value", raw)
+	}
+	return v, nil
+}
@@ -0,0 +1,60 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ExampleServer carries example tags.
+type ExampleServer struct {
+	Host    string            `json:"host" example:"db.example.com"`
+	Port    int               `json:"port" example:"8080" example:"443"`
+	Ratio   float64           `json:"ratio" example:"0.5"`
+	TLS     *bool             `json:"tls" example:"true"`
+	Aliases []string          `json:"aliases" example:"a\\,b,c"`
+	Weights []int             `json:"weights" example:"[1, 2]"`
+	Labels  map[string]string `json:"labels" example:"{\"env\":\"prod\"}"`
+	Started time.Time         `json:"started" example:"2025-01-01T00:00:00Z"`
+}
+
+// BadExample has an example that does not match its type.
+type BadExample struct {
+	Port int `json:"port" example:"eighty"`
+}
+
+func TestExampleTags(t *testing.T) {
+	gen := New(context.Background(), nil)
+	out, err := gen.Generate(ExampleServer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Examples []any `json:"examples"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]any{
+		"host":    {"db.example.com"},
+		"port":    {8080.0, 443.0},
+		"ratio":   {0.5},
+		"tls":     {true},
+		"aliases": {[]any{"a,b", "c"}},
+		"weights": {[]any{1.0, 2.0}},
+		"labels":  {map[string]any{"env": "prod"}},
+		"started": {"2025-01-01T00:00:00Z"},
+	}
+	for prop, examples := range want {
+		if got := doc.Properties[prop].Examples; !reflect.DeepEqual(got, examples) {
+			t.Fatalf("%s examples = %#v, want %#v", prop, got, examples)
+		}
+	}
+	if _, err := gen.Generate(BadExample{}); err == nil {
+		t.Fatal("expected error for a non-numeric example of an int field")
+	}
+}
@@ -0,0 +1,193 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Directives declaring that a field conforms to a hand-written schema file:
+//
+//	// Payment follows the schema published by the payments team.
+//	// schemator:ref=../schemas/payment.schema.json
+//	Payment json.RawMessage `json:"payment"`
+//
+// "ref=<path>" replaces the property with a $ref to the file, by its $id when
+// it has one and otherwise by its path relative to the written schema file,
+// or its file URI when the schema is not written to disk; "embed=<path>" copies the file into the
+// definitions and refers to the copy. Paths are relative to the directory of
+// the package declaring the field. The file must exist and is recorded as a
+// dependency of the generated schema.
+const (
+	refDirective   = schematorDirective + "ref"
+	embedDirective = schematorDirective + "embed"
+)
+
+// applyExternalSchemas applies the ref and embed directives.
+func applyExternalSchemas(rf *reflection, s *jsonschema.Schema) error {
+	embedded := make(map[string]string)
+	replaced := false
+	err := rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			fieldKey := typeKey(fv.owner) + "." + fv.field.Name
+			refs := directiveValues(rf.markers[fieldKey], refDirective)
+			embeds := directiveValues(rf.markers[fieldKey], embedDirective)
+			if len(refs)+len(embeds) == 0 {
+				return nil
+			}
+			if len(refs)+len(embeds) > 1 {
+				return fmt.Errorf("%s: more than one %s or %s directive", fieldKey, refDirective, embedDirective)
+			}
+			p := append(refs, embeds...)[0]
+			file, ext, err := rf.readExternalSchema(fv.owner, p)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fieldKey, err)
+			}
+			rf.dependencies = appendUnique(rf.dependencies, file)
+			var ref string
+			if len(refs) > 0 {
+				ref = ext.ID.String()
+				if ref == "" {
+					// Rebased on the written schema file by rebaseExternalRefs.
+					ref = fileURI(file)
+					if rf.externalRefs == nil {
+						rf.externalRefs = make(map[string]string)
+					}
+					rf.externalRefs[ref] = file
+				}
+			} else {
+				name, err := embedExternalSchema(s, file, ext, embedded)
+				if err != nil {
+					return fmt.Errorf("%s: %w", fieldKey, err)
+				}
+				ref = "#/$defs/" + name
+			}
+			*fv.schema = jsonschema.Schema{Ref: ref, Title: fv.schema.Title, Description: fv.schema.Description}
+			replaced = true
+			return nil
+		})
+	})
+	if replaced {
+		pruneDefinitions(s)
+	}
+	return err
+}
+
+// readExternalSchema reads the schema file p, relative to the source
+// directory of the package of owner unless absolute.
+func (rf *reflection) readExternalSchema(owner reflect.Type, p string) (string, *jsonschema.Schema, error) {
+	file := filepath.FromSlash(p)
+	if !filepath.IsAbs(file) {
+		dir, ok := rf.packageDirs[owner.PkgPath()]
+		if !ok {
+			return "", nil, fmt.Errorf("schema file %s: source directory of %s is unknown", p, owner.PkgPath())
+		}
+		file = filepath.Join(dir, file)
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("schema file %s: %w", p, err)
+	}
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("schema file %s: %w", p, err)
+	}
+	ext := &jsonschema.Schema{}
+	if err := json.Unmarshal(contents, ext); err != nil {
+		return "", nil, fmt.Errorf("schema file %s: %w", p, err)
+	}
+	// Draft-07 and older keep definitions under "definitions", which the
+	// Schema type does not read.
+	var legacy struct {
+		Definitions jsonschema.Definitions `json:"definitions"`
+	}
+	if err := json.Unmarshal(contents, &legacy); err != nil {
+		return "", nil, fmt.Errorf("schema file %s: %w", p, err)
+	}
+	for name, def := range legacy.Definitions {
+		if ext.Definitions == nil {
+			ext.Definitions = make(jsonschema.Definitions)
+		}
+		if _, exists := ext.Definitions[name]; !exists {
+			ext.Definitions[name] = def
+		}
+	}
+	return file, ext, nil
+}
+
+// embedExternalSchema adds ext, read from file, to the definitions of s and
+// returns its name, the base name of the file without extension. The
+// definitions of ext are hoisted into s and its references to itself are
+// rewritten. embedded maps the names already used to their files.
+func embedExternalSchema(s *jsonschema.Schema, file string, ext *jsonschema.Schema, embedded map[string]string) (string, error) {
+	name := filepath.Base(file)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".schema")
+	if embedded[name] == file {
+		return name, nil
+	}
+	if s.Definitions == nil {
+		s.Definitions = make(jsonschema.Definitions)
+	}
+	if _, exists := s.Definitions[name]; exists {
+		return "", fmt.Errorf("embedded schema %s collides with an existing definition", name)
+	}
+	err := walkSchema(ext, func(n *jsonschema.Schema) error {
+		if n.Ref == "#" {
+			n.Ref = "#/$defs/" + name
+		} else if def, ok := strings.CutPrefix(n.Ref, "#/definitions/"); ok {
+			n.Ref = "#/$defs/" + def
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	for defName, def := range ext.Definitions {
+		if existing, exists := s.Definitions[defName]; exists && compactJSON(existing) != compactJSON(def) {
+			return "", fmt.Errorf("definition %s of embedded schema %s collides with an existing definition", defName, name)
+		}
+		s.Definitions[defName] = def
+	}
+	ext.Definitions = nil
+	ext.Version = ""
+	ext.ID = ""
+	s.Definitions[name] = ext
+	embedded[name] = file
+	return name, nil
+}
+
+// fileURI returns the file URI of the absolute path file.
+func fileURI(file string) string {
+	p := filepath.ToSlash(file)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+// rebaseExternalRefs makes the references of s to external schema files by
+// path relative to the directory of file, the schema file s is written to.
+// Without a file, or when no relative path exists, they stay file URIs.
+func rebaseExternalRefs(rf *reflection, s *jsonschema.Schema, file string) error {
+	if len(rf.externalRefs) == 0 || file == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	return walkSchema(s, func(n *jsonschema.Schema) error {
+		if target, ok := rf.externalRefs[n.Ref]; ok {
+			if rel, err := filepath.Rel(dir, target); err == nil {
+				n.Ref = filepath.ToSlash(rel)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ExternalOrder uses hand-written schemas for some fields.
+type ExternalOrder struct {
+	// Payment is owned by the payments team.
+	// schemator:ref=testdata/external/payment.schema.json
+	Payment json.RawMessage `json:"payment"`
+	// schemator:embed=testdata/external/address.schema.json
+	Shipping ExternalAddress `json:"shipping"`
+	// schemator:embed=testdata/external/address.schema.json
+	Billing *ExternalAddress `json:"billing,omitempty"`
+}
+
+// ExternalAddress is replaced by the embedded schema.
+type ExternalAddress struct {
+	Street string `json:"street"`
+}
+
+// ExternalMissing refers to a schema file that does not exist.
+type ExternalMissing struct {
+	// schemator:ref=testdata/external/missing.schema.json
+	Value json.RawMessage `json:"value"`
+}
+
+// ExternalInOutput refers to a hand-written schema kept in its output
+// directory.
+type ExternalInOutput struct {
+	// schemator:ref=testdata/extout/Payment.schema.json
+	Payment json.RawMessage `json:"payment"`
+}
+
+// writeToExtout writes the schema of ExternalInOutput to testdata/extout with
+// opts, removing what it wrote when the test ends.
+func writeToExtout(t *testing.T, opts ...Option) string {
+	t.Helper()
+	dir := filepath.Join("testdata", "extout")
+	t.Cleanup(func() {
+		for _, name := range []string{"ExternalInOutput.schema.json", ManifestFilename} {
+			_ = os.Remove(filepath.Join(dir, name))
+		}
+	})
+	if err := NewGenerator(context.Background(), opts...).WriteSchemas(dir, ExternalInOutput{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	return dir
+}
+
+func TestExternalSchemas(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(ExternalOrder{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]map[string]any  `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	payment := doc.Properties["payment"]
+	if payment["$ref"] != "https://example.com/schemas/payment.schema.json" || payment["description"] != "Payment is owned by the payments team." {
+		t.Fatalf("payment = %v", payment)
+	}
+	for _, prop := range []string{"shipping", "billing"} {
+		if got := doc.Properties[prop]["$ref"]; got != "#/$defs/address" {
+			t.Fatalf("%s $ref = %v", prop, got)
+		}
+	}
+	want := `{"properties":{"lines":{"items":{"$ref":"#/$defs/line"},"type":"array"},"forward":{"$ref":"#/$defs/address"}},"type":"object"}`
+	if got := compactJSON(doc.Defs["address"]); got != want {
+		t.Fatalf("$defs/address = %s, want %s", got, want)
+	}
+	if _, ok := doc.Defs["line"]; !ok {
+		t.Fatalf("definitions of the embedded schema not hoisted: %s", out)
+	}
+	if _, ok := doc.Defs["ExternalAddress"]; ok {
+		t.Fatalf("replaced definition not pruned: %s", out)
+	}
+
+	_, err = New(context.Background(), nil).Generate(ExternalMissing{})
+	if err == nil || !strings.Contains(err.Error(), "missing.schema.json") {
+		t.Fatalf("Generate() error = %v, want missing file error", err)
+	}
+}
+
+func TestExternalSchemaDependencies(t *testing.T) {
+	outDir := t.TempDir()
+	if err := New(context.Background(), nil).WriteSchemas(outDir, ExternalOrder{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	m, err := ReadManifest(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 1 {
+		t.Fatalf("artifacts = %+v", m.Artifacts)
+	}
+	var want []string
+	for _, f := range []string{"payment.schema.json", "address.schema.json"} {
+		abs, err := filepath.Abs(filepath.Join("testdata", "external", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel, err := filepath.Rel(outDir, abs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, filepath.ToSlash(rel))
+	}
+	if got := m.Artifacts[0].Dependencies; !slices.Equal(got, want) {
+		t.Fatalf("dependencies = %v, want %v", got, want)
+	}
+}
+
+func TestPruneKeepsExternalSchemaDependencies(t *testing.T) {
+	dir := writeToExtout(t, WithPrune())
+	if _, err := os.Stat(filepath.Join(dir, "Payment.schema.json")); err != nil {
+		t.Fatalf("WithPrune removed a dependency: %v", err)
+	}
+}
+
+func TestExternalRefsRelativeToOutputFile(t *testing.T) {
+	refOf := func(out []byte) string {
+		var doc struct {
+			Properties map[string]struct {
+				Ref string `json:"$ref"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc.Properties["payment"].Ref
+	}
+	dir := writeToExtout(t)
+	out, err := os.ReadFile(filepath.Join(dir, "ExternalInOutput.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := refOf(out); got != "Payment.schema.json" {
+		t.Errorf("$ref in the directory of the file = %q, want Payment.schema.json", got)
+	}
+
+	outDir := t.TempDir()
+	if err := New(context.Background(), nil).WriteSchemas(outDir, ExternalInOutput{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if out, err = os.ReadFile(filepath.Join(outDir, "ExternalInOutput.schema.json")); err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(filepath.Join(dir, "Payment.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.Rel(outDir, abs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := refOf(out); got != filepath.ToSlash(want) {
+		t.Errorf("$ref in another directory = %q, want %q", got, filepath.ToSlash(want))
+	}
+
+	if out, err = New(context.Background(), nil).Generate(ExternalInOutput{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := refOf(out); got != fileURI(abs) {
+		t.Errorf("$ref without an output file = %q, want %q", got, fileURI(abs))
+	}
+}
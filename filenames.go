@@ -0,0 +1,116 @@
+package schemator
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FilenameCase selects how FilenameStrategy spells type names.
+type FilenameCase int
+
+const (
+	// FilenamePascalCase keeps the type name, e.g. HTTPServer (default).
+	FilenamePascalCase FilenameCase = iota
+	// FilenameSnakeCase writes the words of the type name in lower case
+	// separated by underscores, e.g. http_server.
+	FilenameSnakeCase
+	// FilenameKebabCase writes the words of the type name in lower case
+	// separated by hyphens, e.g. http-server.
+	FilenameKebabCase
+)
+
+func (c FilenameCase) String() string {
+	switch c {
+	case FilenamePascalCase:
+		return "pascal"
+	case FilenameSnakeCase:
+		return "snake"
+	case FilenameKebabCase:
+		return "kebab"
+	}
+	return fmt.Sprintf("FilenameCase(%d)", int(c))
+}
+
+// FilenameStrategy derives schema filenames from the type name of a model.
+// Its Filename method is meant for WithFilenameFunc:
+//
+//	schemator.WithFilenameFunc(schemator.FilenameStrategy{Case: schemator.FilenameSnakeCase, PackagePrefix: true}.Filename)
+//
+// names the schema of v1.HTTPServer v1.http_server.schema.json.
+type FilenameStrategy struct {
+	// Case selects how the type name is spelled.
+	Case FilenameCase
+	// PackagePrefix puts the name of the package declaring the type and a
+	// dot in front of the type name.
+	PackagePrefix bool
+	// OmitExtension leaves out the .schema.json extension.
+	OmitExtension bool
+}
+
+// Filename returns the filename of the schema of model, or "" if no name can
+// be derived.
+func (s FilenameStrategy) Filename(model any) string {
+	name := toString(model)
+	if name == "" {
+		return ""
+	}
+	switch s.Case {
+	case FilenameSnakeCase:
+		name = strings.Join(nameWords(name), "_")
+	case FilenameKebabCase:
+		name = strings.Join(nameWords(name), "-")
+	}
+	if s.PackagePrefix {
+		if pkg := modelPackage(model); pkg != "" {
+			name = pkg + "." + name
+		}
+	}
+	if !s.OmitExtension {
+		name += JSONSchemaFormat.Extension
+	}
+	return name
+}
+
+// modelPackage returns the name of the package declaring model, or of its
+// element type for unnamed slices, arrays and maps.
+func modelPackage(model any) string {
+	pkgPath := typePkgPath(derefType(reflect.TypeOf(model)))
+	if pkgPath == "" {
+		return ""
+	}
+	return path.Base(pkgPath)
+}
+
+// nameWords splits an identifier into lower case words at case changes and
+// non-alphanumeric runes, keeping acronyms together: HTTPServerV2 becomes
+// http, server, v2.
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,64 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// HTTPServerV2 has a name with an acronym and a digit.
+type HTTPServerV2 struct {
+	Addr string `json:"addr"`
+}
+
+func TestFilenameStrategy(t *testing.T) {
+	for _, tt := range []struct {
+		strategy FilenameStrategy
+		model    any
+		want     string
+	}{
+		{FilenameStrategy{}, HTTPServerV2{}, "HTTPServerV2.schema.json"},
+		{FilenameStrategy{Case: FilenameSnakeCase}, HTTPServerV2{}, "http_server_v2.schema.json"},
+		{FilenameStrategy{Case: FilenameKebabCase}, &OrderedRecord{}, "ordered-record.schema.json"},
+		{FilenameStrategy{Case: FilenameKebabCase, OmitExtension: true}, []OrderedBase{}, "ordered-base-slice"},
+		{FilenameStrategy{PackagePrefix: true}, []OrderedBase{}, "schemator.OrderedBaseSlice.schema.json"},
+		{FilenameStrategy{Case: FilenameSnakeCase, PackagePrefix: true}, HTTPServerV2{}, "schemator.http_server_v2.schema.json"},
+		{FilenameStrategy{}, map[string]string{}, ""},
+	} {
+		if got := tt.strategy.Filename(tt.model); got != tt.want {
+			t.Errorf("%+v.Filename(%T) = %q, want %q", tt.strategy, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestWithFilenameFunc(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewGenerator(context.Background(), WithFilenameFunc(func(model any) string {
+		if _, ok := model.(HTTPServerV2); ok {
+			return "server.json"
+		}
+		return ""
+	}))
+	if err := gen.WriteSchemas(dir, HTTPServerV2{}, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != "OrderedBase.schema.json,server.json" {
+		t.Errorf("files = %s", got)
+	}
+	if err := gen.Verify(dir, HTTPServerV2{}, OrderedBase{}); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "HTTPServerV2.schema.json")); err == nil {
+		t.Error("default filename written")
+	}
+}
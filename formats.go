@@ -0,0 +1,104 @@
+package schemator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"pkt.systems/logport"
+)
+
+// Format is an output format WriteAll can emit for a model. Besides the
+// built-in formats, any emitter can be plugged in by providing Generate
+// (e.g. a TypeScript or Avro generator).
+type Format struct {
+	// Name identifies the format in the manifest, e.g. "jsonschema".
+	Name string
+	// Extension is appended to the model name to form the filename,
+	// including the leading dot.
+	Extension string
+	// Generate renders model using g.
+	Generate func(g Generator, model any) (SchemaBytes, error)
+}
+
+// Built-in formats.
+var (
+	JSONSchemaFormat = Format{Name: "jsonschema", Extension: ".schema.json", Generate: Generator.Generate}
+	XSDFormat        = Format{Name: "xsd", Extension: ".xsd", Generate: Generator.GenerateXSD}
+	GraphQLFormat    = Format{Name: "graphql", Extension: ".graphql", Generate: Generator.GenerateGraphQL}
+	OpenAPI30Format  = Format{Name: "openapi-3.0", Extension: ".openapi.json", Generate: Generator.GenerateOpenAPI30}
+)
+
+// Output selects the formats WriteAll writes for Model. No formats means
+// JSON Schema only.
+type Output struct {
+	Model   any
+	Formats []Format
+}
+
+func (g *generator) WriteAll(outputDir string, outputs ...Output) error {
+	l := logport.LoggerFromContext(g.ctx).With("outputDir", outputDir)
+	// Name every file first, so colliding names fail before anything is
+	// written.
+	var models []any
+	var formats []Format
+	var filenames []string
+	for _, o := range outputs {
+		outputFormats := o.Formats
+		if len(outputFormats) == 0 {
+			outputFormats = []Format{JSONSchemaFormat}
+		}
+		for _, f := range outputFormats {
+			if f.Name == "" || f.Generate == nil {
+				return fmt.Errorf("format %q for %T needs a name and a Generate function", f.Name, o.Model)
+			}
+			filename := g.artifactFilename(o.Model, f.Extension)
+			if f.Name == JSONSchemaFormat.Name {
+				filename = g.schemaFilename(o.Model)
+			}
+			if filename == "" {
+				if g.strict {
+					return unnamedModelError(o.Model)
+				}
+				l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", o.Model)
+				break
+			}
+			models = append(models, o.Model)
+			formats = append(formats, f)
+			filenames = append(filenames, filename)
+		}
+	}
+	filenames, err := g.uniqueFilenames(models, filenames)
+	if err != nil {
+		return err
+	}
+	var artifacts []Artifact
+	progress := g.modelProgress(filenames)
+	for i, model := range models {
+		f, filename := formats[i], filenames[i]
+		start := progress.start(model)
+		file := filepath.Join(outputDir, filepath.FromSlash(filename))
+		var out []byte
+		if f.Name == JSONSchemaFormat.Name {
+			// References to external schema files are relative to file.
+			out, _, err = g.generateTo(model, file)
+		} else {
+			out, err = f.Generate(g, model)
+		}
+		progress.finish(model, start, err)
+		if err != nil {
+			return fmt.Errorf("%s for %s: %w", f.Name, filename, err)
+		}
+		if err := g.writeFile(file, out, "model", model, "format", f.Name); err != nil {
+			return err
+		}
+		artifacts = append(artifacts, g.newArtifact(filename, model, f.Name, out))
+	}
+	created, err := sourceDate()
+	if err != nil {
+		return err
+	}
+	if err := recordArtifacts(outputDir, artifacts, created, g.write); err != nil {
+		return err
+	}
+	return g.finishOutputDir(outputDir, artifacts)
+}
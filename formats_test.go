@@ -0,0 +1,62 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestWriteAllFormatMatrix(t *testing.T) {
+	outDir := t.TempDir()
+	upper := Format{
+		Name:      "upper",
+		Extension: ".txt",
+		Generate: func(g Generator, model any) (SchemaBytes, error) {
+			return SchemaBytes("custom " + toString(model)), nil
+		},
+	}
+	gen := NewGenerator(context.Background(), WithVersion("v1"))
+	err := gen.WriteAll(outDir,
+		Output{Model: example.Subject{}, Formats: []Format{JSONSchemaFormat, upper}},
+		Output{Model: example.Example{}},
+	)
+	if err != nil {
+		t.Fatalf("WriteAll() error = %v", err)
+	}
+	for _, file := range []string{"Subject.v1.schema.json", "Subject.v1.txt", "Example.v1.schema.json"} {
+		if _, err := os.Stat(filepath.Join(outDir, file)); err != nil {
+			t.Fatalf("%s missing: %v", file, err)
+		}
+	}
+	if b, _ := os.ReadFile(filepath.Join(outDir, "Subject.v1.txt")); string(b) != "custom Subject\n" {
+		t.Fatalf("custom output = %q", b)
+	}
+	m, err := ReadManifest(outDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	formats := make(map[string]string)
+	for _, a := range m.Artifacts {
+		formats[a.File] = a.Format
+	}
+	want := map[string]string{
+		"Subject.v1.schema.json": "jsonschema",
+		"Subject.v1.txt":         "upper",
+		"Example.v1.schema.json": "jsonschema",
+	}
+	for file, format := range want {
+		if formats[file] != format {
+			t.Fatalf("manifest format for %s = %q, want %q (%+v)", file, formats[file], format, m.Artifacts)
+		}
+	}
+}
+
+func TestWriteAllRejectsIncompleteFormat(t *testing.T) {
+	err := NewGenerator(context.Background()).WriteAll(t.TempDir(), Output{Model: example.Subject{}, Formats: []Format{{Name: "broken"}}})
+	if err == nil {
+		t.Fatal("expected error for a format without Generate")
+	}
+}
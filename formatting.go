@@ -0,0 +1,44 @@
+package schemator
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// defaultIndent indents generated JSON unless WithIndent or WithCompact says
+// otherwise.
+const defaultIndent = "  "
+
+// marshalJSON renders v as JSON formatted as set by WithIndent and
+// WithCompact.
+func (g *generator) marshalJSON(v any) ([]byte, error) {
+	if g.compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", g.indent)
+}
+
+// formatJSON reformats the JSON document data as marshalJSON would.
+func (g *generator) formatJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if g.compact {
+		err = json.Compact(&buf, data)
+	} else {
+		err = json.Indent(&buf, data, "", g.indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fileContent returns out as written to a file: without trailing newlines,
+// followed by exactly one unless WithTrailingNewline(false) was given.
+func (g *generator) fileContent(out []byte) []byte {
+	out = bytes.TrimRight(out, "\n")
+	if !g.trailingNewline {
+		return out
+	}
+	return append(out[:len(out):len(out)], '\n')
+}
@@ -0,0 +1,58 @@
+package schemator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormatting(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		opts   []Option
+		prefix string
+	}{
+		{"default", nil, "{\n  \"$schema\""},
+		{"indent", []Option{WithIndent("\t")}, "{\n\t\"$schema\""},
+		{"compact", []Option{WithCompact()}, "{\"$schema\""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewGenerator(context.Background(), tt.opts...).Generate(OrderedBase{})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if !strings.HasPrefix(string(out), tt.prefix) {
+				t.Errorf("Generate() = %s, want prefix %q", out, tt.prefix)
+			}
+			if tt.name == "compact" && bytes.ContainsAny(out, "\n\t") {
+				t.Errorf("Generate() = %s, want a single line", out)
+			}
+		})
+	}
+}
+
+func TestTrailingNewline(t *testing.T) {
+	for _, tt := range []struct {
+		opts   []Option
+		suffix string
+	}{
+		{nil, "}\n"},
+		{[]Option{WithTrailingNewline(true), WithCompact()}, "}\n"},
+		{[]Option{WithTrailingNewline(false)}, "}"},
+	} {
+		dir := t.TempDir()
+		if err := NewGenerator(context.Background(), tt.opts...).WriteSchemas(dir, OrderedBase{}); err != nil {
+			t.Fatalf("WriteSchemas() error = %v", err)
+		}
+		out, err := os.ReadFile(filepath.Join(dir, "OrderedBase.schema.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasSuffix(out, []byte(tt.suffix)) || bytes.HasSuffix(out, []byte(tt.suffix+"\n")) {
+			t.Errorf("file ends with %q, want %q", out[len(out)-3:], tt.suffix)
+		}
+	}
+}
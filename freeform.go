@@ -0,0 +1,141 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Directives declaring the schema of the values of a free-form map field:
+//
+//	// Annotations are free-form, but x- keys hold extensions.
+//	// schemator:prefix=x-:Extension
+//	// schemator:values=Annotation
+//	Annotations map[string]any `json:"annotations"`
+//
+// "prefix=<prefix>:<name>" (repeatable) validates the values of keys
+// starting with prefix against the schema registered as name;
+// "values=<name>" validates every other value, and "values=false" rejects
+// other keys.
+const (
+	prefixDirective = schematorDirective + "prefix"
+	valuesDirective = schematorDirective + "values"
+)
+
+// applyFreeFormDirectives applies the prefix and values directives to map
+// fields, adding the named schemas registered with WithNamedSchema to the
+// definitions.
+func (g *generator) applyFreeFormDirectives(rf *reflection, s *jsonschema.Schema) error {
+	added := make(map[string]bool)
+	use := func(name string) (*jsonschema.Schema, error) {
+		model, ok := g.namedSchemas[name]
+		if !ok {
+			names := make([]string, 0, len(g.namedSchemas))
+			for n := range g.namedSchemas {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("no schema named %q is registered (registered: %v)", name, names)
+		}
+		if !added[name] {
+			added[name] = true
+			if err := g.addNamedDefinition(rf, s, name, model); err != nil {
+				return nil, err
+			}
+		}
+		return &jsonschema.Schema{Ref: "#/$defs/" + name}, nil
+	}
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			fieldKey := typeKey(fv.owner) + "." + fv.field.Name
+			prefixes := directiveValues(rf.markers[fieldKey], prefixDirective)
+			values := directiveValues(rf.markers[fieldKey], valuesDirective)
+			if len(prefixes) == 0 && len(values) == 0 {
+				return nil
+			}
+			if ft := derefType(fv.field.Type); ft.Kind() != reflect.Map || ft.Key().Kind() != reflect.String {
+				return fmt.Errorf("%s: %s directives apply to maps with string keys, not %s", fieldKey, schematorDirective, ft)
+			}
+			if len(values) > 1 {
+				return fmt.Errorf("%s: more than one %s directive", fieldKey, valuesDirective)
+			}
+			for _, p := range prefixes {
+				prefix, name, ok := strings.Cut(p, ":")
+				if !ok || prefix == "" || name == "" {
+					return fmt.Errorf("%s: %s=%s is not <prefix>:<name>", fieldKey, prefixDirective, p)
+				}
+				ref, err := use(name)
+				if err != nil {
+					return fmt.Errorf("%s: %w", fieldKey, err)
+				}
+				if fv.schema.PatternProperties == nil {
+					fv.schema.PatternProperties = make(map[string]*jsonschema.Schema)
+				}
+				fv.schema.PatternProperties["^"+regexp.QuoteMeta(prefix)] = ref
+			}
+			for _, name := range values {
+				if name == "false" {
+					fv.schema.AdditionalProperties = jsonschema.FalseSchema
+					continue
+				}
+				ref, err := use(name)
+				if err != nil {
+					return fmt.Errorf("%s: %w", fieldKey, err)
+				}
+				fv.schema.AdditionalProperties = ref
+			}
+			return nil
+		})
+	})
+}
+
+// addNamedDefinition adds the schema of model to the definitions of s as
+// name, together with the definitions it uses.
+func (g *generator) addNamedDefinition(rf *reflection, s *jsonschema.Schema, name string, model any) error {
+	if existing := rf.types[name]; existing != nil && derefType(existing) != derefType(reflect.TypeOf(model)) {
+		return fmt.Errorf("named schema %s collides with the definition of %s", name, existing)
+	}
+	if slices.Contains(g.namedInProgress, name) {
+		// A named schema using itself; the reference resolves once the
+		// outer reflection adds the definition.
+		return nil
+	}
+	g.namedInProgress = append(g.namedInProgress, name)
+	_, ns, err := g.reflectModel(g.ctx, model, nil)
+	g.namedInProgress = g.namedInProgress[:len(g.namedInProgress)-1]
+	if err != nil {
+		return fmt.Errorf("named schema %s: %w", name, err)
+	}
+	// Self-references of a recursive model point at its root, which
+	// becomes the definition.
+	ns = namedRoot(ns, name)
+	if s.Definitions == nil {
+		s.Definitions = make(jsonschema.Definitions)
+	}
+	for defName, def := range ns.Definitions {
+		if _, exists := s.Definitions[defName]; !exists {
+			s.Definitions[defName] = def
+		}
+	}
+	ns.Definitions = nil
+	ns.Version = ""
+	ns.ID = ""
+	s.Definitions[name] = ns
+	return nil
+}
+
+// directiveValues returns the values of every "name=value" marker.
+func directiveValues(markers []string, name string) []string {
+	var values []string
+	for _, m := range markers {
+		if n, v, ok := strings.Cut(m, "="); ok && n == name {
+			values = append(values, strings.TrimSpace(v))
+		}
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// FreeFormExtension is the value of an x- extension.
+type FreeFormExtension struct {
+	Owner string `json:"owner"`
+}
+
+// FreeFormAnnotation is the value of an annotation.
+type FreeFormAnnotation struct {
+	Text string `json:"text"`
+}
+
+// FreeFormResource has free-form maps with named value schemas.
+type FreeFormResource struct {
+	// Annotations are free-form, x- keys hold extensions.
+	// schemator:prefix=x-:Extension
+	// schemator:values=Annotation
+	Annotations map[string]any `json:"annotations"`
+	// Labels only allow app. keys.
+	// schemator:prefix=app.:Annotation
+	// schemator:values=false
+	Labels map[string]any `json:"labels"`
+	Other  map[string]any `json:"other"`
+}
+
+func TestFreeFormDirectives(t *testing.T) {
+	gen := NewGenerator(context.Background(),
+		WithNamedSchema("Extension", FreeFormExtension{}),
+		WithNamedSchema("Annotation", FreeFormAnnotation{}),
+	)
+	out, err := gen.Generate(FreeFormResource{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]map[string]any `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	annotations := doc.Properties["annotations"]
+	if ref := annotations["patternProperties"].(map[string]any)["^x-"].(map[string]any)["$ref"]; ref != "#/$defs/Extension" {
+		t.Fatalf("annotations.patternProperties = %v", annotations["patternProperties"])
+	}
+	if ref := annotations["additionalProperties"].(map[string]any)["$ref"]; ref != "#/$defs/Annotation" {
+		t.Fatalf("annotations.additionalProperties = %v", annotations["additionalProperties"])
+	}
+	labels := doc.Properties["labels"]
+	if _, ok := labels["patternProperties"].(map[string]any)[`^app\.`]; !ok || labels["additionalProperties"] != false {
+		t.Fatalf("labels = %v", labels)
+	}
+	if doc.Properties["other"]["patternProperties"] != nil {
+		t.Fatalf("other = %v", doc.Properties["other"])
+	}
+	if ext := doc.Defs["Extension"]; ext == nil || ext["properties"].(map[string]any)["owner"] == nil {
+		t.Fatalf("$defs.Extension = %v", doc.Defs["Extension"])
+	}
+
+	_, err = New(context.Background(), nil).Generate(FreeFormResource{})
+	if err == nil || !strings.Contains(err.Error(), `no schema named "Extension"`) {
+		t.Fatalf("Generate() without registrations error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package schemator
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// definitionName returns the name of the definition of the named type t.
+// Instantiations of generic types are named after their type arguments, so
+// every instantiation gets a definition of its own with a readable name:
+// Page[example.com/api.User] is PageOfUser and Pair[string,int] is
+// PairOfStringAndInt.
+func definitionName(t reflect.Type) string {
+	if genericBase(t) == "" {
+		return t.Name()
+	}
+	return readableTypeName(t.Name())
+}
+
+// genericBase returns the name of the generic type t instantiates, or "".
+func genericBase(t reflect.Type) string {
+	name, _, ok := strings.Cut(t.Name(), "[")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// readableTypeName turns a type as reflect.Type.String and Name spell it
+// into an identifier: package paths are dropped and type arguments,
+// composite types and builtin types are spelled out.
+func readableTypeName(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return ""
+	case strings.HasPrefix(s, "*"):
+		return readableTypeName(s[1:])
+	case strings.HasPrefix(s, "[]"):
+		return readableTypeName(s[2:]) + "Slice"
+	case strings.HasPrefix(s, "["):
+		if end := strings.IndexByte(s, ']'); end > 0 {
+			return readableTypeName(s[end+1:]) + "Array"
+		}
+	case strings.HasPrefix(s, "map["):
+		if end := matchingBracket(s, len("map")); end > 0 {
+			return "MapOf" + capitalize(readableTypeName(s[len("map["):end])) + "To" + capitalize(readableTypeName(s[end+1:]))
+		}
+	case s == "interface {}" || s == "any":
+		return "Any"
+	}
+	base, args := s, ""
+	if open := strings.IndexByte(s, '['); open > 0 {
+		if end := matchingBracket(s, open); end == len(s)-1 {
+			base, args = s[:open], s[open+1:end]
+		}
+	}
+	// Drop the package path: example.com/api.User is User.
+	if dot := strings.LastIndexByte(base, '.'); dot >= 0 {
+		base = base[dot+1:]
+	}
+	name := base
+	if !token.IsIdentifier(name) {
+		name = identifier(name)
+	}
+	if args == "" {
+		return name
+	}
+	var parts []string
+	for _, arg := range splitTypeArgs(args) {
+		parts = append(parts, capitalize(readableTypeName(arg)))
+	}
+	return name + "Of" + strings.Join(parts, "And")
+}
+
+// matchingBracket returns the index of the ']' closing the '[' at open in s,
+// or -1.
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTypeArgs splits a list of type arguments on the commas outside
+// brackets.
+func splitTypeArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, s[start:])
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// identifier drops the characters identifiers cannot hold from s, starting
+// a new word in upper case after each.
+func identifier(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
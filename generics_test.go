@@ -0,0 +1,115 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// GenericPage is one page of a listing.
+type GenericPage[T any] struct {
+	// Items on this page.
+	Items []T  `json:"items"`
+	Next  *int `json:"next,omitempty"`
+}
+
+// GenericUser is a user.
+type GenericUser struct {
+	Name string `json:"name"`
+}
+
+// GenericOrder is an order.
+type GenericOrder struct {
+	ID int `json:"id"`
+}
+
+type GenericPair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+type GenericListings struct {
+	Users  GenericPage[GenericUser]            `json:"users"`
+	Orders GenericPage[GenericOrder]           `json:"orders"`
+	Counts GenericPair[string, map[string]int] `json:"counts"`
+}
+
+func TestGenericDefinitions(t *testing.T) {
+	out, err := NewGenerator(context.Background()).Generate(GenericListings{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Ref string `json:"$ref"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Description string `json:"description"`
+			Properties  map[string]struct {
+				Description string `json:"description"`
+				Items       struct {
+					Ref string `json:"$ref"`
+				} `json:"items"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for prop, want := range map[string]string{
+		"users":  "#/$defs/GenericPageOfGenericUser",
+		"orders": "#/$defs/GenericPageOfGenericOrder",
+		"counts": "#/$defs/GenericPairOfStringAndMapOfStringToInt",
+	} {
+		if got := doc.Properties[prop].Ref; got != want {
+			t.Errorf("%s: $ref = %q, want %q", prop, got, want)
+		}
+	}
+	users := doc.Defs["GenericPageOfGenericUser"]
+	if got := users.Properties["items"].Items.Ref; got != "#/$defs/GenericUser" {
+		t.Errorf("users items = %q", got)
+	}
+	if got := doc.Defs["GenericPageOfGenericOrder"].Properties["items"].Items.Ref; got != "#/$defs/GenericOrder" {
+		t.Errorf("orders items = %q", got)
+	}
+	if users.Description != "GenericPage is one page of a listing." {
+		t.Errorf("description = %q", users.Description)
+	}
+	if got := users.Properties["items"].Description; got != "Items on this page." {
+		t.Errorf("items description = %q", got)
+	}
+	for name := range doc.Defs {
+		if strings.ContainsAny(name, "[]./") {
+			t.Errorf("definition name %q is not readable", name)
+		}
+	}
+}
+
+func TestGenericToString(t *testing.T) {
+	if got, want := toString(GenericPage[GenericUser]{}), "GenericPageOfGenericUser"; got != want {
+		t.Errorf("toString() = %q, want %q", got, want)
+	}
+	if got, want := toString(&GenericPair[string, []*GenericOrder]{}), "GenericPairOfStringAndGenericOrderSlice"; got != want {
+		t.Errorf("toString() = %q, want %q", got, want)
+	}
+}
+
+func TestReadableTypeName(t *testing.T) {
+	for in, want := range map[string]string{
+		"User":                                  "User",
+		"Page[example.com/api.User]":            "PageOfUser",
+		"Pair[string,int]":                      "PairOfStringAndInt",
+		"Page[[]*example.com/api.User]":         "PageOfUserSlice",
+		"Page[[4]uint8]":                        "PageOfUint8Array",
+		"Page[map[string]example.com/api.User]": "PageOfMapOfStringToUser",
+		"Page[interface {}]":                    "PageOfAny",
+		"Page[example.com/api.Pair[string,example.com/api.Page[int]]]": "PageOfPairOfStringAndPageOfInt",
+		"page[example.com/api.user]":                                   "pageOfUser",
+		"Page[struct { A int }]":                                       "PageOfStructAInt",
+	} {
+		if got := readableTypeName(in); got != want {
+			t.Errorf("readableTypeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package schemator
+
+import (
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Go metadata extensions, as read by Go code generators such as oapi-codegen
+// and go-swagger.
+const (
+	goTypeExtension    = "x-go-type"
+	goPackageExtension = "x-go-package"
+	goNameExtension    = "x-go-name"
+)
+
+// applyGoExtensions is the schema pass recording the Go type of the root and
+// every definition in x-go-type, x-go-package and x-go-name, and the Go field
+// of every struct property in x-go-name.
+func (g *generator) applyGoExtensions(rf *reflection, s *jsonschema.Schema) error {
+	annotate := func(t reflect.Type, ts *jsonschema.Schema) {
+		t = derefType(t)
+		if t == nil || typeID(t) == "" || isBooleanSchema(ts) {
+			return
+		}
+		setExtra(ts, goTypeExtension, importName(t.PkgPath())+"."+t.Name())
+		setExtra(ts, goPackageExtension, t.PkgPath())
+		setExtra(ts, goNameExtension, t.Name())
+	}
+	if s.Ref == "" {
+		annotate(reflect.TypeOf(rf.model), s)
+	}
+	for name, ts := range s.Definitions {
+		if t, ok := rf.types[name]; ok {
+			annotate(t, ts)
+		}
+	}
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if ts.Properties == nil {
+			return nil
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if p, ok := fv.parent.Properties.Get(fv.name); ok && p != nil && !isBooleanSchema(p) {
+				setExtra(p, goNameExtension, fv.field.Name)
+			}
+			return nil
+		})
+	})
+}
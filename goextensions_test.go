@@ -0,0 +1,32 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestGoExtensions(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithGoExtensions()).Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["x-go-type"] != "schemator.NamerCustomer" || doc["x-go-package"] != "pkt.systems/schemator" || doc["x-go-name"] != "NamerCustomer" {
+		t.Errorf("root = %s", out)
+	}
+	props := doc["properties"].(map[string]any)
+	if got := compactJSON(props["address"]); got != `{"$ref":"#/$defs/NamerAddress","x-go-name":"Address"}` {
+		t.Errorf("address = %s", got)
+	}
+	address := doc["$defs"].(map[string]any)["NamerAddress"].(map[string]any)
+	if address["x-go-type"] != "schemator.NamerAddress" {
+		t.Errorf("NamerAddress = %v", address)
+	}
+	if got := address["properties"].(map[string]any)["zip_code"].(map[string]any)["x-go-name"]; got != "Zip" {
+		t.Errorf("zip_code x-go-name = %v", got)
+	}
+}
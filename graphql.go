@@ -0,0 +1,200 @@
+package schemator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+func (g *generator) GenerateGraphQL(model any) (SchemaBytes, error) {
+	name := toString(model)
+	if name == "" {
+		return nil, fmt.Errorf("unable to derive a GraphQL type name from %T", model)
+	}
+	wide := make(map[*jsonschema.Schema]bool)
+	_, s, err := g.reflectModel(g.ctx, model, nil, graphqlWideIntegers(wide))
+	if err != nil {
+		return nil, err
+	}
+	s = namedRoot(s, name)
+	w := &graphqlWriter{defs: s.Definitions, wide: wide}
+	w.object(name, s)
+	names := make([]string, 0, len(s.Definitions))
+	for n := range s.Definitions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if def := s.Definitions[n]; isGraphQLObject(def) {
+			w.object(n, def)
+		}
+	}
+	var b strings.Builder
+	for _, scalar := range w.sortedScalars() {
+		fmt.Fprintf(&b, "scalar %s\n\n", scalar)
+	}
+	for i, obj := range w.objects {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(obj)
+	}
+	return []byte(b.String()), nil
+}
+
+// graphqlWriter renders object definitions from reflected schemas.
+type graphqlWriter struct {
+	defs    jsonschema.Definitions
+	objects []string
+	scalars map[string]struct{}
+	// wide holds the integer schemas rendered as the Int64 scalar.
+	wide map[*jsonschema.Schema]bool
+}
+
+// graphqlWideIntegers returns the schema pass adding the integer schemas
+// whose range does not fit the 32-bit GraphQL Int to wide: int, int64, uint,
+// uint32 and uint64 fields, unless markers or tags bound them tighter.
+func graphqlWideIntegers(wide map[*jsonschema.Schema]bool) schemaPass {
+	return func(rf *reflection, s *jsonschema.Schema) error {
+		return rf.forEachInteger(s, func(kind reflect.Kind, is *jsonschema.Schema) {
+			minimum, maximum := integerRange(kind)
+			lower := tighterBound(is.Minimum, minimum, false)
+			upper := tighterBound(is.Maximum, maximum, true)
+			if tighterBound(lower, fmt.Sprint(math.MinInt32), false) != lower ||
+				tighterBound(upper, fmt.Sprint(math.MaxInt32), true) != upper {
+				wide[is] = true
+			}
+		})
+	}
+}
+
+func (w *graphqlWriter) object(name string, s *jsonschema.Schema) {
+	var b strings.Builder
+	writeGraphQLDescription(&b, "", s.Description)
+	fmt.Fprintf(&b, "type %s {\n", graphqlName(name))
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	if !isGraphQLObject(s) {
+		// An object type needs a field; models without exported fields get
+		// a placeholder.
+		b.WriteString("  _: Boolean\n")
+	} else {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			writeGraphQLDescription(&b, "  ", pair.Value.Description)
+			typ := w.typeOf(pair.Value)
+			if required[pair.Key] {
+				typ += "!"
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", graphqlName(pair.Key), typ)
+		}
+	}
+	b.WriteString("}\n")
+	w.objects = append(w.objects, b.String())
+}
+
+func (w *graphqlWriter) typeOf(s *jsonschema.Schema) string {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		if def, ok := w.defs[name]; ok && !isGraphQLObject(def) {
+			return w.typeOf(def)
+		}
+		return graphqlName(name)
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		// Nullable wrappers are {oneOf: [T, null]}; anything else has no
+		// GraphQL equivalent.
+		alts := append(append([]*jsonschema.Schema{}, s.OneOf...), s.AnyOf...)
+		var nonNull []*jsonschema.Schema
+		for _, alt := range alts {
+			if alt.Type != "null" {
+				nonNull = append(nonNull, alt)
+			}
+		}
+		if len(nonNull) == 1 {
+			return w.typeOf(nonNull[0])
+		}
+		return w.scalar("JSON")
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return w.scalar("DateTime")
+		}
+		return "String"
+	case "integer":
+		if w.wide[s] {
+			return w.scalar("Int64")
+		}
+		return "Int"
+	case "number":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	case "array":
+		if s.Items == nil {
+			return "[" + w.scalar("JSON") + "]"
+		}
+		return "[" + w.typeOf(s.Items) + "]"
+	}
+	return w.scalar("JSON")
+}
+
+func (w *graphqlWriter) scalar(name string) string {
+	if w.scalars == nil {
+		w.scalars = make(map[string]struct{})
+	}
+	w.scalars[name] = struct{}{}
+	return name
+}
+
+func (w *graphqlWriter) sortedScalars() []string {
+	out := make([]string, 0, len(w.scalars))
+	for s := range w.scalars {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// isGraphQLObject reports whether s maps to a GraphQL object type. Free-form
+// objects and maps have no properties and are rendered as the JSON scalar.
+func isGraphQLObject(s *jsonschema.Schema) bool {
+	return s != nil && s.Type == "object" && s.Properties != nil && s.Properties.Len() > 0
+}
+
+func writeGraphQLDescription(b *strings.Builder, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	desc = strings.ReplaceAll(desc, `"""`, `\"""`)
+	fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, desc)
+}
+
+// graphqlName maps s onto the GraphQL name grammar /[_A-Za-z][_0-9A-Za-z]*/.
+func graphqlName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
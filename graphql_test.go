@@ -0,0 +1,108 @@
+package schemator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestGenerateGraphQL(t *testing.T) {
+	out, err := New(context.Background(), nil).GenerateGraphQL(example.Subject{})
+	if err != nil {
+		t.Fatalf("GenerateGraphQL() error = %v", err)
+	}
+	sdl := out.String()
+	for _, want := range []string{
+		"scalar DateTime",
+		"scalar Int64",
+		"type Subject {",
+		`  """ID is the ID of the subject."""`,
+		"  id: Int64!",
+		"  tags: [String]!",
+		"  dateOfBirth: DateTime!",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Fatalf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+}
+
+type GraphQLCounters struct {
+	Small    int8         `json:"small"`
+	Medium   int32        `json:"medium"`
+	Bounded  int          `json:"bounded" jsonschema:"minimum=0,maximum=1000"`
+	Large    int64        `json:"large"`
+	Unsigned uint32       `json:"unsigned"`
+	Huge     *uint64      `json:"huge,omitempty"`
+	Sizes    []int64      `json:"sizes"`
+	Empty    GraphQLEmpty `json:"empty"`
+}
+
+type GraphQLEmpty struct {
+	hidden string
+}
+
+func TestGenerateGraphQLIntegers(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithNullablePointers()).GenerateGraphQL(GraphQLCounters{})
+	if err != nil {
+		t.Fatalf("GenerateGraphQL() error = %v", err)
+	}
+	sdl := out.String()
+	for _, want := range []string{
+		"scalar Int64",
+		"  small: Int!",
+		"  medium: Int!",
+		"  bounded: Int!",
+		"  large: Int64!",
+		"  unsigned: Int64!",
+		"  huge: Int64\n",
+		"  sizes: [Int64]!",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL missing %q:\n%s", want, sdl)
+		}
+	}
+
+	// Int64String describes 64-bit integers as strings already.
+	out, err = NewGenerator(context.Background(), WithInt64Format(Int64String)).GenerateGraphQL(GraphQLCounters{})
+	if err != nil {
+		t.Fatalf("GenerateGraphQL() error = %v", err)
+	}
+	if sdl := out.String(); !strings.Contains(sdl, "  large: String!") {
+		t.Errorf("SDL with Int64String:\n%s", sdl)
+	}
+}
+
+func TestGenerateGraphQLEmptyObject(t *testing.T) {
+	out, err := New(context.Background(), nil).GenerateGraphQL(GraphQLEmpty{})
+	if err != nil {
+		t.Fatalf("GenerateGraphQL() error = %v", err)
+	}
+	if want := "type GraphQLEmpty {\n  _: Boolean\n}\n"; out.String() != want {
+		t.Errorf("GenerateGraphQL() = %q, want %q", out, want)
+	}
+
+	// Referenced structs without exported fields are free-form.
+	out, err = New(context.Background(), nil).GenerateGraphQL(GraphQLCounters{})
+	if err != nil {
+		t.Fatalf("GenerateGraphQL() error = %v", err)
+	}
+	if sdl := out.String(); !strings.Contains(sdl, "  empty: JSON!") || strings.Contains(sdl, "{\n}") {
+		t.Errorf("SDL with an empty struct field:\n%s", sdl)
+	}
+}
+
+func TestGraphQLName(t *testing.T) {
+	for in, want := range map[string]string{
+		"objectMeta": "objectMeta",
+		"x-go-type":  "x_go_type",
+		"2fa":        "_2fa",
+		"":           "_",
+	} {
+		if got := graphqlName(in); got != want {
+			t.Fatalf("graphqlName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
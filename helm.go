@@ -0,0 +1,104 @@
+package schemator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode"
+
+	"github.com/invopop/jsonschema"
+	"pkt.systems/logport"
+)
+
+// HelmValuesSchemaFilename is the file Helm validates chart values against.
+const HelmValuesSchemaFilename = "values.schema.json"
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+func (g *generator) GenerateHelmValuesSchema(model any) (SchemaBytes, error) {
+	s, err := g.reflectWith(model, func(r *jsonschema.Reflector) {
+		r.KeyNamer = lowerCamel
+		r.Anonymous = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	inlined, err := inlineRefs(s, s.Definitions)
+	if err != nil {
+		return nil, err
+	}
+	if err := walkSchema(inlined, downgradeToDraft07); err != nil {
+		return nil, err
+	}
+	inlined.Version = draft07
+	out, err := g.marshalJSON(inlined)
+	if err != nil {
+		return nil, err
+	}
+	// Keywords without a draft-07 equivalent stay in the document but are
+	// ignored by Helm's validator.
+	if report, err := AnalyzeDraft(out); err == nil {
+		l := logport.LoggerFromContext(g.ctx)
+		for _, f := range report.Lost(Draft07) {
+			l.Warn("Values schema keyword is not enforced by draft-07 validators", "model", model, "pointer", f.Path, "keyword", f.Keyword, "draft", f.Draft.String())
+		}
+	}
+	return out, nil
+}
+
+func (g *generator) WriteHelmValuesSchema(model any, chartDir string) error {
+	if _, err := os.Stat(filepath.Join(chartDir, "Chart.yaml")); err != nil {
+		return fmt.Errorf("%s is not a Helm chart: %w", chartDir, err)
+	}
+	out, err := g.GenerateHelmValuesSchema(model)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filepath.Join(chartDir, HelmValuesSchemaFilename), out, "model", model)
+}
+
+// downgradeToDraft07 rewrites the 2020-12 keywords the reflector emits into
+// their draft-07 equivalents.
+func downgradeToDraft07(s *jsonschema.Schema) error {
+	s.Version = ""
+	s.ID = ""
+	s.Anchor = ""
+	s.DynamicRef = ""
+	if len(s.PrefixItems) > 0 {
+		return fmt.Errorf("prefixItems cannot be expressed in draft-07 without losing the additional items schema")
+	}
+	if len(s.DependentRequired) > 0 {
+		deps := make(map[string]any, len(s.DependentRequired))
+		for k, v := range s.DependentRequired {
+			deps[k] = v
+		}
+		setExtra(s, "dependencies", deps)
+		s.DependentRequired = nil
+	}
+	if len(s.DependentSchemas) > 0 {
+		return fmt.Errorf("dependentSchemas are not supported in draft-07 output")
+	}
+	return nil
+}
+
+// lowerCamel converts a Go identifier to lowerCamelCase, treating leading
+// initialisms as one word: ID → id, APIVersion → apiVersion, URLs → urls.
+func lowerCamel(s string) string {
+	runes := []rune(s)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper == 0 {
+		return s
+	}
+	rest := string(runes[upper:])
+	if upper > 1 && rest != "s" && len(rest) > 0 && unicode.IsLower(runes[upper]) {
+		// The last capital starts the next word: APIVersion.
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
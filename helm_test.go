@@ -0,0 +1,78 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// HelmValues configures the example chart.
+type HelmValues struct {
+	// ReplicaCount is the number of pods.
+	ReplicaCount int
+	Image        HelmImage
+	APIVersion   string
+	Resources    map[string]string `json:"resources,omitempty"`
+}
+
+// HelmImage selects the container image.
+type HelmImage struct {
+	Repository string
+	Tag        string
+}
+
+func TestWriteHelmValuesSchema(t *testing.T) {
+	chart := t.TempDir()
+	gen := New(context.Background(), nil)
+	if err := gen.WriteHelmValuesSchema(HelmValues{}, chart); err == nil {
+		t.Fatalf("WriteHelmValuesSchema() without Chart.yaml error = nil")
+	}
+	writeFile(t, filepath.Join(chart, "Chart.yaml"), "apiVersion: v2\nname: demo\nversion: 0.1.0\n")
+	if err := gen.WriteHelmValuesSchema(HelmValues{}, chart); err != nil {
+		t.Fatalf("WriteHelmValuesSchema() error = %v", err)
+	}
+	contents, err := os.ReadFile(filepath.Join(chart, HelmValuesSchemaFilename))
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if strings.Contains(string(contents), "$ref") || strings.Contains(string(contents), "$defs") {
+		t.Fatalf("values schema must not contain references:\n%s", contents)
+	}
+	var doc struct {
+		Schema     string                     `json:"$schema"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(contents, &doc); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if doc.Schema != "http://json-schema.org/draft-07/schema#" {
+		t.Fatalf("$schema = %q", doc.Schema)
+	}
+	for _, key := range []string{"replicaCount", "image", "apiVersion", "resources"} {
+		if _, ok := doc.Properties[key]; !ok {
+			t.Fatalf("property %q missing in %s", key, contents)
+		}
+	}
+	if !strings.Contains(string(doc.Properties["image"]), `"repository"`) {
+		t.Fatalf("nested properties not lowerCamel: %s", doc.Properties["image"])
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	for in, want := range map[string]string{
+		"ReplicaCount": "replicaCount",
+		"ID":           "id",
+		"APIVersion":   "apiVersion",
+		"URLs":         "urls",
+		"alreadyLower": "alreadyLower",
+		"X":            "x",
+		"HTTP2Enabled": "http2Enabled",
+	} {
+		if got := lowerCamel(in); got != want {
+			t.Fatalf("lowerCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package schemator
+
+import (
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+)
+
+// WithSchemaHook runs fn on every reflected schema after schemator's own
+// passes and before it is rendered, to change titles, add keywords or remove
+// properties in code rather than in the output JSON. model is the value
+// being reflected; besides the models passed to Generate and the other
+// methods, schemator reflects registered named schemas and union members on
+// their own before adding them as definitions. Hooks run in the order they
+// were added and an error fails generation.
+func WithSchemaHook(fn func(model any, s *jsonschema.Schema) error) Option {
+	return func(g *generator) {
+		if fn != nil {
+			g.schemaHooks = append(g.schemaHooks, fn)
+		}
+	}
+}
+
+// applySchemaHooks is the schema pass running the WithSchemaHook functions.
+func (g *generator) applySchemaHooks(rf *reflection, s *jsonschema.Schema) error {
+	for _, hook := range g.schemaHooks {
+		if err := hook(rf.model, s); err != nil {
+			return fmt.Errorf("%s: schema hook: %w", typeName(rf.model), err)
+		}
+	}
+	return nil
+}
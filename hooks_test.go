@@ -0,0 +1,52 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+func TestSchemaHook(t *testing.T) {
+	var models []any
+	gen := NewGenerator(context.Background(),
+		WithSchemaHook(func(model any, s *jsonschema.Schema) error {
+			models = append(models, model)
+			s.Title = "Customer"
+			s.Properties.Delete("FullName")
+			s.Required = removeString(s.Required, "FullName")
+			return nil
+		}),
+		WithSchemaHook(func(model any, s *jsonschema.Schema) error {
+			setExtra(s, "x-title-seen", s.Title)
+			return nil
+		}),
+	)
+	out, err := gen.Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["title"] != "Customer" || doc["x-title-seen"] != "Customer" {
+		t.Errorf("title = %v, x-title-seen = %v", doc["title"], doc["x-title-seen"])
+	}
+	if _, ok := doc["properties"].(map[string]any)["FullName"]; ok {
+		t.Error("FullName not removed")
+	}
+	if len(models) != 1 {
+		t.Errorf("hook ran for %v", models)
+	}
+
+	_, err = NewGenerator(context.Background(), WithSchemaHook(func(any, *jsonschema.Schema) error {
+		return errors.New("rejected")
+	})).Generate(NamerCustomer{})
+	if err == nil || !strings.Contains(err.Error(), "NamerCustomer: schema hook: rejected") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexFilename is the table of contents WriteIndex writes into a schema
+// directory.
+const IndexFilename = "README.md"
+
+// WriteIndex writes a README.md into dir summarizing every *.schema.json
+// file in it (type, version, title, description and $id, linked to the
+// file), followed by the other artifacts recorded in the manifest, so a
+// committed schema directory explains itself when browsed. An existing
+// README.md keeps its permissions.
+func WriteIndex(dir string) error {
+	return writeIndex(dir, defaultPermissions.keepFileMode(filepath.Join(dir, IndexFilename)).write)
+}
+
+// writeIndex is WriteIndex writing the README.md with write.
+func writeIndex(dir string, write func(name string, data []byte) error) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+JSONSchemaFormat.Extension))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	recorded := make(map[string]Artifact, len(m.Artifacts))
+	for _, a := range m.Artifacts {
+		recorded[a.File] = a
+	}
+
+	var b strings.Builder
+	b.WriteString("# Schemas\n\n")
+	b.WriteString("Generated by [schemator](https://pkt.systems/schemator); regenerate instead of editing.\n\n")
+	if len(files) == 0 {
+		b.WriteString("No JSON schemas yet.\n")
+	} else {
+		b.WriteString("| Schema | Type | Version | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		summary, err := readSchemaSummary(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		typ, version, _ := strings.Cut(strings.TrimSuffix(name, JSONSchemaFormat.Extension), ".")
+		if a, ok := recorded[name]; ok {
+			typ, version = a.Type, a.Version
+		}
+		description := summary.Description
+		if summary.Title != "" {
+			description = "**" + summary.Title + "** " + description
+		}
+		if summary.ID != "" {
+			description = strings.TrimSpace(description + " (`" + summary.ID + "`)")
+		}
+		fmt.Fprintf(&b, "| [%s](%s) | %s | %s | %s |\n", name, name, markdownCell(typ), markdownCell(version), markdownCell(description))
+	}
+
+	var others []Artifact
+	for _, a := range m.Artifacts {
+		if a.Format != "" && a.Format != JSONSchemaFormat.Name {
+			others = append(others, a)
+		}
+	}
+	if len(others) > 0 {
+		b.WriteString("\n## Other formats\n\n")
+		b.WriteString("| File | Type | Format | Version |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, a := range others {
+			fmt.Fprintf(&b, "| [%s](%s) | %s | %s | %s |\n", a.File, a.File, markdownCell(a.Type), markdownCell(a.Format), markdownCell(a.Version))
+		}
+	}
+	return write(filepath.Join(dir, IndexFilename), []byte(b.String()))
+}
+
+// schemaSummary is what WriteIndex shows of a schema.
+type schemaSummary struct {
+	ID          string `json:"$id"`
+	Ref         string `json:"$ref"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Defs        map[string]struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"$defs"`
+}
+
+// readSchemaSummary reads the title and description of the schema in file,
+// following a root $ref to its definition.
+func readSchemaSummary(file string) (schemaSummary, error) {
+	var s schemaSummary
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(contents, &s); err != nil {
+		return s, err
+	}
+	if def, ok := s.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]; ok && s.Ref != "" {
+		if s.Title == "" {
+			s.Title = def.Title
+		}
+		if s.Description == "" {
+			s.Description = def.Description
+		}
+	}
+	return s, nil
+}
+
+// markdownCell makes s safe for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
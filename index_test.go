@@ -0,0 +1,47 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestWriteIndex(t *testing.T) {
+	outDir := t.TempDir()
+	gen := NewGenerator(context.Background(), WithVersion("v1"), WithIndex())
+	err := gen.WriteAll(outDir,
+		Output{Model: example.Subject{}, Formats: []Format{JSONSchemaFormat, XSDFormat}},
+		Output{Model: DeprecatingOrder{}},
+	)
+	if err != nil {
+		t.Fatalf("WriteAll() error = %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(outDir, IndexFilename))
+	if err != nil {
+		t.Fatalf("index missing: %v", err)
+	}
+	index := string(b)
+	for _, want := range []string{
+		"| [DeprecatingOrder.v1.schema.json](DeprecatingOrder.v1.schema.json) | DeprecatingOrder | v1 | DeprecatingOrder has deprecated fields.",
+		"| [Subject.v1.schema.json](Subject.v1.schema.json) | Subject | v1 | (`https://pkt.systems/schemator/example/subject`) |",
+		"## Other formats",
+		"| [Subject.v1.xsd](Subject.v1.xsd) | Subject | xsd | v1 |",
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("index missing %q:\n%s", want, index)
+		}
+	}
+	if strings.Index(index, "DeprecatingOrder.v1") > strings.Index(index, "Subject.v1") {
+		t.Fatalf("index not sorted:\n%s", index)
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	if got := markdownCell("a | b\n\nc"); got != `a \| b c` {
+		t.Fatalf("markdownCell() = %q", got)
+	}
+}
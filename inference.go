@@ -0,0 +1,187 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Suggestion is a format or constraint inferred from the name and type of a
+// field, reported by SuggestConstraints and applied by WithInferredFormats.
+type Suggestion struct {
+	// Type is the struct declaring the field as "<import path>.<Type>".
+	Type  string `json:"type"`
+	Field string `json:"field"`
+	// Keyword is the JSON Schema keyword, e.g. "format" or "minLength".
+	Keyword string `json:"keyword"`
+	Value   any    `json:"value"`
+	Reason  string `json:"reason"`
+}
+
+func (s Suggestion) String() string {
+	return fmt.Sprintf("%s.%s: %s %s (%s)", s.Type, s.Field, s.Keyword, compactJSON(s.Value), s.Reason)
+}
+
+// inferenceRule suggests keyword: value for fields matching name and kind.
+type inferenceRule struct {
+	match   func(name string, kind reflect.Kind) bool
+	keyword string
+	value   any
+	reason  string
+}
+
+var inferenceRules = []inferenceRule{
+	{
+		match:   func(n string, k reflect.Kind) bool { return k == reflect.String && hasWordSuffix(n, "Email") },
+		keyword: "format", value: "email", reason: "field name suggests an email address",
+	},
+	{
+		match: func(n string, k reflect.Kind) bool {
+			return k == reflect.String && (hasWordSuffix(n, "URL") || hasWordSuffix(n, "Url") || hasWordSuffix(n, "URI") || hasWordSuffix(n, "Uri"))
+		},
+		keyword: "format", value: "uri", reason: "field name suggests a URL",
+	},
+	{
+		match: func(n string, k reflect.Kind) bool {
+			return k == reflect.String && (hasWordSuffix(n, "UUID") || hasWordSuffix(n, "Uuid"))
+		},
+		keyword: "format", value: "uuid", reason: "field name suggests a UUID",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return k == reflect.String && hasWordSuffix(n, "At") },
+		keyword: "format", value: "date-time", reason: "field name suggests a timestamp",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return k == reflect.String && hasWordSuffix(n, "Date") },
+		keyword: "format", value: "date", reason: "field name suggests a date",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return k == reflect.String && hasWordSuffix(n, "Hostname") },
+		keyword: "format", value: "hostname", reason: "field name suggests a hostname",
+	},
+	{
+		match: func(n string, k reflect.Kind) bool {
+			return k == reflect.String && (hasWordSuffix(n, "ID") || hasWordSuffix(n, "Id"))
+		},
+		keyword: "minLength", value: uint64(1), reason: "identifiers should not be empty",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return isIntegerKind(k) && hasWordSuffix(n, "Port") },
+		keyword: "minimum", value: json.Number("0"), reason: "field name suggests a network port",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return isIntegerKind(k) && hasWordSuffix(n, "Port") },
+		keyword: "maximum", value: json.Number("65535"), reason: "field name suggests a network port",
+	},
+	{
+		match:   func(n string, k reflect.Kind) bool { return isIntegerKind(k) && hasWordSuffix(n, "Count") },
+		keyword: "minimum", value: json.Number("0"), reason: "counts cannot be negative",
+	},
+}
+
+// hasWordSuffix reports whether the Go identifier name is suffix or ends
+// with suffix as its last camel-case word (CreatedAt, but not Format).
+func hasWordSuffix(name, suffix string) bool {
+	if name == suffix {
+		return true
+	}
+	rest, ok := strings.CutSuffix(name, suffix)
+	if !ok || rest == "" {
+		return false
+	}
+	// The suffix starts a new word unless it continues an initialism
+	// (e.g. "GUID" does not end with the word "ID").
+	last := rest[len(rest)-1]
+	if suffix[0] >= 'A' && suffix[0] <= 'Z' && len(suffix) > 1 && suffix[1] >= 'A' && suffix[1] <= 'Z' {
+		return !(last >= 'A' && last <= 'Z')
+	}
+	return true
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	return isNumericKind(k) && k != reflect.Float32 && k != reflect.Float64
+}
+
+func (g *generator) SuggestConstraints(model any) ([]Suggestion, error) {
+	rf, s, err := g.reflectModel(g.ctx, model, nil)
+	if err != nil {
+		return nil, err
+	}
+	var suggestions []Suggestion
+	err = rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			suggestions = append(suggestions, suggestConstraints(fv)...)
+			return nil
+		})
+	})
+	return suggestions, err
+}
+
+// applyInferredFormats is the schema pass installed by WithInferredFormats.
+func applyInferredFormats(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			for _, sg := range suggestConstraints(fv) {
+				setKeyword(fv.schema, sg.Keyword, sg.Value)
+			}
+			return nil
+		})
+	})
+}
+
+// suggestConstraints returns the rules matching fv whose keyword is not set
+// on its schema yet.
+func suggestConstraints(fv fieldVisit) []Suggestion {
+	s := fv.schema
+	if s.Ref != "" {
+		return nil
+	}
+	kind := derefType(fv.field.Type).Kind()
+	var out []Suggestion
+	suggested := make(map[string]bool)
+	for _, r := range inferenceRules {
+		if suggested[r.keyword] || keywordSet(s, r.keyword) || !r.match(fv.field.Name, kind) {
+			continue
+		}
+		suggested[r.keyword] = true
+		out = append(out, Suggestion{
+			Type:    typeKey(fv.owner),
+			Field:   fv.field.Name,
+			Keyword: r.keyword,
+			Value:   r.value,
+			Reason:  r.reason,
+		})
+	}
+	return out
+}
+
+func keywordSet(s *jsonschema.Schema, keyword string) bool {
+	switch keyword {
+	case "format":
+		return s.Format != "" || len(s.Enum) > 0 || s.Const != nil
+	case "minLength":
+		return s.MinLength != nil || len(s.Enum) > 0 || s.Const != nil
+	case "minimum":
+		return s.Minimum != "" || s.ExclusiveMinimum != ""
+	case "maximum":
+		return s.Maximum != "" || s.ExclusiveMaximum != ""
+	}
+	return false
+}
+
+func setKeyword(s *jsonschema.Schema, keyword string, value any) {
+	switch keyword {
+	case "format":
+		s.Format = value.(string)
+	case "minLength":
+		n := value.(uint64)
+		s.MinLength = &n
+	case "minimum":
+		s.Minimum = value.(json.Number)
+	case "maximum":
+		s.Maximum = value.(json.Number)
+	}
+}
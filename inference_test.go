@@ -0,0 +1,73 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// InferredAccount has fields whose names suggest formats.
+type InferredAccount struct {
+	UserID       string `json:"userId"`
+	ContactEmail string `json:"contactEmail"`
+	CreatedAt    string `json:"createdAt"`
+	Homepage     string `json:"homepage"`
+	AvatarURL    string `json:"avatarUrl" jsonschema:"format=iri"`
+	AdminPort    int    `json:"adminPort"`
+	Format       string `json:"format"`
+	GUID         string `json:"guid"`
+}
+
+func TestSuggestConstraints(t *testing.T) {
+	suggestions, err := New(context.Background(), nil).SuggestConstraints(InferredAccount{})
+	if err != nil {
+		t.Fatalf("SuggestConstraints() error = %v", err)
+	}
+	got := make(map[string]any)
+	for _, s := range suggestions {
+		got[s.Field+"."+s.Keyword] = s.Value
+	}
+	want := map[string]any{
+		"UserID.minLength":    uint64(1),
+		"ContactEmail.format": "email",
+		"CreatedAt.format":    "date-time",
+		"AdminPort.minimum":   json.Number("0"),
+		"AdminPort.maximum":   json.Number("65535"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("suggestions = %v, want %v", suggestions, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("suggestion %s = %v, want %v (all: %v)", k, got[k], v, suggestions)
+		}
+	}
+}
+
+func TestWithInferredFormats(t *testing.T) {
+	ctx := context.Background()
+	plain, err := New(ctx, nil).Generate(InferredAccount{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	inferred, err := NewGenerator(ctx, WithInferredFormats()).Generate(InferredAccount{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	props := func(b []byte) map[string]map[string]any {
+		var doc struct {
+			Properties map[string]map[string]any `json:"properties"`
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc.Properties
+	}
+	if f := props(plain)["contactEmail"]["format"]; f != nil {
+		t.Fatalf("format inferred without WithInferredFormats: %v", f)
+	}
+	p := props(inferred)
+	if p["contactEmail"]["format"] != "email" || p["userId"]["minLength"] != 1.0 || p["avatarUrl"]["format"] != "iri" {
+		t.Fatalf("inferred properties = %v", p)
+	}
+}
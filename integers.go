@@ -0,0 +1,182 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Int64Format selects how int64 and uint64 fields are described.
+// JavaScript numbers are doubles, so clients written in JavaScript or
+// TypeScript silently lose precision beyond Number.MAX_SAFE_INTEGER (2^53-1).
+type Int64Format int
+
+const (
+	// Int64Number describes 64-bit integers as integers (default).
+	Int64Number Int64Format = iota
+	// Int64String describes 64-bit integers as strings of decimal digits, as
+	// written by fields tagged `json:",string"` or by protojson. Enum, const,
+	// default and example values become strings as well.
+	Int64String
+	// Int64SafeRange describes 64-bit integers as integers between
+	// -(2^53-1) and 2^53-1, the range JavaScript represents exactly. Tighter
+	// bounds from markers or tags are kept.
+	Int64SafeRange
+)
+
+func (f Int64Format) String() string {
+	switch f {
+	case Int64Number:
+		return "number"
+	case Int64String:
+		return "string"
+	case Int64SafeRange:
+		return "safe-range"
+	}
+	return fmt.Sprintf("Int64Format(%d)", int(f))
+}
+
+// maxSafeInteger is Number.MAX_SAFE_INTEGER, 2^53-1.
+const maxSafeInteger = "9007199254740991"
+
+// applyInt64Format is the schema pass describing int64 and uint64 fields as
+// g.int64Format says.
+func (g *generator) applyInt64Format(rf *reflection, s *jsonschema.Schema) error {
+	apply := func(kind reflect.Kind, is *jsonschema.Schema) {
+		if kind != reflect.Int64 && kind != reflect.Uint64 {
+			return
+		}
+		switch g.int64Format {
+		case Int64String:
+			int64String(kind, is)
+		case Int64SafeRange:
+			if kind == reflect.Int64 {
+				is.Minimum = tighterBound(is.Minimum, "-"+maxSafeInteger, false)
+			}
+			is.Maximum = tighterBound(is.Maximum, maxSafeInteger, true)
+		}
+	}
+	return rf.forEachInteger(s, apply)
+}
+
+// forEachInteger calls fn with the kind and schema of every integer type
+// and field of s.
+func (rf *reflection) forEachInteger(s *jsonschema.Schema, fn func(reflect.Kind, *jsonschema.Schema)) error {
+	return rf.forEachType(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if t.Kind() != reflect.Struct {
+			forEachIntegerSchema(t, ts, fn)
+			return nil
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			forEachIntegerSchema(fv.field.Type, fv.schema, fn)
+			return nil
+		})
+	})
+}
+
+// forEachIntegerSchema calls fn with the kind and schema of t, or of the
+// element types of t, for every integer schema s was reflected into.
+// Schemas that are references or were mapped to another type are skipped,
+// and nullable unions are looked through.
+func forEachIntegerSchema(t reflect.Type, s *jsonschema.Schema, fn func(reflect.Kind, *jsonschema.Schema)) {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) {
+		return
+	}
+	if s = unwrapNullable(s); s == nil || s.Ref != "" {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s.Type == "integer" {
+			fn(t.Kind(), s)
+		}
+	case reflect.Slice, reflect.Array:
+		forEachIntegerSchema(t.Elem(), s.Items, fn)
+	case reflect.Map:
+		forEachIntegerSchema(t.Elem(), s.AdditionalProperties, fn)
+	}
+}
+
+// int64String turns the integer schema s into a string schema of decimal
+// digits.
+func int64String(kind reflect.Kind, s *jsonschema.Schema) {
+	s.Type = "string"
+	s.Pattern = "^-?[0-9]+$"
+	if kind == reflect.Uint64 {
+		s.Pattern = "^[0-9]+$"
+	}
+	for i, v := range s.Enum {
+		s.Enum[i] = integerString(v)
+	}
+	for i, v := range s.Examples {
+		s.Examples[i] = integerString(v)
+	}
+	s.Const = integerString(s.Const)
+	s.Default = integerString(s.Default)
+}
+
+// integerString returns the decimal string of a numeric value and any other
+// value as is.
+func integerString(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		return v.String()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float64:
+		return big.NewFloat(v).Text('f', -1)
+	}
+	return v
+}
+
+// tighterBound returns bound, or limit if bound is unset or looser than it.
+// upper tells a maximum from a minimum.
+func tighterBound(bound json.Number, limit string, upper bool) json.Number {
+	l, _ := new(big.Rat).SetString(limit)
+	if b, ok := new(big.Rat).SetString(bound.String()); ok {
+		if c := b.Cmp(l); (upper && c <= 0) || (!upper && c >= 0) {
+			return bound
+		}
+	}
+	return json.Number(limit)
+}
+
+// integerRange returns the smallest and largest values of an integer kind.
+// int and uint are taken to be 64 bits wide, so schemas do not depend on the
+// platform generating them.
+func integerRange(kind reflect.Kind) (minimum, maximum string) {
+	switch kind {
+	case reflect.Int8:
+		return fmt.Sprint(math.MinInt8), fmt.Sprint(math.MaxInt8)
+	case reflect.Int16:
+		return fmt.Sprint(math.MinInt16), fmt.Sprint(math.MaxInt16)
+	case reflect.Int32:
+		return fmt.Sprint(math.MinInt32), fmt.Sprint(math.MaxInt32)
+	case reflect.Int, reflect.Int64:
+		return fmt.Sprint(math.MinInt64), fmt.Sprint(math.MaxInt64)
+	case reflect.Uint8:
+		return "0", fmt.Sprint(math.MaxUint8)
+	case reflect.Uint16:
+		return "0", fmt.Sprint(math.MaxUint16)
+	case reflect.Uint32:
+		return "0", fmt.Sprint(math.MaxUint32)
+	}
+	return "0", fmt.Sprint(uint64(math.MaxUint64))
+}
+
+// applyIntegerBounds is the schema pass bounding integer fields by the range
+// of their Go type. Tighter bounds from markers or tags are kept.
+func applyIntegerBounds(rf *reflection, s *jsonschema.Schema) error {
+	apply := func(kind reflect.Kind, is *jsonschema.Schema) {
+		minimum, maximum := integerRange(kind)
+		is.Minimum = tighterBound(is.Minimum, minimum, false)
+		is.Maximum = tighterBound(is.Maximum, maximum, true)
+	}
+	return rf.forEachInteger(s, apply)
+}
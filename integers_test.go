@@ -0,0 +1,93 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// IntegerAccount has 64-bit integer fields.
+type IntegerAccount struct {
+	ID      int64            `json:"id" default:"7"`
+	Balance uint64           `json:"balance" validate:"max=1000"`
+	Ledger  []int64          `json:"ledger"`
+	Limits  map[string]int64 `json:"limits"`
+	Count   int32            `json:"count"`
+}
+
+func TestInt64Format(t *testing.T) {
+	for format, want := range map[Int64Format]map[string]string{
+		Int64Number: {
+			"id":      `{"type":"integer","default":7}`,
+			"balance": `{"type":"integer","maximum":1000}`,
+			"ledger":  `{"items":{"type":"integer"},"type":"array"}`,
+		},
+		Int64String: {
+			"id":      `{"type":"string","pattern":"^-?[0-9]+$","default":"7"}`,
+			"balance": `{"type":"string","maximum":1000,"pattern":"^[0-9]+$"}`,
+			"ledger":  `{"items":{"type":"string","pattern":"^-?[0-9]+$"},"type":"array"}`,
+			"limits":  `{"additionalProperties":{"type":"string","pattern":"^-?[0-9]+$"},"type":"object"}`,
+			"count":   `{"type":"integer"}`,
+		},
+		Int64SafeRange: {
+			"id":      `{"type":"integer","maximum":9007199254740991,"minimum":-9007199254740991,"default":7}`,
+			"balance": `{"type":"integer","maximum":1000}`,
+			"ledger":  `{"items":{"type":"integer","maximum":9007199254740991,"minimum":-9007199254740991},"type":"array"}`,
+			"count":   `{"type":"integer"}`,
+		},
+	} {
+		out, err := NewGenerator(context.Background(), WithInt64Format(format)).Generate(IntegerAccount{})
+		if err != nil {
+			t.Fatalf("Generate(%s) error = %v", format, err)
+		}
+		var props struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		if err := json.Unmarshal(out, &props); err != nil {
+			t.Fatal(err)
+		}
+		for name, w := range want {
+			if got := compactJSON(props.Properties[name]); got != w {
+				t.Errorf("%s: %s = %s, want %s", format, name, got, w)
+			}
+		}
+	}
+}
+
+// IntegerLevel is a named small integer.
+type IntegerLevel int8
+
+// IntegerSensor has integers of every width.
+type IntegerSensor struct {
+	Level   IntegerLevel `json:"level"`
+	Channel uint8        `json:"channel"`
+	Offset  int16        `json:"offset" validate:"min=-100"`
+	Samples []uint32     `json:"samples"`
+	Counter uint64       `json:"counter"`
+	Total   int          `json:"total"`
+}
+
+func TestIntegerBounds(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithIntegerBounds()).Generate(IntegerSensor{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"level":   `{"type":"integer","maximum":127,"minimum":-128}`,
+		"channel": `{"type":"integer","maximum":255,"minimum":0}`,
+		"offset":  `{"type":"integer","maximum":32767,"minimum":-100}`,
+		"samples": `{"items":{"type":"integer","maximum":4294967295,"minimum":0},"type":"array"}`,
+		"counter": `{"type":"integer","maximum":18446744073709551615,"minimum":0}`,
+		"total":   `{"type":"integer","maximum":9223372036854775807,"minimum":-9223372036854775808}`,
+	} {
+		if got := compactJSON(doc.Properties[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
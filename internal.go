@@ -0,0 +1,128 @@
+package schemator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"pkt.systems/logport"
+)
+
+// InternalTypePolicy decides what happens to fields whose type is
+// unexported or declared in an internal package the model's package cannot
+// import. Consumers of the schema cannot refer to such types, and their
+// comments are only found if the package source resolves.
+type InternalTypePolicy int
+
+const (
+	// AllowInternalTypes reflects internal types like any other (default).
+	AllowInternalTypes InternalTypePolicy = iota
+	// RejectInternalTypes makes generation fail, listing every field using
+	// an internal type.
+	RejectInternalTypes
+	// PermissiveInternalTypes replaces such fields with a schema accepting
+	// any value, keeping their description.
+	PermissiveInternalTypes
+)
+
+func (p InternalTypePolicy) String() string {
+	switch p {
+	case AllowInternalTypes:
+		return "allow"
+	case RejectInternalTypes:
+		return "reject"
+	case PermissiveInternalTypes:
+		return "permissive"
+	}
+	return fmt.Sprintf("InternalTypePolicy(%d)", int(p))
+}
+
+// applyInternalTypePolicy is the schema pass enforcing g.internalTypes.
+func (g *generator) applyInternalTypePolicy(rf *reflection, s *jsonschema.Schema) error {
+	l := logport.LoggerFromContext(g.ctx)
+	from := ""
+	if root := derefType(reflect.TypeOf(rf.model)); root != nil {
+		from = root.PkgPath()
+	}
+	var errs []error
+	replaced := false
+	err := rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			it, reason := internalType(fv.field.Type, from)
+			if it == nil {
+				return nil
+			}
+			field := fieldRef(fv.owner, fv.field)
+			switch g.internalTypes {
+			case RejectInternalTypes:
+				errs = append(errs, fmt.Errorf("%s: %s %s", field, reason, it))
+			case PermissiveInternalTypes:
+				rf.warn(UnmappedType, field, "%s %s replaced by a permissive schema", reason, it)
+				fv.parent.Properties.Set(fv.name, &jsonschema.Schema{
+					Description: fv.schema.Description,
+					Comments:    fmt.Sprintf("%s %s replaced by a permissive schema", reason, it),
+				})
+				replaced = true
+			default:
+				l.Debug("Field uses internal type", "field", field, "type", it.String(), "reason", reason)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("internal types are not allowed in schemas: %w", errors.Join(errs...))
+	}
+	if replaced {
+		pruneDefinitions(s)
+	}
+	return nil
+}
+
+// internalType returns the first named type reachable from t through
+// pointers, slices, arrays and maps that is unexported or declared in an
+// internal package the package from cannot import, and why.
+func internalType(t reflect.Type, from string) (reflect.Type, string) {
+	for t != nil {
+		if t.Name() != "" && t.PkgPath() != "" {
+			if !token.IsExported(t.Name()) {
+				return t, "unexported type"
+			}
+			if !internalVisible(t.PkgPath(), from) {
+				return t, "type in internal package"
+			}
+		}
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		case reflect.Map:
+			if it, reason := internalType(t.Key(), from); it != nil {
+				return it, reason
+			}
+			t = t.Elem()
+		default:
+			return nil, ""
+		}
+	}
+	return nil, ""
+}
+
+// internalVisible reports whether package from may import pkg under the Go
+// rule that a/b/internal/c is importable only from below a/b.
+func internalVisible(pkg, from string) bool {
+	i := strings.LastIndex("/"+pkg+"/", "/internal/")
+	if i < 0 {
+		return true
+	}
+	if i == 0 {
+		// internal/... is only importable from the standard library.
+		return standardPackage(from)
+	}
+	parent := pkg[:i-1]
+	return from == parent || strings.HasPrefix(from, parent+"/")
+}
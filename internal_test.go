@@ -0,0 +1,81 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// secretSettings is unexported but documented.
+type secretSettings struct {
+	Key string `json:"key"`
+}
+
+// ExposedService uses an unexported type.
+type ExposedService struct {
+	Name string `json:"name"`
+	// Settings are the service settings.
+	Settings secretSettings            `json:"settings"`
+	Extra    map[string]secretSettings `json:"extra"`
+}
+
+func TestInternalTypePolicy(t *testing.T) {
+	ctx := context.Background()
+	out, err := New(ctx, nil).Generate(ExposedService{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(out), "secretSettings is unexported but documented.") {
+		t.Fatalf("comment of unexported type missing:\n%s", out)
+	}
+
+	_, err = NewGenerator(ctx, WithInternalTypes(RejectInternalTypes)).Generate(ExposedService{})
+	if err == nil {
+		t.Fatal("expected error with RejectInternalTypes")
+	}
+	for _, want := range []string{"ExposedService.Settings: unexported type", "ExposedService.Extra: unexported type"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err, want)
+		}
+	}
+
+	out, err = NewGenerator(ctx, WithInternalTypes(PermissiveInternalTypes)).Generate(ExposedService{})
+	if err != nil {
+		t.Fatalf("Generate(permissive) error = %v", err)
+	}
+	var doc struct {
+		Defs       map[string]any            `json:"$defs"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	settings := doc.Properties["settings"]
+	if settings["type"] != nil || settings["description"] != "Settings are the service settings." || settings["$comment"] == nil {
+		t.Fatalf("settings = %v, want a permissive schema", settings)
+	}
+	if _, ok := doc.Defs["secretSettings"]; ok {
+		t.Fatalf("unused definition kept: %v", doc.Defs)
+	}
+}
+
+func TestInternalVisible(t *testing.T) {
+	tests := []struct {
+		pkg, from string
+		want      bool
+	}{
+		{"example.com/a/b", "example.com/x", true},
+		{"example.com/a/internal/c", "example.com/a", true},
+		{"example.com/a/internal/c", "example.com/a/d/e", true},
+		{"example.com/a/internal/c", "example.com/ab", false},
+		{"example.com/a/internal", "example.com/x", false},
+		{"internal/poll", "os", true},
+		{"internal/poll", "example.com/a", false},
+	}
+	for _, tt := range tests {
+		if got := internalVisible(tt.pkg, tt.from); got != tt.want {
+			t.Errorf("internalVisible(%q, %q) = %v, want %v", tt.pkg, tt.from, got, tt.want)
+		}
+	}
+}
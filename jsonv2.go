@@ -0,0 +1,132 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// splitJSONTag splits a json struct tag into the name and options, the way
+// encoding/json/v2 reads it: the name may be a single-quoted Go string
+// literal, which can hold commas and other characters a bare name cannot.
+//
+//	Name string `json:"'display name',omitzero"`
+func splitJSONTag(tag string) (string, []string, error) {
+	if !strings.HasPrefix(tag, "'") {
+		parts := strings.Split(tag, ",")
+		return parts[0], parts[1:], nil
+	}
+	end := -1
+	for i := 1; i < len(tag); i++ {
+		if tag[i] == '\\' {
+			i++
+			continue
+		}
+		if tag[i] == '\'' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", nil, fmt.Errorf("json tag %q has an unterminated quoted name", tag)
+	}
+	name, err := unquoteJSONName(tag[:end+1])
+	if err != nil {
+		return "", nil, fmt.Errorf("json tag %q: %w", tag, err)
+	}
+	rest := tag[end+1:]
+	if rest == "" {
+		return name, nil, nil
+	}
+	if rest[0] != ',' {
+		return "", nil, fmt.Errorf("json tag %q has text after its quoted name", tag)
+	}
+	return name, strings.Split(rest[1:], ","), nil
+}
+
+// unquoteJSONName decodes a single-quoted json/v2 name.
+func unquoteJSONName(quoted string) (string, error) {
+	inner := quoted[1 : len(quoted)-1]
+	inner = strings.ReplaceAll(inner, `\'`, `'`)
+	inner = strings.ReplaceAll(inner, `"`, `\"`)
+	return strconv.Unquote(`"` + inner + `"`)
+}
+
+// unquoteKey is a KeyNamer turning the single-quoted names of json/v2 into
+// property names. The Reflector splits tags on every comma, so names with
+// commas are reported by applyJSONv2Fields instead.
+func unquoteKey(name string) string {
+	if len(name) >= 2 && strings.HasPrefix(name, "'") && strings.HasSuffix(name, "'") {
+		if unquoted, err := unquoteJSONName(name); err == nil {
+			return unquoted
+		}
+	}
+	return name
+}
+
+// applyJSONv2Fields is the schema pass applying the json/v2 field options
+// the Reflector does not know. A field tagged "unknown" collects the
+// members without a field of their own: it is not a property, and the
+// object accepts additional properties of its map value type. It also
+// rejects quoted names the Reflector split apart.
+func applyJSONv2Fields(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.jsonv2Fields(t, ts)
+	})
+}
+
+func (rf *reflection) jsonv2Fields(t reflect.Type, ts *jsonschema.Schema) error {
+	if ts.Properties == nil {
+		return nil
+	}
+	tagName := rf.nameTag()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(tagName)
+		name, opts, err := splitJSONTag(tag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fieldRef(t, f), err)
+		}
+		if strings.HasPrefix(tag, "'") && strings.Contains(name, ",") {
+			return fmt.Errorf("%s: quoted json names containing commas are not supported", fieldRef(t, f))
+		}
+		if name == "-" && len(opts) == 0 {
+			continue
+		}
+		if f.Anonymous && name == "" && !slices.Contains(opts, "unknown") {
+			if ft := derefType(f.Type); ft.Kind() == reflect.Struct {
+				if err := rf.jsonv2Fields(ft, ts); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !slices.Contains(opts, "unknown") {
+			continue
+		}
+		// The Reflector named the property like a regular field.
+		key := strings.Split(tag, ",")[0]
+		if key == "" {
+			key = f.Name
+		}
+		key = unquoteKey(key)
+		if rf.KeyNamer != nil {
+			key = rf.KeyNamer(key)
+		}
+		prop, ok := ts.Properties.Get(key)
+		if !ok {
+			continue
+		}
+		ts.Properties.Delete(key)
+		ts.Required = removeString(ts.Required, key)
+		ts.AdditionalProperties = nil
+		if prop = unwrapNullable(prop); prop != nil && !isBooleanSchema(prop) && derefType(f.Type).Kind() == reflect.Map {
+			ts.AdditionalProperties = prop.AdditionalProperties
+		}
+	}
+	return nil
+}
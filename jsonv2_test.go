@@ -0,0 +1,74 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// JSONv2Record uses encoding/json/v2 tag options.
+type JSONv2Record struct {
+	Name    string            `json:"name"`
+	Created string            `json:"created,omitzero"`
+	Display string            `json:"'display name'"`
+	Escaped string            `json:"'a\\'b'"`
+	Extra   map[string]string `json:",unknown"`
+}
+
+func TestJSONv2Fields(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(JSONv2Record{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required             []string                   `json:"required"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+		AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"name", "display name", "a'b"}; !slices.Equal(doc.Required, want) {
+		t.Errorf("required = %v, want %v", doc.Required, want)
+	}
+	for _, name := range []string{"name", "created", "display name", "a'b"} {
+		if _, ok := doc.Properties[name]; !ok {
+			t.Errorf("property %q missing: %s", name, out)
+		}
+	}
+	if len(doc.Properties) != 4 {
+		t.Errorf("properties = %d, want 4: %s", len(doc.Properties), out)
+	}
+	if got := compactJSON(doc.AdditionalProperties); got != `{"type":"string"}` {
+		t.Errorf("additionalProperties = %s", got)
+	}
+}
+
+func TestSplitJSONTag(t *testing.T) {
+	for _, tc := range []struct {
+		tag, name string
+		opts      []string
+		err       bool
+	}{
+		{tag: "id,omitempty", name: "id", opts: []string{"omitempty"}},
+		{tag: "'a,b',omitzero", name: "a,b", opts: []string{"omitzero"}},
+		{tag: `'é'`, name: "é"},
+		{tag: "'open", err: true},
+		{tag: "'x'y", err: true},
+	} {
+		name, opts, err := splitJSONTag(tc.tag)
+		if (err != nil) != tc.err || name != tc.name || !slices.Equal(opts, tc.opts) {
+			t.Errorf("splitJSONTag(%q) = %q, %v, %v", tc.tag, name, opts, err)
+		}
+	}
+}
+
+func TestJSONv2QuotedNameWithComma(t *testing.T) {
+	type bad struct {
+		A string `json:"'a,b'"`
+	}
+	if _, err := New(context.Background(), nil).Generate(bad{}); err == nil {
+		t.Fatal("Generate() error = nil, want unsupported quoted name")
+	}
+}
@@ -0,0 +1,391 @@
+package schemator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// applyKubebuilderMarkers translates the controller-gen markers found in doc
+// comments (`// +optional`, `// +kubebuilder:validation:Minimum=0`,
+// `// +kubebuilder:default=3`, ...) into JSON Schema keywords, so types
+// annotated for controller-gen produce the same constraints. Markers on a
+// type apply wherever the type is used; markers on a field apply to its
+// property. Unknown kubebuilder validation markers and schemator directives
+// are ignored with a warning; markers of other tools are ignored silently.
+func applyKubebuilderMarkers(rf *reflection, s *jsonschema.Schema) error {
+	if len(rf.markers) == 0 {
+		return nil
+	}
+	return rf.forEachType(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		key := typeKey(t)
+		typeMarkers := rf.markers[key]
+		rf.warnUnknownMarkers(key, typeMarkers)
+		if err := applyMarkers(key, ts, typeMarkers); err != nil {
+			return err
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if err := rf.applyTypeMarkers(fv.field.Type, fv.schema); err != nil {
+				return err
+			}
+			fieldKey := typeKey(fv.owner) + "." + fv.field.Name
+			fieldMarkers := rf.markers[fieldKey]
+			rf.warnUnknownMarkers(fieldKey, fieldMarkers)
+			if err := applyMarkers(fieldKey, fv.schema, fieldMarkers); err != nil {
+				return err
+			}
+			applyRequiredMarkers(fv, typeMarkers, fieldMarkers)
+			if hasMarker(fieldMarkers, "nullable", "kubebuilder:validation:Nullable") {
+				prop, _ := fv.parent.Properties.Get(fv.name)
+				if unwrapNullable(prop) == prop {
+					fv.parent.Properties.Set(fv.name, &jsonschema.Schema{
+						OneOf: []*jsonschema.Schema{prop, {Type: "null"}},
+					})
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// applyTypeMarkers applies the markers of the named type t (or of the element
+// types of t) to a schema the type was inlined into. Types reflected as
+// definitions already carry their markers.
+func (rf *reflection) applyTypeMarkers(t reflect.Type, s *jsonschema.Schema) error {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) || s.Ref != "" {
+		return nil
+	}
+	if key := typeKey(t); key != "" && t.Kind() != reflect.Struct {
+		if err := applyMarkers(key, s, rf.markers[key]); err != nil {
+			return err
+		}
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return rf.applyTypeMarkers(t.Elem(), s.Items)
+		}
+	case reflect.Map:
+		return rf.applyTypeMarkers(t.Elem(), s.AdditionalProperties)
+	}
+	return nil
+}
+
+// applyRequiredMarkers adjusts the required list of the field's parent from
+// +optional/+required markers on the field or its declaring type.
+func applyRequiredMarkers(fv fieldVisit, typeMarkers, fieldMarkers []string) {
+	switch {
+	case hasMarker(fieldMarkers, "optional", "kubebuilder:validation:Optional"):
+		fv.parent.Required = removeString(fv.parent.Required, fv.name)
+	case hasMarker(fieldMarkers, "required", "kubebuilder:validation:Required"):
+		fv.parent.Required = appendUnique(fv.parent.Required, fv.name)
+	case hasMarker(typeMarkers, "kubebuilder:validation:Optional"):
+		fv.parent.Required = removeString(fv.parent.Required, fv.name)
+	case hasMarker(typeMarkers, "kubebuilder:validation:Required"):
+		fv.parent.Required = appendUnique(fv.parent.Required, fv.name)
+	}
+}
+
+func hasMarker(markers []string, names ...string) bool {
+	for _, m := range markers {
+		name, _, _ := strings.Cut(m, "=")
+		for _, n := range names {
+			if name == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyMarkers applies markers to s. The Type marker replaces the reflected
+// schema and is therefore applied first; ExclusiveMinimum/ExclusiveMaximum
+// modify the bound set by Minimum/Maximum and are applied last.
+func applyMarkers(key string, s *jsonschema.Schema, markers []string) error {
+	if len(markers) == 0 || s == nil || isBooleanSchema(s) {
+		return nil
+	}
+	ordered := append([]string(nil), markers...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return markerRank(ordered[i]) < markerRank(ordered[j])
+	})
+	for _, m := range ordered {
+		if err := applyMarker(s, m); err != nil && !errors.Is(err, errUnknownMarker) {
+			return fmt.Errorf("%s: marker +%s: %w", key, m, err)
+		}
+	}
+	for _, m := range ordered {
+		if v, ok := strings.CutPrefix(m, "kubebuilder:validation:items:"); ok && s.Items != nil && !isBooleanSchema(s.Items) {
+			if err := applyMarker(s.Items, "kubebuilder:validation:"+v); err != nil && !errors.Is(err, errUnknownMarker) {
+				return fmt.Errorf("%s: marker +%s: %w", key, m, err)
+			}
+		}
+	}
+	return nil
+}
+
+func markerRank(marker string) int {
+	switch name, _, _ := strings.Cut(marker, "="); name {
+	case "kubebuilder:validation:Type":
+		return 0
+	case "kubebuilder:validation:ExclusiveMinimum", "kubebuilder:validation:ExclusiveMaximum":
+		return 2
+	}
+	return 1
+}
+
+// errUnknownMarker is returned by applyMarker for kubebuilder validation
+// markers it does not know.
+var errUnknownMarker = errors.New("unknown marker")
+
+// applyMarker applies a single marker to s. Markers outside the kubebuilder
+// namespace are left alone.
+func applyMarker(s *jsonschema.Schema, marker string) error {
+	name, value, _ := strings.Cut(marker, "=")
+	switch name {
+	case "kubebuilder:default", "default":
+		v, err := markerValue(s, value)
+		if err != nil {
+			return err
+		}
+		s.Default = v
+		return nil
+	case "kubebuilder:example":
+		v, err := markerValue(s, value)
+		if err != nil {
+			return err
+		}
+		s.Examples = append(s.Examples, v)
+		return nil
+	case "kubebuilder:title":
+		s.Title = unquoteMarker(value)
+		return nil
+	case "kubebuilder:pruning:PreserveUnknownFields":
+		setExtra(s, "x-kubernetes-preserve-unknown-fields", true)
+		return nil
+	case "listType":
+		setExtra(s, "x-kubernetes-list-type", unquoteMarker(value))
+		return nil
+	case "listMapKey":
+		keys, _ := s.Extras["x-kubernetes-list-map-keys"].([]any)
+		setExtra(s, "x-kubernetes-list-map-keys", append(keys, unquoteMarker(value)))
+		return nil
+	case "mapType":
+		setExtra(s, "x-kubernetes-map-type", unquoteMarker(value))
+		return nil
+	case "structType":
+		setExtra(s, "x-kubernetes-map-type", unquoteMarker(value))
+		return nil
+	}
+	validation, ok := strings.CutPrefix(name, "kubebuilder:validation:")
+	if !ok {
+		return nil
+	}
+	// Markers with several arguments name the first one after a colon:
+	// +kubebuilder:validation:XValidation:rule="...",message="...".
+	if v, firstArg, found := strings.Cut(validation, ":"); found && v == "XValidation" {
+		validation, value = v, firstArg+"="+value
+	}
+	switch validation {
+	case "Minimum":
+		return setNumber(&s.Minimum, value)
+	case "Maximum":
+		return setNumber(&s.Maximum, value)
+	case "MultipleOf":
+		return setNumber(&s.MultipleOf, value)
+	case "ExclusiveMinimum":
+		return setExclusive(&s.ExclusiveMinimum, &s.Minimum, value)
+	case "ExclusiveMaximum":
+		return setExclusive(&s.ExclusiveMaximum, &s.Maximum, value)
+	case "MinLength":
+		return setCount(&s.MinLength, value)
+	case "MaxLength":
+		return setCount(&s.MaxLength, value)
+	case "MinItems":
+		return setCount(&s.MinItems, value)
+	case "MaxItems":
+		return setCount(&s.MaxItems, value)
+	case "MinProperties":
+		return setCount(&s.MinProperties, value)
+	case "MaxProperties":
+		return setCount(&s.MaxProperties, value)
+	case "UniqueItems":
+		b, err := markerBool(value)
+		s.UniqueItems = b
+		return err
+	case "Pattern":
+		s.Pattern = unquoteMarker(value)
+		return nil
+	case "Format":
+		s.Format = unquoteMarker(value)
+		return nil
+	case "Enum":
+		values, err := markerEnum(s, value)
+		if err != nil {
+			return err
+		}
+		s.Enum = values
+		return nil
+	case "Type":
+		*s = jsonschema.Schema{Type: unquoteMarker(value), Description: s.Description, Title: s.Title}
+		return nil
+	case "XPreserveUnknownFields":
+		setExtra(s, "x-kubernetes-preserve-unknown-fields", true)
+		return nil
+	case "XEmbeddedResource", "EmbeddedResource":
+		setExtra(s, "x-kubernetes-embedded-resource", true)
+		return nil
+	case "XIntOrString":
+		*s = jsonschema.Schema{
+			AnyOf:       []*jsonschema.Schema{{Type: "integer"}, {Type: "string"}},
+			Description: s.Description,
+			Title:       s.Title,
+			Extras:      s.Extras,
+		}
+		setExtra(s, "x-kubernetes-int-or-string", true)
+		return nil
+	case "XValidation":
+		rule, err := markerArgs(value)
+		if err != nil {
+			return err
+		}
+		if rule["rule"] == nil {
+			return fmt.Errorf("missing rule")
+		}
+		rules, _ := s.Extras["x-kubernetes-validations"].([]any)
+		setExtra(s, "x-kubernetes-validations", append(rules, rule))
+		return nil
+	case "Optional", "Required", "Nullable":
+		// Handled by applyRequiredMarkers and applyKubebuilderMarkers.
+		return nil
+	}
+	if strings.HasPrefix(validation, "items:") {
+		// Applied to the items schema by applyMarkers.
+		return nil
+	}
+	return errUnknownMarker
+}
+
+// markerValue parses a default or example value: JSON when it parses and
+// suits the schema type, a plain string otherwise.
+func markerValue(s *jsonschema.Schema, value string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		if _, isString := v.(string); isString || s.Type != "string" {
+			return v, nil
+		}
+	}
+	switch s.Type {
+	case "integer", "number", "boolean", "object", "array":
+		return nil, fmt.Errorf("value %q is not a valid %s", value, s.Type)
+	}
+	return unquoteMarker(value), nil
+}
+
+// markerEnum parses "a;b;c" (or "{a,b,c}") into values typed after s.
+func markerEnum(s *jsonschema.Schema, value string) ([]any, error) {
+	sep := ";"
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		value, sep = value[1:len(value)-1], ","
+	}
+	var out []any
+	for _, item := range strings.Split(value, sep) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		v, err := markerValue(s, item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// markerArgs parses `rule="self > 0",message="must be positive"`.
+func markerArgs(value string) (map[string]any, error) {
+	args := make(map[string]any)
+	for value != "" {
+		key, rest, found := strings.Cut(value, "=")
+		if !found {
+			return nil, fmt.Errorf("argument %q has no value", value)
+		}
+		key = strings.TrimSpace(key)
+		var v string
+		if rest != "" && (rest[0] == '"' || rest[0] == '`') {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: %w", key, err)
+			}
+			v, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			v, rest, _ = strings.Cut(rest, ",")
+			rest = "," + rest
+		}
+		switch v {
+		case "true", "false":
+			args[key] = v == "true"
+		default:
+			args[key] = v
+		}
+		value = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return args, nil
+}
+
+func unquoteMarker(value string) string {
+	if u, err := strconv.Unquote(value); err == nil {
+		return u
+	}
+	return value
+}
+
+func markerBool(value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func setNumber(dst *json.Number, value string) error {
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	*dst = json.Number(value)
+	return nil
+}
+
+// setExclusive turns the OpenAPI v3 style boolean ExclusiveMinimum=true into
+// the numeric exclusiveMinimum of JSON Schema 2020-12.
+func setExclusive(exclusive, bound *json.Number, value string) error {
+	b, err := markerBool(value)
+	if err != nil || !b {
+		return err
+	}
+	if *bound == "" {
+		return fmt.Errorf("no bound to make exclusive")
+	}
+	*exclusive, *bound = *bound, ""
+	return nil
+}
+
+func setCount(dst **uint64, value string) error {
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a non-negative integer", value)
+	}
+	*dst = &n
+	return nil
+}
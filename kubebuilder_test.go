@@ -0,0 +1,102 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+// KBPhase is the lifecycle phase of a KBWidget.
+// +kubebuilder:validation:Enum=Pending;Running;Failed
+type KBPhase string
+
+// KBWidget is a controller-gen annotated test type.
+type KBWidget struct {
+	// Replicas is the desired number of replicas.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=1
+	// +optional
+	Replicas int `json:"replicas"`
+	// Name of the widget.
+	// +kubebuilder:validation:Pattern=`^[a-z]+$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name,omitempty"`
+	// +required
+	Phase KBPhase `json:"phase,omitempty"`
+	// Ratio must be strictly positive.
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Minimum=0
+	Ratio float64 `json:"ratio"`
+	// Ports served by the widget.
+	// +listType=set
+	// +kubebuilder:validation:items:Maximum=65535
+	Ports []int `json:"ports"`
+	// +kubebuilder:validation:XValidation:rule="self.size() > 0",message="must not be empty"
+	Owner string `json:"owner"`
+}
+
+func TestKubebuilderMarkers(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(KBWidget{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(doc.Required, "replicas") || !slices.Contains(doc.Required, "phase") {
+		t.Fatalf("required = %v, want phase but not replicas", doc.Required)
+	}
+	replicas := doc.Properties["replicas"]
+	if replicas["minimum"] != 0.0 || replicas["maximum"] != 10.0 || replicas["default"] != 1.0 {
+		t.Fatalf("replicas = %v", replicas)
+	}
+	if replicas["description"] != "Replicas is the desired number of replicas." {
+		t.Fatalf("markers leaked into description: %q", replicas["description"])
+	}
+	name := doc.Properties["name"]
+	if name["pattern"] != "^[a-z]+$" || name["maxLength"] != 63.0 {
+		t.Fatalf("name = %v", name)
+	}
+	phase := doc.Properties["phase"]
+	if enum, _ := phase["enum"].([]any); len(enum) != 3 || enum[0] != "Pending" {
+		t.Fatalf("phase = %v, want enum from type markers", phase)
+	}
+	ratio := doc.Properties["ratio"]
+	if ratio["exclusiveMinimum"] != 0.0 || ratio["minimum"] != nil {
+		t.Fatalf("ratio = %v, want exclusiveMinimum 0", ratio)
+	}
+	ports := doc.Properties["ports"]
+	if ports["x-kubernetes-list-type"] != "set" || ports["items"].(map[string]any)["maximum"] != 65535.0 {
+		t.Fatalf("ports = %v", ports)
+	}
+	rules, _ := doc.Properties["owner"]["x-kubernetes-validations"].([]any)
+	if len(rules) != 1 || rules[0].(map[string]any)["message"] != "must not be empty" {
+		t.Fatalf("owner = %v", doc.Properties["owner"])
+	}
+}
+
+func TestKubebuilderMarkerErrors(t *testing.T) {
+	err := applyMarkers("x.T.F", &jsonschema.Schema{Type: "integer"}, []string{"kubebuilder:validation:Minimum=abc"})
+	if err == nil || !strings.Contains(err.Error(), "x.T.F") {
+		t.Fatalf("applyMarkers() error = %v, want error naming the field", err)
+	}
+}
+
+func TestSplitMarkers(t *testing.T) {
+	text, markers := splitMarkers("Size is the size.\n+optional\n+kubebuilder:validation:Minimum=1\n1 + 1 is two.\n")
+	if strings.Contains(text, "optional") || !strings.Contains(text, "1 + 1") {
+		t.Fatalf("text = %q", text)
+	}
+	if !slices.Equal(markers, []string{"optional", "kubebuilder:validation:Minimum=1"}) {
+		t.Fatalf("markers = %v", markers)
+	}
+}
@@ -0,0 +1,53 @@
+package schemator
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// OutputLayout selects where in the output directory files are written.
+type OutputLayout int
+
+const (
+	// LayoutFlat writes every file directly into the output directory
+	// (default).
+	LayoutFlat OutputLayout = iota
+	// LayoutPackages writes the files of a model into a subdirectory named
+	// after the import path of its package relative to its module, such as
+	// billing/v1/Invoice.schema.json for
+	// github.com/acme/contracts/billing/v1.Invoice. Models of a module's root
+	// package stay in the output directory, and models of packages whose
+	// module is unknown, such as the standard library, use the whole import
+	// path.
+	LayoutPackages
+)
+
+func (l OutputLayout) String() string {
+	switch l {
+	case LayoutFlat:
+		return "flat"
+	case LayoutPackages:
+		return "packages"
+	}
+	return fmt.Sprintf("OutputLayout(%d)", int(l))
+}
+
+// layoutPath returns filename, the name of a file of model, placed in the
+// subdirectory the layout puts it in.
+func (g *generator) layoutPath(model any, filename string) string {
+	if g.layout != LayoutPackages || filename == "" {
+		return filename
+	}
+	return path.Join(packageDir(typePkgPath(derefType(reflect.TypeOf(model)))), filename)
+}
+
+// packageDir returns the directory LayoutPackages writes the files of models
+// declared in the package pkgPath into.
+func packageDir(pkgPath string) string {
+	if module, _ := packageModule(pkgPath, buildModules()); module != "" && module != "std" {
+		return strings.TrimPrefix(strings.TrimPrefix(pkgPath, module), "/")
+	}
+	return pkgPath
+}
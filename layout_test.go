@@ -0,0 +1,36 @@
+package schemator
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayoutPackages(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewGenerator(context.Background(), WithLayout(LayoutPackages), WithVersion("1.0.0"))
+	if err := gen.WriteSchemas(dir, token.Position{}, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	files := []string{
+		filepath.Join("go", "token", "Position.1.0.0.schema.json"),
+		filepath.Join(packageDir(schematorPath), "OrderedBase.1.0.0.schema.json"),
+	}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 2 || m.Artifacts[1].File != "go/token/Position.1.0.0.schema.json" {
+		t.Errorf("manifest = %+v", m.Artifacts)
+	}
+	if err := gen.Verify(dir, token.Position{}, OrderedBase{}); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package schemator
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// ManifestFilename is the name of the manifest WriteSchemas maintains inside
+// the output directory.
+const ManifestFilename = "manifest.json"
+
+// Manifest lists the artifacts written to an output directory.
+type Manifest struct {
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is a file recorded in a Manifest.
+type Artifact struct {
+	// File is the filename relative to the output directory.
+	File string `json:"file"`
+	// Type is the name of the model the artifact was generated from.
+	Type string `json:"type"`
+	// Format is the Format.Name the artifact was written in. Manifests
+	// written before formats existed leave it empty, meaning JSON Schema.
+	Format string `json:"format,omitempty"`
+	// Version is the version the artifact was written as, if any.
+	Version string `json:"version,omitempty"`
+	// Tags mark artifacts that retention policies may keep, e.g. "release".
+	Tags []string `json:"tags,omitempty"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the file as written.
+	SHA256 string `json:"sha256,omitempty"`
+	// Package is the import path of the package declaring the model.
+	Package string `json:"package,omitempty"`
+	// PackageVersion is the version of the module providing Package, as
+	// recorded in the build information of the generating binary.
+	PackageVersion string `json:"packageVersion,omitempty"`
+	// Dependencies are the external schema files the artifact refers to or
+	// embeds (see the schemator:ref and schemator:embed directives),
+	// relative to the output directory.
+	Dependencies []string `json:"dependencies,omitempty"`
+	// Created is when the artifact was (last) written.
+	Created time.Time `json:"created"`
+}
+
+// ReadManifest reads the manifest in dir. A missing manifest yields an empty
+// Manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Manifest{}, nil
+		}
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(contents, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, ManifestFilename), err)
+	}
+	return m, nil
+}
+
+// WriteManifest writes m to the manifest in dir, artifacts sorted by file.
+// An existing manifest keeps its permissions.
+func WriteManifest(dir string, m *Manifest) error {
+	return writeManifest(dir, m, defaultPermissions.keepFileMode(filepath.Join(dir, ManifestFilename)).write)
+}
+
+// writeManifest is WriteManifest writing the manifest with write.
+func writeManifest(dir string, m *Manifest, write func(name string, data []byte) error) error {
+	out, err := encodeManifest(m)
+	if err != nil {
+		return err
+	}
+	return write(filepath.Join(dir, ManifestFilename), out)
+}
+
+// encodeManifest sorts the artifacts of m by file and returns m as the
+// content of a manifest file.
+func encodeManifest(m *Manifest) ([]byte, error) {
+	sort.Slice(m.Artifacts, func(i, j int) bool {
+		return m.Artifacts[i].File < m.Artifacts[j].File
+	})
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// VerifyManifest checks every artifact of the manifest in dir that records a
+// checksum against the file it lists, and returns an error naming the files
+// that are missing or whose content changed since they were written.
+func VerifyManifest(dir string) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	var problems []error
+	for _, a := range m.Artifacts {
+		if a.SHA256 == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(a.File)))
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		if sum := checksum(content); sum != a.SHA256 {
+			problems = append(problems, fmt.Errorf("%s: sha256 %s, manifest records %s", a.File, sum, a.SHA256))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// checksum returns the hex-encoded SHA-256 checksum of content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// newArtifact returns the Artifact recording filename, holding out in format
+// generated from model.
+func (g *generator) newArtifact(filename string, model any, format string, out []byte) Artifact {
+	a := Artifact{
+		File:    filename,
+		Type:    toString(model),
+		Format:  format,
+		Version: g.version,
+		Tags:    g.versionTags,
+		SHA256:  checksum(g.fileContent(out)),
+		Package: typePkgPath(derefType(reflect.TypeOf(model))),
+	}
+	if a.Package != "" {
+		_, a.PackageVersion = packageModule(a.Package, buildModules())
+	}
+	return a
+}
+
+// upsert adds a or replaces the artifact with the same file.
+func (m *Manifest) upsert(a Artifact) {
+	for i := range m.Artifacts {
+		if m.Artifacts[i].File == a.File {
+			m.Artifacts[i] = a
+			return
+		}
+	}
+	m.Artifacts = append(m.Artifacts, a)
+}
+
+func recordArtifacts(dir string, artifacts []Artifact, now time.Time, write func(name string, data []byte) error) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	for _, a := range artifacts {
+		a.Created = now
+		m.upsert(a)
+	}
+	return writeManifest(dir, m, write)
+}
+
+// RetentionPolicy decides which versioned artifacts GC keeps.
+type RetentionPolicy struct {
+	// KeepLast is the number of most recent versions kept per type. It must
+	// be at least 1.
+	KeepLast int
+	// KeepTags keeps artifacts carrying any of these tags regardless of age.
+	KeepTags []string
+	// DryRun reports what would be removed without touching the directory.
+	DryRun bool
+}
+
+// GC prunes old versioned artifacts from dir according to policy and returns
+// the files it removed (or would remove when policy.DryRun is set). Only files
+// recorded in the manifest are considered, so unrelated files are never
+// deleted; the manifest is rewritten without the pruned entries. Versions are
+// ordered by when they were written, and by version where that is the same.
+// Files that cannot be removed stay in the manifest and their errors are
+// returned together.
+func GC(dir string, policy RetentionPolicy) ([]string, error) {
+	if policy.KeepLast < 1 {
+		return nil, fmt.Errorf("retention policy must keep at least one version, got KeepLast=%d", policy.KeepLast)
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Versions are counted per type and format.
+	byType := make(map[[2]string][]Artifact)
+	for _, a := range m.Artifacts {
+		format := a.Format
+		if format == "" {
+			format = JSONSchemaFormat.Name
+		}
+		key := [2]string{a.Type, format}
+		byType[key] = append(byType[key], a)
+	}
+	remove := make(map[string]bool)
+	for _, artifacts := range byType {
+		sort.SliceStable(artifacts, func(i, j int) bool {
+			return newerArtifact(artifacts[i], artifacts[j])
+		})
+		for i, a := range artifacts {
+			if i < policy.KeepLast || a.Version == "" || hasAnyTag(a.Tags, policy.KeepTags) {
+				continue
+			}
+			remove[a.File] = true
+		}
+	}
+	// Check every file before removing any, so a bad entry leaves the
+	// directory untouched.
+	for file := range remove {
+		if !filepath.IsLocal(filepath.FromSlash(file)) {
+			return nil, fmt.Errorf("refusing to remove %q outside %s", file, dir)
+		}
+	}
+	var removed []string
+	var errs []error
+	kept := m.Artifacts[:0]
+	for _, a := range m.Artifacts {
+		if !remove[a.File] {
+			kept = append(kept, a)
+			continue
+		}
+		if !policy.DryRun {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(a.File))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				// The entry stays in the manifest, as its file does.
+				errs = append(errs, err)
+				kept = append(kept, a)
+				continue
+			}
+		}
+		removed = append(removed, a.File)
+	}
+	sort.Strings(removed)
+	if policy.DryRun || len(removed) == 0 {
+		return removed, errors.Join(errs...)
+	}
+	m.Artifacts = kept
+	return removed, errors.Join(append(errs, WriteManifest(dir, m))...)
+}
+
+// newerArtifact reports whether a was written after b. Artifacts written at
+// the same time, as under SOURCE_DATE_EPOCH, are ordered by version.
+func newerArtifact(a, b Artifact) bool {
+	if !a.Created.Equal(b.Created) {
+		return a.Created.After(b.Created)
+	}
+	return compareVersions(a.Version, b.Version) > 0
+}
+
+// compareVersions compares the versions a and b as semantic versions, with
+// or without a leading "v", where both parse, and by name otherwise.
+func compareVersions(a, b string) int {
+	sa, sb := a, b
+	if !strings.HasPrefix(sa, "v") {
+		sa = "v" + sa
+	}
+	if !strings.HasPrefix(sb, "v") {
+		sb = "v" + sb
+	}
+	if semver.IsValid(sa) && semver.IsValid(sb) {
+		if c := semver.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a, b)
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, t := range tags {
+		if slices.Contains(wanted, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyPaths returns files relative to dir, or absolute where no
+// relative path exists.
+func dependencyPaths(dir string, files []string) []string {
+	var paths []string
+	for _, f := range files {
+		if abs, err := filepath.Abs(dir); err == nil {
+			if rel, err := filepath.Rel(abs, f); err == nil {
+				f = rel
+			}
+		}
+		paths = append(paths, filepath.ToSlash(f))
+	}
+	return paths
+}
@@ -0,0 +1,200 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestWriteSchemasWithVersionRecordsManifest(t *testing.T) {
+	outDir := t.TempDir()
+	gen := NewGenerator(context.Background(), WithVersion("v1.2.0", "release"))
+	if err := gen.WriteSchemas(outDir, example.Subject{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "Subject.v1.2.0.schema.json")); err != nil {
+		t.Fatalf("versioned schema missing: %v", err)
+	}
+	m, err := ReadManifest(outDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if len(m.Artifacts) != 1 {
+		t.Fatalf("expected one artifact, got %+v", m.Artifacts)
+	}
+	a := m.Artifacts[0]
+	if a.File != "Subject.v1.2.0.schema.json" || a.Type != "Subject" || a.Version != "v1.2.0" || !reflect.DeepEqual(a.Tags, []string{"release"}) {
+		t.Fatalf("unexpected artifact %+v", a)
+	}
+}
+
+func TestGCKeepsLastAndTagged(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &Manifest{}
+	for i, v := range []string{"v1", "v2", "v3", "v4"} {
+		file := "Subject." + v + ".schema.json"
+		writeFile(t, filepath.Join(dir, file), "{}")
+		a := Artifact{File: file, Type: "Subject", Version: v, Created: base.Add(time.Duration(i) * time.Hour)}
+		if v == "v1" {
+			a.Tags = []string{"release"}
+		}
+		m.Artifacts = append(m.Artifacts, a)
+	}
+	writeFile(t, filepath.Join(dir, "unrelated.json"), "{}")
+	if err := WriteManifest(dir, m); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+
+	planned, err := GC(dir, RetentionPolicy{KeepLast: 2, KeepTags: []string{"release"}, DryRun: true})
+	if err != nil {
+		t.Fatalf("GC(dry-run) error = %v", err)
+	}
+	if !reflect.DeepEqual(planned, []string{"Subject.v2.schema.json"}) {
+		t.Fatalf("GC(dry-run) = %v", planned)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Subject.v2.schema.json")); err != nil {
+		t.Fatalf("dry-run removed a file: %v", err)
+	}
+
+	removed, err := GC(dir, RetentionPolicy{KeepLast: 2, KeepTags: []string{"release"}})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if !reflect.DeepEqual(removed, planned) {
+		t.Fatalf("GC() = %v, want %v", removed, planned)
+	}
+	for file, want := range map[string]bool{
+		"Subject.v1.schema.json": true,
+		"Subject.v2.schema.json": false,
+		"Subject.v3.schema.json": true,
+		"Subject.v4.schema.json": true,
+		"unrelated.json":         true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, file))
+		if exists := err == nil; exists != want {
+			t.Fatalf("%s exists = %v, want %v", file, exists, want)
+		}
+	}
+	after, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if len(after.Artifacts) != 3 {
+		t.Fatalf("manifest not pruned: %+v", after.Artifacts)
+	}
+	if _, err := GC(dir, RetentionPolicy{}); err == nil {
+		t.Fatalf("GC() with KeepLast=0 error = nil")
+	}
+}
+
+func TestGCOrdersVersionsWrittenAtTheSameTime(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Unix(1700000000, 0).UTC()
+	m := &Manifest{}
+	for _, v := range []string{"1.0.0", "1.10.0", "1.9.0"} {
+		file := "Subject." + v + ".schema.json"
+		writeFile(t, filepath.Join(dir, file), "{}")
+		m.Artifacts = append(m.Artifacts, Artifact{File: file, Type: "Subject", Version: v, Created: created})
+	}
+	if err := WriteManifest(dir, m); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	removed, err := GC(dir, RetentionPolicy{KeepLast: 1, DryRun: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if want := []string{"Subject.1.0.0.schema.json", "Subject.1.9.0.schema.json"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("GC() = %v, want %v", removed, want)
+	}
+}
+
+func TestGCKeepsManifestInSyncOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Unix(1700000000, 0).UTC()
+	m := &Manifest{}
+	for _, v := range []string{"v1", "v2", "v3"} {
+		file := "schemas/Subject." + v + ".schema.json"
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(file)), "{}")
+		m.Artifacts = append(m.Artifacts, Artifact{File: file, Type: "Subject", Version: v, Created: created})
+	}
+	// A directory that is not empty cannot be removed.
+	stuck := "schemas/Subject.v0.schema.json"
+	writeFile(t, filepath.Join(dir, filepath.FromSlash(stuck), "keep"), "")
+	m.Artifacts = append(m.Artifacts, Artifact{File: stuck, Type: "Subject", Version: "v0", Created: created})
+	if err := WriteManifest(dir, m); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+
+	outside := &Manifest{Artifacts: append(slices.Clone(m.Artifacts), Artifact{File: "../Subject.v00.schema.json", Type: "Subject", Version: "v00", Created: created})}
+	if err := WriteManifest(dir, outside); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	if removed, err := GC(dir, RetentionPolicy{KeepLast: 1}); err == nil || len(removed) != 0 {
+		t.Fatalf("GC() = %v, %v, want an error before removing anything", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "schemas", "Subject.v1.schema.json")); err != nil {
+		t.Fatalf("GC() removed a file after refusing to: %v", err)
+	}
+
+	if err := WriteManifest(dir, m); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	removed, err := GC(dir, RetentionPolicy{KeepLast: 1})
+	if err == nil {
+		t.Fatal("GC() error = nil, want the error removing " + stuck)
+	}
+	if want := []string{"schemas/Subject.v1.schema.json", "schemas/Subject.v2.schema.json"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("GC() = %v, want %v", removed, want)
+	}
+	after, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	var files []string
+	for _, a := range after.Artifacts {
+		files = append(files, a.File)
+	}
+	if want := []string{stuck, "schemas/Subject.v3.schema.json"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("manifest = %v, want %v", files, want)
+	}
+	if err := VerifyManifest(dir); err != nil {
+		t.Errorf("VerifyManifest() error = %v", err)
+	}
+}
+
+func TestManifestChecksums(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background(), WithManifest()).WriteSchemas(dir, example.Subject{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 1 {
+		t.Fatalf("expected one artifact, got %+v", m.Artifacts)
+	}
+	a := m.Artifacts[0]
+	content, err := os.ReadFile(filepath.Join(dir, "Subject.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SHA256 != checksum(content) || a.Package != "pkt.systems/schemator/example" || a.PackageVersion == "" {
+		t.Fatalf("unexpected artifact %+v", a)
+	}
+	if err := VerifyManifest(dir); err != nil {
+		t.Fatalf("VerifyManifest() error = %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "Subject.schema.json"), "{}")
+	if err := VerifyManifest(dir); err == nil || !strings.Contains(err.Error(), "Subject.schema.json: sha256") {
+		t.Fatalf("VerifyManifest() after tampering error = %v", err)
+	}
+}
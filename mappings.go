@@ -0,0 +1,88 @@
+package schemator
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// RegisterTypeMapping returns an Option rendering every use of type t with
+// the schema fn returns, for types whose schema cannot be reflected and that
+// cannot implement JSONSchema() themselves, such as company-internal Money
+// or ULID types:
+//
+//	schemator.RegisterTypeMapping(reflect.TypeFor[ulid.ULID](), func() *jsonschema.Schema {
+//		return &jsonschema.Schema{Type: "string", Pattern: "^[0-9A-HJKMNP-TV-Z]{26}$"}
+//	})
+//
+// fn is called for every use, so each property gets its own schema. Pointers
+// to t are mapped too.
+func RegisterTypeMapping(t reflect.Type, fn func() *jsonschema.Schema) Option {
+	return func(g *generator) {
+		if g.typeMappings == nil {
+			g.typeMappings = make(map[reflect.Type]func() *jsonschema.Schema)
+		}
+		g.typeMappings[derefType(t)] = fn
+	}
+}
+
+// mapType is the Reflector Mapper applying the registered type mappings,
+// then the built-in ones.
+func (g *generator) mapType(t reflect.Type) *jsonschema.Schema {
+	if fn, ok := g.typeMappings[t]; ok && fn != nil {
+		return fn()
+	}
+	if t == durationType {
+		return durationSchema(g.durationFormat)
+	}
+	if unstructured(t) {
+		return g.unstructuredSchema()
+	}
+	if g.unsupported(t) {
+		// The Reflector panics on these; applyUnsupportedKinds removes
+		// the fields.
+		return &jsonschema.Schema{}
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t.Elem() != byteType {
+		// encoding/json writes slices of any byte type as base64; the
+		// Reflector only knows []byte.
+		return &jsonschema.Schema{Type: "string", ContentEncoding: "base64"}
+	}
+	if t.Name() == "" {
+		return nil
+	}
+	return builtinSchema(t.PkgPath() + "." + t.Name())
+}
+
+// byteType is the element type of the []byte the Reflector encodes itself.
+var byteType = reflect.TypeFor[byte]()
+
+// decimalPattern matches the decimal strings shopspring/decimal writes.
+const decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// builtinSchema returns the schema of popular types whose reflected schema
+// does not match their JSON encoding, by "<import path>.<Name>" so that no
+// dependency on their modules is needed.
+func builtinSchema(name string) *jsonschema.Schema {
+	switch name {
+	case "encoding/json.Number":
+		return &jsonschema.Schema{Type: "number"}
+	case "github.com/shopspring/decimal.Decimal":
+		return &jsonschema.Schema{Type: "string", Pattern: decimalPattern}
+	case "github.com/shopspring/decimal.NullDecimal":
+		return &jsonschema.Schema{OneOf: []*jsonschema.Schema{{Type: "string", Pattern: decimalPattern}, {Type: "null"}}}
+	case "cloud.google.com/go/civil.Date":
+		return &jsonschema.Schema{Type: "string", Format: "date"}
+	case "cloud.google.com/go/civil.Time":
+		return &jsonschema.Schema{Type: "string", Pattern: `^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]{1,9})?$`}
+	case "cloud.google.com/go/civil.DateTime":
+		return &jsonschema.Schema{Type: "string", Pattern: `^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]{1,9})?$`}
+	}
+	// Wrapper messages stand for nullable scalars and are written as bare
+	// values by protojson.
+	if wrapper, ok := strings.CutPrefix(name, protoKnownTypes); ok && strings.HasPrefix(wrapper, "wrapperspb.") {
+		return protoWellKnown(wrapper)
+	}
+	return nil
+}
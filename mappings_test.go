@@ -0,0 +1,151 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+// MappedMoney is serialized as a decimal string by its MarshalJSON.
+type MappedMoney struct {
+	units int64
+	nanos int32
+}
+
+// MappedCountry is an ISO 3166-1 alpha-2 code.
+type MappedCountry string
+
+// MappedInvoice uses mapped types.
+type MappedInvoice struct {
+	// Total is the amount due.
+	Total    MappedMoney   `json:"total"`
+	Refund   *MappedMoney  `json:"refund,omitempty"`
+	Country  MappedCountry `json:"country"`
+	Previous []MappedMoney `json:"previous"`
+}
+
+func TestRegisterTypeMapping(t *testing.T) {
+	g := NewGenerator(context.Background(),
+		RegisterTypeMapping(reflect.TypeFor[*MappedMoney](), func() *jsonschema.Schema {
+			return &jsonschema.Schema{Type: "string", Pattern: `^-?[0-9]+(\.[0-9]+)?$`}
+		}),
+		RegisterTypeMapping(reflect.TypeFor[MappedCountry](), func() *jsonschema.Schema {
+			return &jsonschema.Schema{Type: "string", Pattern: "^[A-Z]{2}$"}
+		}),
+	)
+	out, err := g.Generate(MappedInvoice{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	money := `{"type":"string","pattern":"^-?[0-9]+(\\.[0-9]+)?$"}`
+	for prop, want := range map[string]string{
+		"total":    `{"type":"string","pattern":"^-?[0-9]+(\\.[0-9]+)?$","description":"Total is the amount due."}`,
+		"refund":   money,
+		"country":  `{"type":"string","pattern":"^[A-Z]{2}$"}`,
+		"previous": `{"items":` + money + `,"type":"array"}`,
+	} {
+		if got := compactJSON(doc.Properties[prop]); got != want {
+			t.Errorf("%s = %s, want %s", prop, got, want)
+		}
+	}
+	if _, ok := doc.Defs["MappedMoney"]; ok {
+		t.Fatalf("mapped type still defined: %s", out)
+	}
+}
+
+// BuiltinMapped uses a standard library type with a built-in mapping.
+type BuiltinMapped struct {
+	Amount json.Number `json:"amount"`
+}
+
+func TestBuiltinMappings(t *testing.T) {
+	out, err := NewGenerator(context.Background()).Generate(BuiltinMapped{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := compactJSON(doc.Properties["amount"]); got != `{"type":"number"}` {
+		t.Fatalf("amount = %s", got)
+	}
+	for name, want := range map[string]string{
+		"github.com/shopspring/decimal.Decimal":                         `{"type":"string","pattern":"^-?[0-9]+(\\.[0-9]+)?$"}`,
+		"github.com/shopspring/decimal.NullDecimal":                     `{"oneOf":[{"type":"string","pattern":"^-?[0-9]+(\\.[0-9]+)?$"},{"type":"null"}]}`,
+		"cloud.google.com/go/civil.Date":                                `{"type":"string","format":"date"}`,
+		"google.golang.org/protobuf/types/known/wrapperspb.StringValue": `{"type":"string"}`,
+		"google.golang.org/protobuf/types/known/wrapperspb.Int64Value":  `{"anyOf":[{"type":"integer"},{"type":"string","pattern":"^-?[0-9]+$"}]}`,
+		"google.golang.org/protobuf/types/known/timestamppb.Timestamp":  `null`,
+		"database/sql.NullString":                                       `null`,
+	} {
+		if got := compactJSON(builtinSchema(name)); got != want {
+			t.Errorf("builtinSchema(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// ByteOctet is a named byte type.
+type ByteOctet byte
+
+// ByteBlob is an opaque binary value.
+type ByteBlob []byte
+
+// ByteUpload carries binary data in its various shapes.
+type ByteUpload struct {
+	Image    []byte      `json:"image" schemator:"mediatype=image/png"`
+	Thumb    *[]byte     `json:"thumb,omitempty"`
+	Blob     ByteBlob    `json:"blob"`
+	Octets   []ByteOctet `json:"octets"`
+	Chunks   [][]byte    `json:"chunks"`
+	Checksum [4]byte     `json:"checksum"`
+}
+
+func TestByteSlices(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(ByteUpload{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]*jsonschema.Schema `json:"properties"`
+		Defs       map[string]*jsonschema.Schema `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	base64 := func(name string, s *jsonschema.Schema) {
+		t.Helper()
+		if s == nil || s.Type != "string" || s.ContentEncoding != "base64" {
+			t.Errorf("%s = %+v, want base64 string", name, s)
+		}
+	}
+	p := doc.Properties
+	base64("image", p["image"])
+	if p["image"].ContentMediaType != "image/png" {
+		t.Errorf("image contentMediaType = %q", p["image"].ContentMediaType)
+	}
+	base64("thumb", p["thumb"])
+	base64("octets", p["octets"])
+	base64("chunks items", p["chunks"].Items)
+	if p["blob"].Ref != "" {
+		base64("ByteBlob", doc.Defs["ByteBlob"])
+	} else {
+		base64("blob", p["blob"])
+	}
+	// encoding/json writes byte arrays as arrays of numbers.
+	if p["checksum"].Type != "array" || p["checksum"].Items.Type != "integer" {
+		t.Errorf("checksum = %+v, want integer array", p["checksum"])
+	}
+}
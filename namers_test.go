@@ -0,0 +1,58 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type NamerAddress struct {
+	Street string
+	Zip    string `json:"zip_code"`
+}
+
+type NamerCustomer struct {
+	FullName string
+	Address  NamerAddress `json:"address"`
+}
+
+func TestNamers(t *testing.T) {
+	out, err := NewGenerator(context.Background(),
+		WithNamer(func(t reflect.Type) string {
+			if t == reflect.TypeFor[NamerAddress]() {
+				return "Address"
+			}
+			return ""
+		}),
+		WithKeyNamer(func(name string) string {
+			return strings.ToLower(name[:1]) + name[1:]
+		}),
+	).Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Ref string `json:"$ref"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+		Defs     map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Properties["address"].Ref; got != "#/$defs/Address" {
+		t.Errorf("address $ref = %q", got)
+	}
+	if !slices.Equal(doc.Required, []string{"fullName", "address"}) {
+		t.Errorf("required = %v", doc.Required)
+	}
+	if got := doc.Defs["Address"].Required; !slices.Equal(got, []string{"street", "zip_code"}) {
+		t.Errorf("Address required = %v", got)
+	}
+}
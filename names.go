@@ -0,0 +1,63 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// NameCollisionPolicy selects what happens when struct types of different
+// packages share a definition name, such as billing.User and auth.User.
+type NameCollisionPolicy int
+
+const (
+	// CollisionSuffix keeps the bare name for the first type reflected and
+	// appends the package name to the others (UserAuth), adding more of
+	// the import path while that is taken too (default).
+	CollisionSuffix NameCollisionPolicy = iota
+	// CollisionError fails generation.
+	CollisionError
+)
+
+func (p NameCollisionPolicy) String() string {
+	switch p {
+	case CollisionSuffix:
+		return "suffix"
+	case CollisionError:
+		return "error"
+	}
+	return fmt.Sprintf("NameCollisionPolicy(%d)", int(p))
+}
+
+// uniqueName returns the definition name of t given its preferred name,
+// which another struct type may have taken already.
+func (rf *reflection) uniqueName(t reflect.Type, name string) string {
+	if t.Kind() != reflect.Struct || name == "" {
+		return name
+	}
+	if taken, ok := rf.names[t]; ok {
+		return taken
+	}
+	unique := name
+	elems := strings.Split(t.PkgPath(), "/")
+	for i := len(elems) - 1; rf.owners[unique] != nil && i >= 0; i-- {
+		unique += identifier(importName(elems[i]))
+	}
+	if unique != name {
+		rf.collisions = append(rf.collisions, fmt.Sprintf("%s and %s are both named %s", rf.owners[name], t, name))
+	}
+	rf.owners[unique] = t
+	rf.names[t] = unique
+	return unique
+}
+
+// checkNameCollisions is the schema pass failing on struct types sharing a
+// definition name, for CollisionError.
+func checkNameCollisions(rf *reflection, s *jsonschema.Schema) error {
+	if len(rf.collisions) > 0 {
+		return fmt.Errorf("definition names collide: %s", strings.Join(rf.collisions, "; "))
+	}
+	return nil
+}
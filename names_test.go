@@ -0,0 +1,49 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"go/token"
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+type NamesSpan struct {
+	Start token.Position   `json:"start"`
+	End   scanner.Position `json:"end"`
+	Next  token.Position   `json:"next"`
+}
+
+func TestNameCollisions(t *testing.T) {
+	out, err := NewGenerator(context.Background()).Generate(NamesSpan{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Ref string `json:"$ref"`
+		} `json:"properties"`
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for prop, want := range map[string]string{
+		"start": "#/$defs/Position",
+		"end":   "#/$defs/PositionScanner",
+		"next":  "#/$defs/Position",
+	} {
+		if got := doc.Properties[prop].Ref; got != want {
+			t.Errorf("%s: $ref = %q, want %q", prop, got, want)
+		}
+	}
+	if len(doc.Defs) != 2 {
+		t.Errorf("$defs = %v", doc.Defs)
+	}
+
+	_, err = NewGenerator(context.Background(), WithNameCollisions(CollisionError)).Generate(NamesSpan{})
+	if err == nil || !strings.Contains(err.Error(), "token.Position and scanner.Position are both named Position") {
+		t.Fatalf("CollisionError: error = %v", err)
+	}
+}
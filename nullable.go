@@ -0,0 +1,61 @@
+package schemator
+
+import (
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// applyNullablePointers is the schema pass letting pointer fields, and
+// pointer elements of slices and maps, be null, as encoding/json writes nil
+// pointers. Schemas become the {oneOf: [T, {type: null}]} union the
+// jsonschema "nullable" tag produces. The database/sql Null types are left
+// alone: they have no MarshalJSON method, so encoding/json writes them as
+// {"String": ..., "Valid": ...} objects rather than as null.
+func applyNullablePointers(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			prop, _ := fv.parent.Properties.Get(fv.name)
+			if fv.field.Type.Kind() == reflect.Pointer {
+				prop = nullable(prop)
+				fv.parent.Properties.Set(fv.name, prop)
+			}
+			nullableElements(fv.field.Type, unwrapNullable(prop))
+			return nil
+		})
+	})
+}
+
+// nullableElements makes the schemas of pointer elements of t within s
+// nullable.
+func nullableElements(t reflect.Type, s *jsonschema.Schema) {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) || s.Ref != "" {
+		return
+	}
+	var elem **jsonschema.Schema
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem = &s.Items
+	case reflect.Map:
+		elem = &s.AdditionalProperties
+	default:
+		return
+	}
+	if *elem == nil || isBooleanSchema(*elem) {
+		return
+	}
+	if t.Elem().Kind() == reflect.Pointer {
+		*elem = nullable(*elem)
+	}
+	nullableElements(t.Elem(), unwrapNullable(*elem))
+}
+
+// nullable returns s wrapped in a union with null, or s if it already
+// accepts null.
+func nullable(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil || isBooleanSchema(s) || unwrapNullable(s) != s || s.Type == "null" || acceptsAnything(s) {
+		return s
+	}
+	return &jsonschema.Schema{OneOf: []*jsonschema.Schema{s, {Type: "null"}}}
+}
@@ -0,0 +1,91 @@
+package schemator
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+// NullableOwner is referenced through pointers.
+type NullableOwner struct {
+	Name string `json:"name"`
+}
+
+// NullableRecord has pointer fields.
+type NullableRecord struct {
+	Name    string                    `json:"name"`
+	Nick    *string                   `json:"nick"`
+	Owner   *NullableOwner            `json:"owner"`
+	Tags    []*string                 `json:"tags"`
+	Members map[string]*NullableOwner `json:"members"`
+	Grid    [][]*int                  `json:"grid"`
+	Note    *string                   `json:"note" jsonschema:"nullable"`
+}
+
+func TestNullablePointers(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithNullablePointers()).Generate(NullableRecord{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"name":    `{"type":"string"}`,
+		"nick":    `{"oneOf":[{"type":"string"},{"type":"null"}]}`,
+		"owner":   `{"oneOf":[{"$ref":"#/$defs/NullableOwner"},{"type":"null"}]}`,
+		"tags":    `{"items":{"oneOf":[{"type":"string"},{"type":"null"}]},"type":"array"}`,
+		"members": `{"additionalProperties":{"oneOf":[{"$ref":"#/$defs/NullableOwner"},{"type":"null"}]},"type":"object"}`,
+		"grid":    `{"items":{"items":{"oneOf":[{"type":"integer"},{"type":"null"}]},"type":"array"},"type":"array"}`,
+		"note":    `{"oneOf":[{"type":"string"},{"type":"null"}]}`,
+	} {
+		if got := compactJSON(doc.Properties[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// NullableColumns has database/sql Null fields.
+type NullableColumns struct {
+	Name  sql.NullString `json:"name"`
+	Count sql.Null[int]  `json:"count"`
+}
+
+func TestNullablePointersSQLNull(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithNullablePointers()).Generate(NullableColumns{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	// They are described as the objects encoding/json writes.
+	for name, want := range map[string]string{
+		"name":  `{"$ref":"#/$defs/NullString"}`,
+		"count": `{"$ref":"#/$defs/NullOfInt"}`,
+	} {
+		if got := compactJSON(doc.Properties[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if got := doc.Defs["NullString"].Required; len(got) != 2 || got[0] != "String" || got[1] != "Valid" {
+		t.Errorf("NullString required = %v", got)
+	}
+	data, err := json.Marshal(NullableColumns{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":{"String":"","Valid":false},"count":{"V":0,"Valid":false}}`; string(data) != want {
+		t.Errorf("encoding/json writes %s, want %s", data, want)
+	}
+}
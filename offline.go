@@ -0,0 +1,55 @@
+package schemator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// loaderConfigKey is the context key of the loaderConfig packages are loaded
+// with.
+type loaderConfigKey struct{}
+
+// loaderConfig configures how package directories are looked up. Lookups
+// run deep below the generator, so the configuration travels in the context.
+type loaderConfig struct {
+	// offline forbids network access.
+	offline bool
+	// timeout bounds a single run of the go command, if positive.
+	timeout time.Duration
+}
+
+func withLoaderConfig(ctx context.Context, cfg loaderConfig) context.Context {
+	return context.WithValue(ctx, loaderConfigKey{}, cfg)
+}
+
+func loaderConfigFrom(ctx context.Context) loaderConfig {
+	cfg, _ := ctx.Value(loaderConfigKey{}).(loaderConfig)
+	return cfg
+}
+
+// loaderEnv returns the environment the go command loads packages in dir
+// with, or nil for that of the process. Offline, module and toolchain
+// downloads are disabled, and -mod=mod keeps go.mod and go.sum drift from
+// failing the load unless dir is in a workspace or vendors, where the go
+// command rejects it.
+func (cfg loaderConfig) loaderEnv(dir string) []string {
+	if !cfg.offline {
+		return nil
+	}
+	goflags := os.Getenv("GOFLAGS")
+	if !strings.Contains(goflags, "mod=") && !inWorkspace(dir) {
+		if vendor, err := vendorDir(dir); err == nil && vendor == "" {
+			goflags = strings.TrimSpace(goflags + " -mod=mod")
+		}
+	}
+	return append(os.Environ(), "GOPROXY=off", "GOTOOLCHAIN=local", "GOFLAGS="+goflags)
+}
+
+// offlineError explains that the package importPath could not be loaded
+// because its module is not available without network access.
+func offlineError(importPath string, err error) error {
+	return fmt.Errorf("%s is not available offline, download its module with go mod download first: %w", importPath, err)
+}
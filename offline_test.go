@@ -0,0 +1,41 @@
+package schemator
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOffline(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n\nrequire example.com/unavailable v1.0.0\n")
+	writeFile(t, filepath.Join(dir, "app.go"), "package app\n")
+
+	env := loaderConfig{offline: true}.loaderEnv(dir)
+	for _, want := range []string{"GOPROXY=off", "GOTOOLCHAIN=local", "GOFLAGS=-mod=mod"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("loaderEnv() lacks %s", want)
+		}
+	}
+	if env := (loaderConfig{}).loaderEnv(dir); env != nil {
+		t.Errorf("loaderEnv() online = %v, want the process environment", env)
+	}
+
+	ctx := withLoaderConfig(context.Background(), loaderConfig{offline: true})
+	_, _, err := lookupPackageDir(ctx, "example.com/unavailable/pkg", dir)
+	if err == nil || !strings.Contains(err.Error(), "example.com/unavailable/pkg is not available offline") {
+		t.Errorf("lookupPackageDir() offline error = %v", err)
+	}
+	// The standard library and the packages of the main module need no
+	// network.
+	if _, _, err := lookupPackageDir(ctx, "net/http", dir); err != nil {
+		t.Errorf("lookupPackageDir(net/http) offline error = %v", err)
+	}
+	if got, _, err := lookupPackageDir(ctx, "example.com/app", dir); err != nil || got != dir {
+		t.Errorf("lookupPackageDir(example.com/app) offline = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,186 @@
+package schemator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// openAPI30Refs is where OpenAPI 3.0 documents keep named schemas.
+const openAPI30Refs = "#/components/schemas/"
+
+// openAPI30Document is the output of GenerateOpenAPI30, ready to be merged
+// into an OpenAPI 3.0 description.
+type openAPI30Document struct {
+	Components struct {
+		Schemas map[string]*jsonschema.Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+func (g *generator) GenerateOpenAPI30(model any) (SchemaBytes, error) {
+	name := toString(model)
+	if name == "" {
+		return nil, fmt.Errorf("%T has no name to list it under in components/schemas", model)
+	}
+	s, err := g.reflect(model)
+	if err != nil {
+		return nil, err
+	}
+	s = namedRoot(s, name)
+	defs := s.Definitions
+	s.Definitions = nil
+	if _, exists := defs[name]; exists {
+		return nil, fmt.Errorf("%s collides with a definition of the same name", name)
+	}
+	var doc openAPI30Document
+	doc.Components.Schemas = make(map[string]*jsonschema.Schema, len(defs)+1)
+	doc.Components.Schemas[name] = toOpenAPI30(s)
+	for defName, def := range defs {
+		doc.Components.Schemas[defName] = toOpenAPI30(def)
+	}
+	return g.marshalJSON(doc)
+}
+
+// toOpenAPI30 rewrites a reflected schema into an OpenAPI 3.0 Schema Object:
+// nullable unions become `nullable: true`, references point into
+// components/schemas, exclusive bounds become booleans and keywords OpenAPI
+// 3.0 does not know are dropped.
+func toOpenAPI30(s *jsonschema.Schema) *jsonschema.Schema {
+	switch s {
+	case nil:
+		return nil
+	case jsonschema.TrueSchema:
+		return anySchema()
+	case jsonschema.FalseSchema:
+		return &jsonschema.Schema{Not: anySchema()}
+	}
+	if nonNull, ok := nullableUnion(s); ok {
+		n := copySchemaNode(nonNull)
+		if nonNull.Ref != "" {
+			// nullable next to $ref would be ignored.
+			n = &jsonschema.Schema{AllOf: []*jsonschema.Schema{nonNull}}
+		}
+		if n.Description == "" {
+			n.Description = s.Description
+		}
+		n = toOpenAPI30(n)
+		setExtra(n, "nullable", true)
+		return n
+	}
+	if s.Ref != "" {
+		ref := &jsonschema.Schema{Ref: openAPI30Ref(s.Ref)}
+		if compactJSON(&jsonschema.Schema{Ref: s.Ref}) == compactJSON(s) {
+			return ref
+		}
+		// Keywords next to $ref are ignored in OpenAPI 3.0; keep them by
+		// moving the reference into allOf.
+		s.Ref = ""
+		s.AllOf = append([]*jsonschema.Schema{ref}, s.AllOf...)
+	}
+	if s.Type == "null" {
+		s.Type = ""
+		s.Enum = []any{nil}
+		setExtra(s, "nullable", true)
+	}
+	s.Version = ""
+	s.ID = ""
+	s.Anchor = ""
+	s.DynamicRef = ""
+	s.Comments = ""
+	s.Definitions = nil
+	s.If, s.Then, s.Else = nil, nil, nil
+	s.DependentSchemas = nil
+	s.DependentRequired = nil
+	s.PrefixItems = nil
+	s.Contains, s.MinContains, s.MaxContains = nil, nil, nil
+	s.PropertyNames = nil
+	s.ContentSchema = nil
+	s.ContentMediaType = ""
+	if s.ContentEncoding == "base64" && s.Format == "" {
+		s.Format = "byte"
+	}
+	s.ContentEncoding = ""
+	if s.Const != nil {
+		s.Enum = []any{s.Const}
+		s.Const = nil
+	}
+	if len(s.Examples) > 0 {
+		setExtra(s, "example", s.Examples[0])
+		s.Examples = nil
+	}
+	// OpenAPI 3.0 spells exclusive bounds as booleans next to the bound.
+	if s.ExclusiveMinimum != "" {
+		s.Minimum, s.ExclusiveMinimum = s.ExclusiveMinimum, ""
+		setExtra(s, "exclusiveMinimum", true)
+	}
+	if s.ExclusiveMaximum != "" {
+		s.Maximum, s.ExclusiveMaximum = s.ExclusiveMaximum, ""
+		setExtra(s, "exclusiveMaximum", true)
+	}
+	if len(s.PatternProperties) > 0 && s.AdditionalProperties == nil {
+		for _, v := range s.PatternProperties {
+			s.AdditionalProperties = v
+			break
+		}
+	}
+	s.PatternProperties = nil
+	for k, v := range s.Extras {
+		switch {
+		case k == "discriminator":
+			s.Extras[k] = openAPI30Discriminator(v)
+		case strings.HasPrefix(k, "x-"), k == "nullable", k == "example", k == "exclusiveMinimum", k == "exclusiveMaximum":
+		default:
+			delete(s.Extras, k)
+		}
+	}
+	ap := s.AdditionalProperties
+	_ = mapSubschemas(s, func(child *jsonschema.Schema) (*jsonschema.Schema, error) {
+		return toOpenAPI30(child), nil
+	})
+	if isBooleanSchema(ap) {
+		// additionalProperties is the one place OpenAPI 3.0 takes a boolean.
+		s.AdditionalProperties = ap
+	}
+	if s.Extras == nil {
+		// An empty schema would marshal as true.
+		s.Extras = map[string]any{}
+	}
+	return s
+}
+
+// anySchema returns a schema accepting any value that marshals as {}
+// rather than true, which OpenAPI 3.0 does not accept as a schema: the
+// Schema type marshals itself as true only if it equals the zero Schema.
+func anySchema() *jsonschema.Schema {
+	return &jsonschema.Schema{Extras: map[string]any{}}
+}
+
+// openAPI30Ref rewrites a reference to a definition into components/schemas.
+func openAPI30Ref(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		return openAPI30Refs + name
+	}
+	return ref
+}
+
+// openAPI30Discriminator rewrites the mapping of a discriminator added by
+// WithDiscriminator into components/schemas.
+func openAPI30Discriminator(v any) any {
+	d, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	mapping, ok := d["mapping"].(map[string]any)
+	if !ok {
+		return v
+	}
+	rewritten := make(map[string]any, len(mapping))
+	for tag, ref := range mapping {
+		if r, ok := ref.(string); ok {
+			ref = openAPI30Ref(r)
+		}
+		rewritten[tag] = ref
+	}
+	return map[string]any{"propertyName": d["propertyName"], "mapping": rewritten}
+}
@@ -0,0 +1,62 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// OpenAPIOwner is referenced by OpenAPIRecord.
+type OpenAPIOwner struct {
+	Name string `json:"name"`
+}
+
+// OpenAPIRecord uses keywords OpenAPI 3.0 spells differently.
+type OpenAPIRecord struct {
+	Nick  *string           `json:"nick"`
+	Owner *OpenAPIOwner     `json:"owner"`
+	Data  []byte            `json:"data" schemator:"mediatype=image/png"`
+	Score float64           `json:"score" validate:"gt=0"`
+	Code  string            `json:"code" example:"AB12"`
+	Attrs map[string]string `json:"attrs"`
+	Raw   any               `json:"raw"`
+}
+
+func TestGenerateOpenAPI30(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithNullablePointers()).GenerateOpenAPI30(OpenAPIRecord{})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPI30() error = %v", err)
+	}
+	for _, forbidden := range []string{`"$defs"`, `"$schema"`, `"$id"`, `"const"`, `"examples"`, `"contentEncoding"`, `"type": "null"`, `#/$defs/`} {
+		if strings.Contains(string(out), forbidden) {
+			t.Errorf("output contains %s:\n%s", forbidden, out)
+		}
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Components.Schemas["OpenAPIOwner"]; !ok {
+		t.Fatalf("OpenAPIOwner missing from components:\n%s", out)
+	}
+	props := doc.Components.Schemas["OpenAPIRecord"].Properties
+	for name, want := range map[string]string{
+		"nick":  `{"type":"string","nullable":true}`,
+		"owner": `{"allOf":[{"$ref":"#/components/schemas/OpenAPIOwner"}],"nullable":true}`,
+		"data":  `{"type":"string","format":"byte"}`,
+		"score": `{"type":"number","minimum":0,"exclusiveMinimum":true}`,
+		"code":  `{"type":"string","example":"AB12"}`,
+		"raw":   `{}`,
+	} {
+		if got := compactJSON(props[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,615 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures optional behaviour of a Generator created with
+// NewGenerator.
+type Option func(*generator)
+
+// NewGenerator returns a Generator configured by opts. New(ctx, files, ips...)
+// is equivalent to
+//
+//	NewGenerator(ctx, WithFilesThatMustExist(files...), WithImportPaths(ips...))
+func NewGenerator(ctx context.Context, opts ...Option) Generator {
+	g := &generator{
+		ctx:             ctx,
+		indent:          defaultIndent,
+		trailingNewline: true,
+		perms:           defaultPermissions,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(g)
+		}
+	}
+	g.comments = newCommentCache(buildContext(g.goos, g.goarch, g.buildTags))
+	return g
+}
+
+// WithFilesThatMustExist makes generation fail unless every file in files
+// exists.
+func WithFilesThatMustExist(files ...string) Option {
+	return func(g *generator) {
+		g.filesThatMustExist = append(g.filesThatMustExist, files...)
+	}
+}
+
+// WithImportPaths adds import paths to scrape Go comments from (see New).
+func WithImportPaths(importPaths ...ImportPath) Option {
+	return func(g *generator) {
+		g.importPaths = append(g.importPaths, importPaths...)
+	}
+}
+
+// WithSuppressionFile points Verify at a suppression file listing accepted
+// drift (see LoadSuppressions).
+func WithSuppressionFile(path string) Option {
+	return func(g *generator) {
+		g.suppressionFile = path
+	}
+}
+
+// WithVersion makes WriteSchemas write versioned filenames
+// (<Type>.<version>.schema.json) and record every file in the manifest of the
+// output directory together with tags (e.g. "release"). GC uses the manifest
+// to prune old versions.
+func WithVersion(version string, tags ...string) Option {
+	return func(g *generator) {
+		g.version = version
+		g.versionTags = tags
+	}
+}
+
+// WithManifest makes WriteSchemas record every file in the manifest of the
+// output directory, with its SHA-256 checksum and the package and module
+// version of its model, even without WithVersion. VerifyManifest checks the
+// files against the checksums.
+func WithManifest() Option {
+	return func(g *generator) {
+		g.manifest = true
+	}
+}
+
+// WithPrune makes WriteSchemas and WriteAll remove the JSON schema files in
+// the output directory they did not write, such as those of models no longer
+// passed (see Prune).
+func WithPrune() Option {
+	return func(g *generator) {
+		g.pruneStale = true
+	}
+}
+
+// WithPruneDryRun is WithPrune logging the files it would remove instead of
+// removing them.
+func WithPruneDryRun() Option {
+	return func(g *generator) {
+		g.pruneStale = true
+		g.pruneDryRun = true
+	}
+}
+
+// WithDryRun makes every write generate as usual but leave the disk alone:
+// each file that would be created, updated, left unchanged or pruned is
+// passed to report instead, or logged if report is nil.
+func WithDryRun(report func(PlannedWrite)) Option {
+	return func(g *generator) {
+		g.dryRun = true
+		g.dryRunReport = report
+	}
+}
+
+// WithConcurrency generates the schemas of the models passed to
+// WriteSchemas, WriteNamedSchemas, WriteSchemasFS, WriteSchemasArchive,
+// GenerateAll and Verify up to n at a time. Files are still written in the
+// order of the models, and the errors of all failing models are returned
+// together. n of 0 or 1 generates one model after another, stopping at the
+// first error (default).
+func WithConcurrency(n int) Option {
+	return func(g *generator) {
+		g.concurrency = n
+	}
+}
+
+// WithPackageCache stores the package directories it looks up in the file
+// name and reads them back on later runs, so repeated generation in CI or
+// go:generate loops skips loading packages it has seen. Within a process,
+// lookups are cached regardless. Entries whose directory no longer exists, or
+// whose go.mod or go.work file changed since they were stored, are looked up
+// again. The file is only written when the lookups changed, and never under
+// WithDryRun.
+func WithPackageCache(name string) Option {
+	return func(g *generator) {
+		g.packageCache = name
+	}
+}
+
+// WithBuildConstraints reads comments only from the source files built for
+// goos and goarch with tags, so fields declared in files such as
+// types_linux.go are documented and the declarations of other platforms do
+// not override them. Empty goos and goarch keep those of the running binary,
+// and tags add to the build tags it was built with (default).
+func WithBuildConstraints(goos, goarch string, tags ...string) Option {
+	return func(g *generator) {
+		g.goos = goos
+		g.goarch = goarch
+		g.buildTags = append(g.buildTags, tags...)
+	}
+}
+
+// WithOffline guarantees generation makes no network access: packages are
+// loaded with GOPROXY=off and GOTOOLCHAIN=local, and a package whose module
+// is not in the module cache, a workspace or vendor directory fails with an
+// error naming it instead of being downloaded.
+func WithOffline() Option {
+	return func(g *generator) {
+		g.offline = true
+	}
+}
+
+// WithSubprocessTimeout fails generation when a single run of the go
+// command, which loads the packages of the models, takes longer than d, so
+// a hung toolchain cannot stall CI. Zero means no timeout (default).
+// Cancelling the context passed to NewGenerator stops generation and
+// writing at any point regardless.
+func WithSubprocessTimeout(d time.Duration) Option {
+	return func(g *generator) {
+		g.subprocessTimeout = d
+	}
+}
+
+// WithContinueOnError makes WriteSchemas, WriteNamedSchemas, WriteSchemasFS,
+// WriteSchemasArchive, GenerateAll and Verify go on past models that fail to
+// generate: the schemas of the other models are still written, returned or
+// verified, and the errors of all failing models are joined, each prefixed
+// with the name of its model, in the order of the models. The files of
+// failing models are kept as they are and not pruned. By default the first
+// failing model stops generation.
+func WithContinueOnError() Option {
+	return func(g *generator) {
+		g.continueOnError = true
+	}
+}
+
+// WithProgress makes fn receive a ProgressEvent when generation of each
+// model starts and finishes, with how long it took, and for every file
+// written, with its size, e.g. to show progress of a large schema set. Events
+// are passed one at a time, also under WithConcurrency.
+func WithProgress(fn func(ProgressEvent)) Option {
+	return func(g *generator) {
+		g.progress = fn
+	}
+}
+
+// WithTracerProvider records OpenTelemetry spans with tp for the phases of
+// generation: resolving import paths, loading packages with the go command,
+// parsing comments, reflecting each model and writing each file. The spans
+// are children of the span in the context passed to NewGenerator. Nothing
+// is traced by default, and a nil tp turns tracing off again.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(g *generator) {
+		if tp == nil {
+			g.tracer = nil
+			return
+		}
+		g.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// WithStatusFile makes Verify write a Status summary as JSON to path, whether
+// or not verification succeeds.
+func WithStatusFile(path string) Option {
+	return func(g *generator) {
+		g.statusFile = path
+	}
+}
+
+// WithBadgeFile makes Verify write a shields.io endpoint Badge as JSON to
+// path, whether or not verification succeeds.
+func WithBadgeFile(path string) Option {
+	return func(g *generator) {
+		g.badgeFile = path
+	}
+}
+
+// WithInferredFormats applies the formats and constraints suggested by
+// SuggestConstraints to generated schemas.
+func WithInferredFormats() Option {
+	return func(g *generator) {
+		g.inferFormats = true
+	}
+}
+
+// WithInternalTypes sets how fields of unexported types, or of types in
+// internal packages the model's package cannot import, are handled (see
+// InternalTypePolicy).
+func WithInternalTypes(policy InternalTypePolicy) Option {
+	return func(g *generator) {
+		g.internalTypes = policy
+	}
+}
+
+// WithDeprecationReasons moves the "Deprecated:" paragraph of deprecated
+// types and fields out of the description into an x-deprecated-reason
+// extension.
+func WithDeprecationReasons() Option {
+	return func(g *generator) {
+		g.deprecationReasons = true
+	}
+}
+
+// WithOrderExtension sets an x-order extension on every property holding its
+// position in the declaration order of the Go fields, for documentation
+// generators that sort properties by name and with PropertyAlphabeticalOrder.
+func WithOrderExtension() Option {
+	return func(g *generator) {
+		g.orderExtension = true
+	}
+}
+
+// WithPropertyOrder sets the order properties and required properties are
+// written in (see PropertyOrder).
+func WithPropertyOrder(order PropertyOrder) Option {
+	return func(g *generator) {
+		g.propertyOrder = order
+	}
+}
+
+// WithIndent sets the string indenting each level of generated JSON
+// documents, two spaces by default.
+func WithIndent(indent string) Option {
+	return func(g *generator) {
+		g.indent = indent
+		g.compact = false
+	}
+}
+
+// WithCompact writes generated JSON documents on a single line without
+// insignificant whitespace.
+func WithCompact() Option {
+	return func(g *generator) {
+		g.compact = true
+	}
+}
+
+// WithTrailingNewline sets whether written files end with a newline, which
+// they do by default. Files never end with more than one.
+func WithTrailingNewline(trailingNewline bool) Option {
+	return func(g *generator) {
+		g.trailingNewline = trailingNewline
+	}
+}
+
+// WithFileMode sets the permissions of written files, 0644 by default.
+func WithFileMode(mode os.FileMode) Option {
+	return func(g *generator) {
+		g.perms.file = mode.Perm()
+	}
+}
+
+// WithDirMode sets the permissions of the directories created for written
+// files, 0755 by default. The umask of the process still applies.
+func WithDirMode(mode os.FileMode) Option {
+	return func(g *generator) {
+		g.perms.dir = mode.Perm()
+	}
+}
+
+// WithUmask sets the permissions of written files to 0666 and of created
+// directories to 0777 less the bits in mask, e.g. 0077 for files only their
+// owner can read.
+func WithUmask(mask os.FileMode) Option {
+	return func(g *generator) {
+		g.perms = umaskPermissions(mask)
+	}
+}
+
+// WithFilenameFunc names the JSON schema files of WriteSchemas, WriteAll,
+// WriteSchemasFS, GenerateAll and Verify: fn returns the filename of the
+// schema of model, including any extension and version, or "" to keep the
+// default <Type>[.<version>].schema.json. FilenameStrategy implements common
+// conventions.
+func WithFilenameFunc(fn func(model any) string) Option {
+	return func(g *generator) {
+		g.filenameFunc = fn
+	}
+}
+
+// WithFilenameCollisions sets what happens when models of different packages
+// are written to the same file (see FilenameCollisionPolicy). By default the
+// write fails.
+func WithFilenameCollisions(policy FilenameCollisionPolicy) Option {
+	return func(g *generator) {
+		g.filenameCollisions = policy
+	}
+}
+
+// WithLayout sets where in the output directory files are written (see
+// OutputLayout).
+func WithLayout(layout OutputLayout) Option {
+	return func(g *generator) {
+		g.layout = layout
+	}
+}
+
+// WithCommentFormat sets how doc comments are rendered into descriptions
+// (see CommentFormat).
+func WithCommentFormat(format CommentFormat) Option {
+	return func(g *generator) {
+		g.commentFormat = format
+	}
+}
+
+// WithNamedSchema registers the schema of model as name for the
+// "schemator:prefix" and "schemator:values" directives describing the values
+// of free-form map fields.
+func WithNamedSchema(name string, model any) Option {
+	return func(g *generator) {
+		if g.namedSchemas == nil {
+			g.namedSchemas = make(map[string]any)
+		}
+		g.namedSchemas[name] = model
+	}
+}
+
+// WithIndex makes WriteSchemas and WriteAll finish by writing a README.md
+// table of contents into the output directory (see WriteIndex).
+func WithIndex() Option {
+	return func(g *generator) {
+		g.writeIndex = true
+	}
+}
+
+// WithMaxWarnings makes generation of a model fail with a
+// *WarningBudgetError when it produces more than n warnings (see Warning).
+// Lowering n over time keeps schema quality from regressing unnoticed; 0
+// fails on any warning.
+func WithMaxWarnings(n int) Option {
+	return func(g *generator) {
+		g.maxWarnings = n
+		g.limitWarnings = n >= 0
+	}
+}
+
+// WithWarningHandler makes fn receive every Warning found while generating,
+// in addition to it being logged.
+func WithWarningHandler(fn func(Warning)) Option {
+	return func(g *generator) {
+		g.warningHandler = fn
+	}
+}
+
+// WithStripFieldNames removes the leading "<Field> is" or "<Field> are" from
+// field descriptions, so "Port is the listen port." reads "The listen port.".
+func WithStripFieldNames() Option {
+	return func(g *generator) {
+		g.stripFieldNames = true
+	}
+}
+
+// WithDurationFormat sets how time.Duration fields are described (see
+// DurationFormat). The default, DurationString, suits types that encode
+// durations as "1h30m"; use DurationNanoseconds where encoding/json writes
+// time.Duration as is.
+func WithDurationFormat(format DurationFormat) Option {
+	return func(g *generator) {
+		g.durationFormat = format
+	}
+}
+
+// WithInt64Format sets how int64 and uint64 fields are described (see
+// Int64Format), for APIs consumed from JavaScript. It only changes the
+// schema: fields described as strings must also be encoded as strings, for
+// example with `json:",string"`.
+func WithInt64Format(format Int64Format) Option {
+	return func(g *generator) {
+		g.int64Format = format
+	}
+}
+
+// WithIntegerBounds adds the range of their Go type as minimum and maximum
+// to integer fields: 0..255 for a uint8, -32768..32767 for an int16 and so
+// on. int and uint are taken to be 64 bits wide.
+func WithIntegerBounds() Option {
+	return func(g *generator) {
+		g.integerBounds = true
+	}
+}
+
+// WithNullablePointers lets pointer fields, and pointer elements of slices
+// and maps, be null in addition to their type, since encoding/json writes
+// nil pointers as null. The database/sql Null types are out of scope: they
+// are written as objects, never as null, and described as such.
+func WithNullablePointers() Option {
+	return func(g *generator) {
+		g.nullablePointers = true
+	}
+}
+
+// WithRequiredPolicy sets which properties are required (see
+// RequiredPolicy). The default, RequiredWithoutOmitempty, requires every
+// field without omitempty.
+func WithRequiredPolicy(policy RequiredPolicy) Option {
+	return func(g *generator) {
+		g.requiredPolicy = policy
+	}
+}
+
+// WithYAMLNames takes property names and omitempty from `yaml:"..."` tags
+// instead of json tags, for configuration structs only ever read from YAML.
+// Fields without a yaml tag name are named like yaml.v3 does, by their field
+// name in lower case.
+func WithYAMLNames() Option {
+	return func(g *generator) {
+		g.nameTag = yamlTag
+	}
+}
+
+// WithBSONNames takes property names and omitempty from `bson:"..."` tags
+// instead of json tags, so schemas of MongoDB documents match the stored
+// field names, such as "_id". Fields without a bson tag name are named like
+// the MongoDB driver does, by their field name in lower case.
+func WithBSONNames() Option {
+	return func(g *generator) {
+		g.nameTag = bsonTag
+	}
+}
+
+// WithEmbedPolicy sets how the fields of embedded structs are described (see
+// EmbedPolicy). The default, EmbedFlatten, matches encoding/json.
+func WithEmbedPolicy(policy EmbedPolicy) Option {
+	return func(g *generator) {
+		g.embedPolicy = policy
+	}
+}
+
+// WithAliasPolicy sets how fields declared with a type alias are described
+// (see AliasPolicy). The default, AliasInline, describes them like fields of
+// the alias target.
+func WithAliasPolicy(policy AliasPolicy) Option {
+	return func(g *generator) {
+		g.aliasPolicy = policy
+	}
+}
+
+// WithRecursiveRoot describes a root type that refers to itself, such as a
+// tree node, as a definition named name that the document root refers to,
+// instead of expanding it at the root and pointing its self-references at
+// the root ("#"). An empty name uses the definition name of the type.
+func WithRecursiveRoot(name string) Option {
+	return func(g *generator) {
+		g.recursiveRoot = true
+		g.recursiveRootName = name
+	}
+}
+
+// WithMaxDepth stops describing types n definitions away from the root: the
+// properties referring to deeper definitions accept any value and those
+// definitions are left out. It keeps schemas of deeply nested third-party
+// types, such as Kubernetes object graphs, at a manageable size. Zero, the
+// default, means no limit.
+func WithMaxDepth(n int) Option {
+	return func(g *generator) {
+		g.maxDepth = n
+	}
+}
+
+// WithAnyPolicy sets how fields of interface types without a schema, such
+// as any, are described (see AnyPolicy). The default, AnyPermissive,
+// accepts any value. A `schemator:"any=object"` tag overrides the policy
+// for one field.
+func WithAnyPolicy(policy AnyPolicy) Option {
+	return func(g *generator) {
+		g.anyPolicy = policy
+	}
+}
+
+// WithStrict turns omissions that schemator otherwise only warns about into
+// errors: fields of kinds encoding/json cannot encode, such as channels,
+// functions and unsafe.Pointer, fail generation with the field path instead
+// of being left out of the schema, and models no filename can be derived for,
+// such as anonymous structs, fail WriteSchemas and friends instead of being
+// skipped.
+func WithStrict() Option {
+	return func(g *generator) {
+		g.strict = true
+	}
+}
+
+// WithNamer names the definitions of types: fn returns the name under
+// $defs for a type, or "" to keep the default, the type name with generic
+// type arguments spelled out. The names also apply to definitions schemator
+// adds itself, such as those of embedded structs and union members.
+func WithNamer(fn func(reflect.Type) string) Option {
+	return func(g *generator) {
+		g.namer = fn
+	}
+}
+
+// WithKeyNamer renames every property: fn is given the name from the struct
+// tag, or the field name for fields without one, and returns the property
+// name. Use it to force a casing such as lowerCamelCase for untagged fields
+// across all models.
+func WithKeyNamer(fn func(string) string) Option {
+	return func(g *generator) {
+		g.keyNamer = fn
+	}
+}
+
+// WithNameCollisions sets what happens when struct types of different
+// packages share a definition name (see NameCollisionPolicy). By default the
+// later ones get their package name appended.
+func WithNameCollisions(policy NameCollisionPolicy) Option {
+	return func(g *generator) {
+		g.nameCollisions = policy
+	}
+}
+
+// WithInlineRefs replaces every reference to a definition with a copy of
+// the definition, producing a standalone schema without $defs, as some older
+// validators and AWS API Gateway require. Recursive types cannot be inlined
+// and fail generation.
+func WithInlineRefs() Option {
+	return func(g *generator) {
+		g.inlineRefs = true
+	}
+}
+
+// WithRefMode sets how schemas refer to definitions (see RefMode). The
+// default, RefPointer, uses JSON pointers into $defs.
+func WithRefMode(mode RefMode) Option {
+	return func(g *generator) {
+		g.refMode = mode
+	}
+}
+
+// WithOverlays applies hand-maintained corrections from dir to the JSON
+// schemas of Generate and the files written from it, so they survive
+// regeneration. For a model named Order, Order.merge.json is applied as an
+// RFC 7386 merge patch and then Order.patch.json as an RFC 6902 JSON patch;
+// either file may be missing.
+func WithOverlays(dir string) Option {
+	return func(g *generator) {
+		g.overlayDir = dir
+	}
+}
+
+// WithSchemaOverrides sets the title, description and extra keywords of the
+// schemas of the types in overrides (see SchemaOverrides). Later calls add to
+// the overrides of earlier ones.
+func WithSchemaOverrides(overrides SchemaOverrides) Option {
+	return func(g *generator) {
+		if g.overrides == nil {
+			g.overrides = make(SchemaOverrides)
+		}
+		for k, v := range overrides {
+			g.overrides[k] = v
+		}
+	}
+}
+
+// WithGoExtensions records the Go type of the root schema and every
+// definition in x-go-type, x-go-package and x-go-name, and the Go field name
+// of every property in x-go-name, so Go code generators such as oapi-codegen
+// can map the schemas back to the original types.
+func WithGoExtensions() Option {
+	return func(g *generator) {
+		g.goExtensions = true
+	}
+}
+
+// WithProvenance adds a $comment to every generated JSON schema naming the
+// schemator version, the module version of the package declaring the model
+// and the settings differing from the defaults, to trace which binary
+// produced a schema artifact.
+func WithProvenance() Option {
+	return func(g *generator) {
+		g.provenance = true
+	}
+}
@@ -0,0 +1,110 @@
+package schemator
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/invopop/jsonschema"
+)
+
+// orderExtension records the position of a property, for tools that sort
+// properties alphabetically.
+const orderExtension = "x-order"
+
+// PropertyOrder selects the order properties and required properties are
+// written in. Definitions in $defs and the keywords of every schema are
+// always written in alphabetical order.
+type PropertyOrder int
+
+const (
+	// PropertyDeclarationOrder writes properties in the declaration order of
+	// the Go fields, and required properties in the order of properties
+	// (default).
+	PropertyDeclarationOrder PropertyOrder = iota
+	// PropertyAlphabeticalOrder writes properties and required properties
+	// sorted by name.
+	PropertyAlphabeticalOrder
+)
+
+func (o PropertyOrder) String() string {
+	switch o {
+	case PropertyDeclarationOrder:
+		return "declaration"
+	case PropertyAlphabeticalOrder:
+		return "alphabetical"
+	}
+	return fmt.Sprintf("PropertyOrder(%d)", int(o))
+}
+
+// applyOrderExtension sets x-order on every property to its position in
+// properties, which follows the declaration order of the Go fields.
+func applyOrderExtension(_ *reflection, s *jsonschema.Schema) error {
+	return walkSchema(s, func(s *jsonschema.Schema) error {
+		if s.Properties == nil {
+			return nil
+		}
+		i := 0
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value != nil && !isBooleanSchema(pair.Value) {
+				setExtra(pair.Value, orderExtension, i)
+			}
+			i++
+		}
+		return nil
+	})
+}
+
+// applyPropertyOrder is the schema pass putting the properties and required
+// properties of every schema in the configured order. Passes adding required
+// properties append them, so required is reordered even in declaration
+// order.
+func (g *generator) applyPropertyOrder(_ *reflection, s *jsonschema.Schema) error {
+	return walkSchema(s, func(s *jsonschema.Schema) error {
+		if g.propertyOrder == PropertyAlphabeticalOrder && s.Properties != nil {
+			props := jsonschema.NewProperties()
+			var names []string
+			for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				names = append(names, pair.Key)
+			}
+			slices.Sort(names)
+			for _, name := range names {
+				prop, _ := s.Properties.Get(name)
+				props.Set(name, prop)
+			}
+			s.Properties = props
+		}
+		if len(s.Required) > 1 {
+			s.Required = orderRequired(s)
+		}
+		return nil
+	})
+}
+
+// orderRequired sorts the required properties of s by their position in
+// properties. Names that are not properties follow, sorted by name.
+func orderRequired(s *jsonschema.Schema) []string {
+	position := make(map[string]int)
+	if s.Properties != nil {
+		i := 0
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			position[pair.Key] = i
+			i++
+		}
+	}
+	required := slices.Clone(s.Required)
+	slices.SortStableFunc(required, func(a, b string) int {
+		pa, aok := position[a]
+		pb, bok := position[b]
+		switch {
+		case aok && bok:
+			return pa - pb
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return cmp.Compare(a, b)
+	})
+	return required
+}
@@ -0,0 +1,117 @@
+package schemator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// OrderedBase is embedded by OrderedRecord.
+type OrderedBase struct {
+	ID      string `json:"id"`
+	Created string `json:"created"`
+}
+
+// OrderedRecord declares its fields out of alphabetical order.
+type OrderedRecord struct {
+	Zeta  string `json:"zeta"`
+	Alpha string `json:"alpha"`
+	OrderedBase
+	Mid    int         `json:"mid"`
+	Nested OrderedBase `json:"nested"`
+}
+
+func TestPropertiesFollowDeclarationOrder(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithOrderExtension()).Generate(OrderedRecord{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var orders []float64
+	dec := json.NewDecoder(bytes.NewReader(doc.Properties))
+	_, _ = dec.Token()
+	for dec.More() {
+		tok, _ := dec.Token()
+		names = append(names, tok.(string))
+		var prop map[string]any
+		if err := dec.Decode(&prop); err != nil {
+			t.Fatal(err)
+		}
+		order, _ := prop["x-order"].(float64)
+		orders = append(orders, order)
+	}
+	if want := []string{"zeta", "alpha", "id", "created", "mid", "nested"}; !slices.Equal(names, want) {
+		t.Fatalf("properties = %v, want %v", names, want)
+	}
+	if want := []float64{0, 1, 2, 3, 4, 5}; !slices.Equal(orders, want) {
+		t.Fatalf("x-order = %v, want %v", orders, want)
+	}
+}
+
+// OrderedRequired declares required fields out of alphabetical order and has
+// a field made required by its validate tag, which is appended to required.
+type OrderedRequired struct {
+	Zeta  string  `json:"zeta"`
+	Mid   *string `json:"mid,omitempty" validate:"required"`
+	Alpha string  `json:"alpha"`
+}
+
+func TestPropertyOrder(t *testing.T) {
+	for _, tt := range []struct {
+		order    PropertyOrder
+		props    []string
+		required []string
+	}{
+		{PropertyDeclarationOrder, []string{"zeta", "mid", "alpha"}, []string{"zeta", "mid", "alpha"}},
+		{PropertyAlphabeticalOrder, []string{"alpha", "mid", "zeta"}, []string{"alpha", "mid", "zeta"}},
+	} {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			gen := NewGenerator(context.Background(), WithPropertyOrder(tt.order))
+			out, err := gen.Generate(OrderedRequired{})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for range 5 {
+				again, err := gen.Generate(OrderedRequired{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(out, again) {
+					t.Fatalf("output differs between runs:\n%s\n%s", out, again)
+				}
+			}
+			var doc struct {
+				Properties json.RawMessage `json:"properties"`
+				Required   []string        `json:"required"`
+			}
+			if err := json.Unmarshal(out, &doc); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			dec := json.NewDecoder(bytes.NewReader(doc.Properties))
+			_, _ = dec.Token()
+			for dec.More() {
+				tok, _ := dec.Token()
+				names = append(names, tok.(string))
+				var prop json.RawMessage
+				if err := dec.Decode(&prop); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !slices.Equal(names, tt.props) {
+				t.Errorf("properties = %v, want %v", names, tt.props)
+			}
+			if !slices.Equal(doc.Required, tt.required) {
+				t.Errorf("required = %v, want %v", doc.Required, tt.required)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package schemator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Overlay file extensions, after the type name of the model.
+const (
+	mergePatchExtension = ".merge.json"
+	jsonPatchExtension  = ".patch.json"
+)
+
+// applyOverlays applies the overlays of model in g.overlayDir to the
+// rendered schema out: first the RFC 7386 merge patch <Type>.merge.json,
+// then the RFC 6902 JSON patch <Type>.patch.json. Missing files are skipped.
+func (g *generator) applyOverlays(model any, out []byte) ([]byte, error) {
+	name := toString(model)
+	if g.overlayDir == "" || name == "" {
+		return out, nil
+	}
+	var doc any
+	for _, ext := range []string{mergePatchExtension, jsonPatchExtension} {
+		path := filepath.Join(g.overlayDir, name+ext)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		patch, err := decodeOrderedJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if doc == nil {
+			if doc, err = decodeOrderedJSON(out); err != nil {
+				return nil, err
+			}
+		}
+		if ext == mergePatchExtension {
+			doc = mergePatch(doc, patch)
+		} else if doc, err = jsonPatch(doc, patch); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if doc == nil {
+		return out, nil
+	}
+	compact, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return g.formatJSON(compact)
+}
+
+// jsonObject is a JSON object keeping the order of its keys, so patched
+// schemas keep the property order of the generated ones.
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]any)}
+}
+
+func (o *jsonObject) get(key string) (any, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// set replaces the value of key in place, or adds key last.
+func (o *jsonObject) set(key string, v any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+func (o *jsonObject) remove(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrderedJSON decodes data into *jsonObject, []any, string,
+// json.Number, bool and nil values.
+func decodeOrderedJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: trailing data")
+	}
+	return v, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		o := newJSONObject()
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o.set(tok.(string), v)
+		}
+		_, err := dec.Token()
+		return o, err
+	case json.Delim('['):
+		a := []any{}
+		for dec.More() {
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err := dec.Token()
+		return a, err
+	}
+	return tok, nil
+}
+
+// mergePatch applies the RFC 7386 merge patch patch to target.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(*jsonObject)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(*jsonObject)
+	if !ok {
+		t = newJSONObject()
+	}
+	for _, k := range p.keys {
+		if p.values[k] == nil {
+			t.remove(k)
+			continue
+		}
+		current, _ := t.get(k)
+		t.set(k, mergePatch(current, p.values[k]))
+	}
+	return t
+}
+
+// jsonPatch applies the RFC 6902 JSON patch patch to doc.
+func jsonPatch(doc, patch any) (any, error) {
+	ops, ok := patch.([]any)
+	if !ok {
+		return nil, errors.New("JSON patch must be an array of operations")
+	}
+	for i, raw := range ops {
+		op, ok := raw.(*jsonObject)
+		if !ok {
+			return nil, fmt.Errorf("operation %d is not an object", i)
+		}
+		var err error
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			name, _ := op.get("op")
+			return nil, fmt.Errorf("operation %d (%v): %w", i, name, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOperation(doc any, op *jsonObject) (any, error) {
+	pointer := func(member string) ([]string, error) {
+		v, ok := op.get(member)
+		s, isString := v.(string)
+		if !ok || !isString {
+			return nil, fmt.Errorf("missing %q", member)
+		}
+		return parseJSONPointer(s)
+	}
+	path, err := pointer("path")
+	if err != nil {
+		return nil, err
+	}
+	value, hasValue := op.get("value")
+	name, _ := op.get("op")
+	switch name {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, errors.New(`missing "value"`)
+		}
+	}
+	switch name {
+	case "add":
+		return addAt(doc, path, value)
+	case "remove":
+		_, doc, err := removeAt(doc, path)
+		return doc, err
+	case "replace":
+		if _, doc, err = removeAt(doc, path); err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, value)
+	case "move", "copy":
+		from, err := pointer("from")
+		if err != nil {
+			return nil, err
+		}
+		if name == "move" {
+			if len(from) < len(path) && strings.Join(path[:len(from)], "/") == strings.Join(from, "/") {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if value, doc, err = removeAt(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = valueAt(doc, from); err != nil {
+				return nil, err
+			}
+			value = copyJSON(value)
+		}
+		return addAt(doc, path, value)
+	case "test":
+		current, err := valueAt(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalJSON(current, value) {
+			return nil, errors.New("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %v", name)
+}
+
+// parseJSONPointer splits an RFC 6901 JSON pointer into its unescaped
+// reference tokens.
+func parseJSONPointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses tok as an index of a, allowing len(a) if end is set.
+func arrayIndex(a []any, tok string, end bool) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > len(a) || (i == len(a) && !end) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func valueAt(doc any, path []string) (any, error) {
+	for _, tok := range path {
+		switch n := doc.(type) {
+		case *jsonObject:
+			v, ok := n.get(tok)
+			if !ok {
+				return nil, fmt.Errorf("no member %q", tok)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(n, tok, false)
+			if err != nil {
+				return nil, err
+			}
+			doc = n[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %q", tok)
+		}
+	}
+	return doc, nil
+}
+
+// updateAt replaces the container holding the last token of path with what
+// fn returns for it, and returns the updated doc. Arrays are values in Go, so
+// every parent on the way gets the updated child set again.
+func updateAt(doc any, path []string, fn func(container any, tok string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := valueAt(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = updateAt(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+	switch n := doc.(type) {
+	case *jsonObject:
+		n.set(path[0], child)
+	case []any:
+		i, _ := arrayIndex(n, path[0], false)
+		n[i] = child
+	}
+	return doc, nil
+}
+
+func addAt(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateAt(doc, path, func(container any, tok string) (any, error) {
+		switch n := container.(type) {
+		case *jsonObject:
+			n.set(tok, value)
+			return n, nil
+		case []any:
+			if tok == "-" {
+				return append(n, value), nil
+			}
+			i, err := arrayIndex(n, tok, true)
+			if err != nil {
+				return nil, err
+			}
+			return append(n[:i:i], append([]any{value}, n[i:]...)...), nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a scalar", tok)
+	})
+}
+
+// removeAt removes the value at path and returns it with the updated doc.
+func removeAt(doc any, path []string) (removed, updated any, err error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the document root")
+	}
+	updated, err = updateAt(doc, path, func(container any, tok string) (any, error) {
+		switch n := container.(type) {
+		case *jsonObject:
+			v, ok := n.get(tok)
+			if !ok {
+				return nil, fmt.Errorf("no member %q", tok)
+			}
+			removed = v
+			n.remove(tok)
+			return n, nil
+		case []any:
+			i, err := arrayIndex(n, tok, false)
+			if err != nil {
+				return nil, err
+			}
+			removed = n[i]
+			return append(n[:i:i], n[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a scalar", tok)
+	})
+	return removed, updated, err
+}
+
+func copyJSON(v any) any {
+	switch n := v.(type) {
+	case *jsonObject:
+		c := newJSONObject()
+		for _, k := range n.keys {
+			c.set(k, copyJSON(n.values[k]))
+		}
+		return c
+	case []any:
+		c := make([]any, len(n))
+		for i := range n {
+			c[i] = copyJSON(n[i])
+		}
+		return c
+	}
+	return v
+}
+
+// equalJSON compares decoded JSON values as RFC 6902 test does: objects
+// regardless of key order and numbers by value.
+func equalJSON(a, b any) bool {
+	switch x := a.(type) {
+	case *jsonObject:
+		y, ok := b.(*jsonObject)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false
+		}
+		for _, k := range x.keys {
+			v, ok := y.get(k)
+			if !ok || !equalJSON(x.values[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalJSON(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		return errx == nil && erry == nil && fx == fy
+	}
+	return a == b
+}
@@ -0,0 +1,131 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverlays(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("NamerCustomer.merge.json", `{"title": "Customer", "properties": {"FullName": {"minLength": 1}}}`)
+	write("NamerCustomer.patch.json", `[
+		{"op": "test", "path": "/title", "value": "Customer"},
+		{"op": "add", "path": "/required/-", "value": "nickname"},
+		{"op": "copy", "from": "/properties/FullName", "path": "/properties/nickname"},
+		{"op": "move", "from": "/properties/nickname/minLength", "path": "/properties/nickname/maxLength"},
+		{"op": "replace", "path": "/additionalProperties", "value": true},
+		{"op": "remove", "path": "/$defs/NamerAddress/additionalProperties"}
+	]`)
+
+	gen := NewGenerator(context.Background(), WithOverlays(dir))
+	out, err := gen.Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	doc := string(out)
+	for _, want := range []string{
+		`"title": "Customer"`,
+		`"FullName": {
+      "type": "string",
+      "minLength": 1
+    }`,
+		`"nickname": {
+      "type": "string",
+      "maxLength": 1
+    }`,
+		`"additionalProperties": true`,
+		`"required": [
+    "FullName",
+    "address",
+    "nickname"
+  ]`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("schema lacks %s:\n%s", want, doc)
+		}
+	}
+	// The merge patch adds keys last, the rest keep their order.
+	if strings.Index(doc, `"$schema"`) != strings.Index(doc, "{")+4 || strings.Index(doc, `"title"`) < strings.Index(doc, `"$defs"`) {
+		t.Errorf("key order not kept:\n%s", doc)
+	}
+
+	// Models without overlays are left alone.
+	plain, err := NewGenerator(context.Background()).Generate(NamerAddress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := gen.Generate(NamerAddress{}); err != nil || string(out) != string(plain) {
+		t.Errorf("Generate(NamerAddress) = %s, %v; want %s", out, err, plain)
+	}
+
+	write("NamerCustomer.patch.json", `[{"op": "test", "path": "/title", "value": "Client"}]`)
+	if _, err := gen.Generate(NamerCustomer{}); err == nil || !strings.Contains(err.Error(), "NamerCustomer.patch.json: operation 0 (test): test failed") {
+		t.Errorf("failing test op error = %v", err)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	for _, tc := range []struct {
+		doc, patch, want, err string
+	}{
+		{doc: `{"a":[1,2]}`, patch: `[{"op":"add","path":"/a/1","value":3}]`, want: `{"a":[1,3,2]}`},
+		{doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/0"}]`, want: `{"a":[2]}`},
+		{doc: `{"a/b":{"~":1}}`, patch: `[{"op":"replace","path":"/a~1b/~0","value":2}]`, want: `{"a/b":{"~":2}}`},
+		{doc: `{"a":1}`, patch: `[{"op":"add","path":"","value":[]}]`, want: `[]`},
+		{doc: `{"a":{"b":1.0}}`, patch: `[{"op":"test","path":"/a","value":{"b":1}}]`, want: `{"a":{"b":1.0}}`},
+		{doc: `{"a":[]}`, patch: `[{"op":"add","path":"/a/1","value":1}]`, err: "array index 1 out of range"},
+		{doc: `{"a":{}}`, patch: `[{"op":"move","from":"/a","path":"/a/b"}]`, err: "cannot move a value into itself"},
+		{doc: `{}`, patch: `[{"op":"remove","path":"/x"}]`, err: `no member "x"`},
+		{doc: `{}`, patch: `[{"op":"frobnicate","path":""}]`, err: "unknown op frobnicate"},
+	} {
+		doc, _ := decodeOrderedJSON([]byte(tc.doc))
+		patch, err := decodeOrderedJSON([]byte(tc.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := jsonPatch(doc, patch)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s on %s: error = %v, want %s", tc.patch, tc.doc, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s on %s: error = %v", tc.patch, tc.doc, err)
+			continue
+		}
+		if s := compactJSON(got); s != tc.want {
+			t.Errorf("%s on %s = %s, want %s", tc.patch, tc.doc, s, tc.want)
+		}
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A.
+	for _, tc := range [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		target, _ := decodeOrderedJSON([]byte(tc[0]))
+		patch, _ := decodeOrderedJSON([]byte(tc[1]))
+		if got := compactJSON(mergePatch(target, patch)); got != tc[2] {
+			t.Errorf("merge %s into %s = %s, want %s", tc[1], tc[0], got, tc[2])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package schemator
+
+import (
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// SchemaOverride is set on the schema of a type after reflection. Empty
+// fields are left as reflected.
+type SchemaOverride struct {
+	Title       string
+	Description string
+	// Keywords are added to the schema, such as "x-go-package" or
+	// "deprecated".
+	Keywords map[string]any
+}
+
+// SchemaOverrides maps types to the overrides of their schemas, as a type
+// safe alternative to overlay files. Keys are a reflect.Type, a value of the
+// type, or a "<import path>.<Type>" or "<package>.<Type>" string such as
+// "github.com/acme/contracts.Order" or "contracts.Order".
+type SchemaOverrides map[any]SchemaOverride
+
+// lookup returns the override of t.
+func (o SchemaOverrides) lookup(t reflect.Type) (SchemaOverride, bool) {
+	t = derefType(t)
+	if t == nil {
+		return SchemaOverride{}, false
+	}
+	if ov, ok := o[t]; ok {
+		return ov, true
+	}
+	for key, ov := range o {
+		switch k := key.(type) {
+		case reflect.Type:
+			if derefType(k) == t {
+				return ov, true
+			}
+		case string:
+			if id := typeID(t); id != "" && (k == id || k == typeKey(t) || k == importName(t.PkgPath())+"."+t.Name()) {
+				return ov, true
+			}
+		default:
+			if derefType(reflect.TypeOf(k)) == t {
+				return ov, true
+			}
+		}
+	}
+	return SchemaOverride{}, false
+}
+
+// applySchemaOverrides is the schema pass applying g.overrides to the root
+// and the definitions.
+func (g *generator) applySchemaOverrides(rf *reflection, s *jsonschema.Schema) error {
+	apply := func(t reflect.Type, ts *jsonschema.Schema) {
+		ov, ok := g.overrides.lookup(t)
+		if !ok {
+			return
+		}
+		if ov.Title != "" {
+			ts.Title = ov.Title
+		}
+		if ov.Description != "" {
+			ts.Description = ov.Description
+		}
+		for k, v := range ov.Keywords {
+			setExtra(ts, k, v)
+		}
+	}
+	if s.Ref == "" {
+		apply(reflect.TypeOf(rf.model), s)
+	}
+	for name, ts := range s.Definitions {
+		if t, ok := rf.types[name]; ok {
+			apply(t, ts)
+		}
+	}
+	return nil
+}
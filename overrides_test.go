@@ -0,0 +1,37 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaOverrides(t *testing.T) {
+	for name, key := range map[string]any{
+		"reflect.Type": reflect.TypeFor[*NamerAddress](),
+		"value":        NamerAddress{},
+		"import path":  "pkt.systems/schemator.NamerAddress",
+		"package":      "schemator.NamerAddress",
+	} {
+		gen := NewGenerator(context.Background(), WithSchemaOverrides(SchemaOverrides{
+			NamerCustomer{}: {Title: "Customer", Description: "A customer.", Keywords: map[string]any{"x-owner": "billing"}},
+			key:             {Description: "A postal address."},
+		}))
+		out, err := gen.Generate(NamerCustomer{})
+		if err != nil {
+			t.Fatalf("%s: Generate() error = %v", name, err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc["title"] != "Customer" || doc["description"] != "A customer." || doc["x-owner"] != "billing" {
+			t.Errorf("%s: root = %s", name, out)
+		}
+		address := doc["$defs"].(map[string]any)["NamerAddress"].(map[string]any)
+		if address["description"] != "A postal address." || address["title"] != nil {
+			t.Errorf("%s: NamerAddress = %v", name, address)
+		}
+	}
+}
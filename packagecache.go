@@ -0,0 +1,327 @@
+package schemator
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageDirCache holds the package lookups of lookupPackageDir and
+// lookupPackageDirs for the life of the process, so generating many schemas
+// loads each package once instead of once per Generate call. changes counts
+// the updates of dirs, and saved holds the count each WithPackageCache file
+// was last in sync with, so unchanged lookups are not written again.
+var packageDirCache = struct {
+	sync.Mutex
+	dirs    map[packageLookupKey]packageLookup
+	changes int
+	saved   map[string]int
+}{dirs: make(map[packageLookupKey]packageLookup), saved: make(map[string]int)}
+
+// packageLookupKey identifies a lookup: the import path and the absolute
+// directory it was loaded in, which decides the module resolving it.
+type packageLookupKey struct {
+	ImportPath string `json:"importPath"`
+	WorkDir    string `json:"workDir"`
+}
+
+// packageLookup is the result of a lookup.
+type packageLookup struct {
+	Dir      string `json:"dir"`
+	Standard bool   `json:"standard"`
+}
+
+// packageCacheEntry is a lookup as stored in a WithPackageCache file.
+// Modules is the moduleFilesHash of WorkDir when the lookup was stored.
+type packageCacheEntry struct {
+	packageLookupKey
+	packageLookup
+	Modules string `json:"modules,omitempty"`
+}
+
+// cachedPackageDir returns the cached lookup identified by key.
+func cachedPackageDir(key packageLookupKey) (packageLookup, bool) {
+	packageDirCache.Lock()
+	defer packageDirCache.Unlock()
+	dir, ok := packageDirCache.dirs[key]
+	return dir, ok
+}
+
+func cachePackageDir(key packageLookupKey, dir packageLookup) {
+	packageDirCache.Lock()
+	defer packageDirCache.Unlock()
+	if old, ok := packageDirCache.dirs[key]; ok && old == dir {
+		return
+	}
+	packageDirCache.dirs[key] = dir
+	packageDirCache.changes++
+}
+
+// lookupKey returns the cache key of looking up importPath in workDir, the
+// current directory if empty.
+func lookupKey(importPath, workDir string) (packageLookupKey, error) {
+	if workDir == "" {
+		workDir = "."
+	}
+	abs, err := filepath.Abs(workDir)
+	if err != nil {
+		return packageLookupKey{}, err
+	}
+	return packageLookupKey{ImportPath: importPath, WorkDir: abs}, nil
+}
+
+// lookupPackageDirs resolves the uncached import paths of local modules and
+// vendored packages from disk and the others in one package load, and caches
+// their directories. Paths that cannot be resolved are left
+// uncached for lookupPackageDir to report.
+func lookupPackageDirs(ctx context.Context, importPaths []string, workDir string) error {
+	dirKey, err := lookupKey("", workDir)
+	if err != nil {
+		return err
+	}
+	modules, err := localModules(dirKey.WorkDir)
+	if err != nil {
+		return err
+	}
+	vendor, err := vendorDir(dirKey.WorkDir)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, importPath := range importPaths {
+		key := packageLookupKey{ImportPath: importPath, WorkDir: dirKey.WorkDir}
+		if _, ok := cachedPackageDir(key); ok || slices.Contains(missing, importPath) {
+			continue
+		}
+		if dir, ok := localPackageDir(modules, importPath); ok {
+			cachePackageDir(key, packageLookup{Dir: dir})
+			continue
+		}
+		if dir, ok := vendoredPackageDir(vendor, importPath); ok {
+			cachePackageDir(key, packageLookup{Dir: dir})
+			continue
+		}
+		missing = append(missing, importPath)
+	}
+	if len(missing) < 2 {
+		// lookupPackageDir loads a single package the same way.
+		return nil
+	}
+	pds, err := loadPackageDirs(ctx, missing, workDir)
+	if err != nil {
+		// lookupPackageDir reports the error of each path.
+		return nil
+	}
+	for importPath, pd := range pds {
+		if pd.err == nil {
+			cachePackageDir(packageLookupKey{ImportPath: importPath, WorkDir: dirKey.WorkDir}, pd.packageLookup)
+		}
+	}
+	return nil
+}
+
+// loadedPackageDir is the result of loading a package, or why it could not be
+// loaded.
+type loadedPackageDir struct {
+	packageLookup
+	err error
+}
+
+// loadPackageDirs loads the packages importPaths in workDir, the current
+// directory if empty, and returns their directories keyed by import path.
+// The error is only non-nil if the packages could not be loaded at all.
+func loadPackageDirs(ctx context.Context, importPaths []string, workDir string) (_ map[string]loadedPackageDir, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := startSpan(ctx, "schemator.LoadPackages", attribute.StringSlice("schemator.packages", importPaths))
+	defer func() { endSpan(span, err) }()
+	cfg := loaderConfigFrom(ctx)
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+	dir := workDir
+	if dir == "" {
+		dir = "."
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedModule,
+		Dir:     workDir,
+		Env:     cfg.loaderEnv(dir),
+	}, importPaths...)
+	if cfg.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, &GoListError{Packages: importPaths, Output: fmt.Sprintf("go command timed out after %s", cfg.timeout), Err: ctx.Err()}
+	}
+	if err != nil {
+		return nil, &GoListError{Packages: importPaths, Output: err.Error(), Err: err}
+	}
+	pds := make(map[string]loadedPackageDir, len(importPaths))
+	for _, pkg := range pkgs {
+		pd := loadedPackageDir{packageLookup: packageLookup{Dir: pkg.Dir, Standard: pkg.Module == nil}}
+		switch {
+		case len(pkg.Errors) > 0:
+			errs := make([]error, len(pkg.Errors))
+			for i, e := range pkg.Errors {
+				errs[i] = e
+			}
+			err := errors.Join(errs...)
+			pd.err = &GoListError{Packages: []string{pkg.PkgPath}, Output: err.Error(), Err: err}
+			if cfg.offline {
+				pd.err = offlineError(pkg.PkgPath, pd.err)
+			}
+		case pkg.Dir == "":
+			pd.err = &GoListError{Packages: []string{pkg.PkgPath}, Output: "no source directory", Err: ErrNoGoFiles}
+		}
+		pds[pkg.PkgPath] = pd
+	}
+	for _, importPath := range importPaths {
+		if _, ok := pds[importPath]; !ok {
+			pds[importPath] = loadedPackageDir{err: &GoListError{Packages: []string{importPath}, Output: "package not found"}}
+		}
+	}
+	return pds, nil
+}
+
+// moduleFilesHash returns a hash of the go.work and go.mod files governing
+// dir, which decide the directories the packages imported in dir resolve to.
+func moduleFilesHash(dir string) (string, error) {
+	workFile, err := findWorkFile(dir)
+	if err != nil {
+		return "", err
+	}
+	modFile := ""
+	for d := dir; ; {
+		name := filepath.Join(d, "go.mod")
+		if fi, err := os.Stat(name); err == nil && !fi.IsDir() {
+			modFile = name
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	h := sha256.New()
+	for _, name := range []string{workFile, modFile} {
+		var data []byte
+		if name != "" {
+			if data, err = os.ReadFile(name); err != nil {
+				return "", err
+			}
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moduleFilesHashes memoizes moduleFilesHash by directory. A directory whose
+// files cannot be read hashes to "", which matches no stored lookup.
+func moduleFilesHashes() func(dir string) string {
+	hashes := make(map[string]string)
+	return func(dir string) string {
+		hash, ok := hashes[dir]
+		if !ok {
+			hash, _ = moduleFilesHash(dir)
+			hashes[dir] = hash
+		}
+		return hash
+	}
+}
+
+// loadPackageCache adds the lookups stored in the file name to the cache.
+// Lookups whose directory no longer exists, or whose go.mod or go.work file
+// changed since they were stored, are dropped, and a missing file is an empty
+// cache.
+func loadPackageCache(name string) error {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		packageDirCache.Lock()
+		delete(packageDirCache.saved, name)
+		packageDirCache.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []packageCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("package cache %s: %w", name, err)
+	}
+	packageDirCache.Lock()
+	defer packageDirCache.Unlock()
+	// The file is in sync if it holds exactly the cached lookups.
+	inSync := len(entries) == len(packageDirCache.dirs)
+	modules := moduleFilesHashes()
+	for _, e := range entries {
+		current := modules(e.WorkDir) == e.Modules && e.Modules != ""
+		if pd, ok := packageDirCache.dirs[e.packageLookupKey]; ok {
+			inSync = inSync && current && pd == e.packageLookup
+			continue
+		}
+		inSync = false
+		if !current {
+			continue
+		}
+		if fi, err := os.Stat(e.Dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		packageDirCache.dirs[e.packageLookupKey] = e.packageLookup
+		packageDirCache.changes++
+	}
+	if inSync {
+		packageDirCache.saved[name] = packageDirCache.changes
+	}
+	return nil
+}
+
+// savePackageCache writes the cached lookups to the file name, sorted for
+// stable output, unless they did not change since it was last loaded or
+// saved.
+func savePackageCache(name string, perms permissions) error {
+	packageDirCache.Lock()
+	if changes, ok := packageDirCache.saved[name]; ok && changes == packageDirCache.changes {
+		packageDirCache.Unlock()
+		return nil
+	}
+	changes := packageDirCache.changes
+	keys := slices.SortedFunc(maps.Keys(packageDirCache.dirs), func(a, b packageLookupKey) int {
+		return cmp.Or(cmp.Compare(a.WorkDir, b.WorkDir), cmp.Compare(a.ImportPath, b.ImportPath))
+	})
+	entries := make([]packageCacheEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = packageCacheEntry{packageLookupKey: key, packageLookup: packageDirCache.dirs[key]}
+	}
+	packageDirCache.Unlock()
+	modules := moduleFilesHashes()
+	for i := range entries {
+		entries[i].Modules = modules(entries[i].WorkDir)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := perms.write(name, append(data, '\n')); err != nil {
+		return err
+	}
+	packageDirCache.Lock()
+	packageDirCache.saved[name] = changes
+	packageDirCache.Unlock()
+	return nil
+}
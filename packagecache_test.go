@@ -0,0 +1,173 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "packages.json")
+	if _, err := NewGenerator(context.Background(), WithPackageCache(cacheFile)).Generate(OrderedBase{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []packageCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range entries {
+		found = found || e.ImportPath == "pkt.systems/schemator"
+	}
+	if !found {
+		t.Errorf("package cache = %s, missing pkt.systems/schemator", data)
+	}
+
+	// A package that cannot be loaded resolves from the cache file, and one
+	// whose directory is gone is looked up again.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules, err := moduleFilesHash(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries = []packageCacheEntry{
+		{packageLookupKey{ImportPath: "example.com/cached", WorkDir: wd}, packageLookup{Dir: wd}, modules},
+		{packageLookupKey{ImportPath: "example.com/gone", WorkDir: wd}, packageLookup{Dir: filepath.Join(wd, "gone")}, modules},
+	}
+	data, err = json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cacheFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadPackageCache(cacheFile); err != nil {
+		t.Fatalf("loadPackageCache() error = %v", err)
+	}
+	ip, err := ensureSourceDirectory(context.Background(), ImportPath{ModuleImportPath: "example.com/cached"})
+	if err != nil || ip.SourceDirectory != wd {
+		t.Errorf("ensureSourceDirectory() = %+v, %v", ip, err)
+	}
+	if _, err := ensureSourceDirectory(context.Background(), ImportPath{ModuleImportPath: "example.com/gone"}); err == nil {
+		t.Error("ensureSourceDirectory() of a vanished cached directory error = nil")
+	}
+}
+
+func TestPackageCacheModuleChange(t *testing.T) {
+	workDir := t.TempDir()
+	modFile := filepath.Join(workDir, "go.mod")
+	if err := os.WriteFile(modFile, []byte("module example.com/app\n\nrequire example.com/lib v1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := moduleFilesHash(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(modFile, []byte("module example.com/app\n\nrequire example.com/lib v1.1.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	current, err := moduleFilesHash(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current == stale {
+		t.Fatal("moduleFilesHash() ignores go.mod changes")
+	}
+
+	// A lookup stored before go.mod changed is looked up again.
+	entries := []packageCacheEntry{
+		{packageLookupKey{ImportPath: "example.com/lib/stale", WorkDir: workDir}, packageLookup{Dir: workDir}, stale},
+		{packageLookupKey{ImportPath: "example.com/lib/current", WorkDir: workDir}, packageLookup{Dir: workDir}, current},
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheFile := filepath.Join(t.TempDir(), "packages.json")
+	if err := os.WriteFile(cacheFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadPackageCache(cacheFile); err != nil {
+		t.Fatalf("loadPackageCache() error = %v", err)
+	}
+	for _, e := range entries {
+		_, ok := cachedPackageDir(e.packageLookupKey)
+		if want := e.Modules == current; ok != want {
+			t.Errorf("%s: cached = %v, want %v", e.ImportPath, ok, want)
+		}
+	}
+}
+
+func TestPackageCacheWrites(t *testing.T) {
+	ctx := context.Background()
+	cacheFile := filepath.Join(t.TempDir(), "packages.json")
+	if _, err := NewGenerator(ctx, WithPackageCache(cacheFile), WithDryRun(func(PlannedWrite) {})).Generate(OrderedBase{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote the package cache: %v", err)
+	}
+	if _, err := NewGenerator(ctx, WithPackageCache(cacheFile)).Generate(OrderedBase{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged lookups leave the file as it is, even if formatted
+	// differently.
+	var entries []packageCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	compact, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cacheFile, compact, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(ctx, WithPackageCache(cacheFile))
+	for range 2 {
+		if _, err := gen.Generate(OrderedBase{}); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	if data, err := os.ReadFile(cacheFile); err != nil || string(data) != string(compact) {
+		t.Errorf("unchanged package cache rewritten: %s, %v", data, err)
+	}
+}
+
+func TestLookupPackageDirs(t *testing.T) {
+	workDir := t.TempDir()
+	if err := lookupPackageDirs(context.Background(), []string{"fmt", "net/http", "example.com/missing", "fmt"}, workDir); err != nil {
+		t.Fatalf("lookupPackageDirs() error = %v", err)
+	}
+	for _, tt := range []struct {
+		importPath string
+		cached     bool
+	}{
+		{"fmt", true},
+		{"net/http", true},
+		{"example.com/missing", false},
+	} {
+		key, err := lookupKey(tt.importPath, workDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pd, ok := cachedPackageDir(key)
+		if ok != tt.cached || (ok && (!pd.Standard || filepath.Base(pd.Dir) != filepath.Base(tt.importPath))) {
+			t.Errorf("%s: cached %+v, %v", tt.importPath, pd, ok)
+		}
+	}
+}
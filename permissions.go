@@ -0,0 +1,42 @@
+package schemator
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// permissions are the modes of written files and of the directories created
+// for them.
+type permissions struct {
+	file os.FileMode
+	dir  os.FileMode
+}
+
+// defaultPermissions are used unless WithFileMode, WithDirMode or WithUmask
+// say otherwise.
+var defaultPermissions = permissions{file: 0o644, dir: 0o755}
+
+// umaskPermissions returns the permissions a umask of mask leaves of 0666 for
+// files and 0777 for directories.
+func umaskPermissions(mask os.FileMode) permissions {
+	return permissions{file: 0o666 &^ mask, dir: 0o777 &^ mask}
+}
+
+// keepFileMode returns p with the file mode of name if it exists, so
+// functions called without a Generator, such as GC, keep the mode a file was
+// first written with.
+func (p permissions) keepFileMode(name string) permissions {
+	if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() {
+		p.file = info.Mode().Perm()
+	}
+	return p
+}
+
+// write writes data to name with the file mode of p, creating missing parent
+// directories with the directory mode of p.
+func (p permissions) write(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), p.dir); err != nil {
+		return err
+	}
+	return writeFileAtomic(name, data, p.file)
+}
@@ -0,0 +1,42 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPermissions(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		file os.FileMode
+		dir  os.FileMode
+	}{
+		{"default", nil, 0o644, 0o755},
+		{"modes", []Option{WithFileMode(0o600), WithDirMode(0o700)}, 0o600, 0o700},
+		{"umask", []Option{WithUmask(0o027)}, 0o640, 0o750},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "schemas")
+			opts := append([]Option{WithVersion("1.0.0")}, tt.opts...)
+			if err := NewGenerator(context.Background(), opts...).WriteSchemas(dir, OrderedBase{}); err != nil {
+				t.Fatalf("WriteSchemas() error = %v", err)
+			}
+			for name, want := range map[string]os.FileMode{
+				dir: tt.dir | os.ModeDir,
+				filepath.Join(dir, "OrderedBase.1.0.0.schema.json"): tt.file,
+				filepath.Join(dir, ManifestFilename):                tt.file,
+			} {
+				info, err := os.Stat(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := info.Mode() & (os.ModeDir | os.ModePerm); got != want {
+					t.Errorf("%s mode = %v, want %v", filepath.Base(name), got, want)
+				}
+			}
+		})
+	}
+}
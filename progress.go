@@ -0,0 +1,90 @@
+package schemator
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressKind classifies a ProgressEvent.
+type ProgressKind string
+
+const (
+	// ModelStarted is reported when generation of a model's schema starts.
+	ModelStarted ProgressKind = "model-started"
+	// ModelFinished is reported when generation of a model's schema is done,
+	// with the time it took and, if it failed, the error.
+	ModelFinished ProgressKind = "model-finished"
+	// FileWritten is reported for every file written, with its size.
+	FileWritten ProgressKind = "file-written"
+)
+
+// ProgressEvent is a step of WriteSchemas, WriteNamedSchemas, WriteSchemasFS,
+// WriteSchemasArchive, GenerateAll, Verify or WriteAll passed to the
+// WithProgress function.
+type ProgressEvent struct {
+	Kind ProgressKind
+	// Model is the name of the model of ModelStarted and ModelFinished.
+	Model string
+	// Done and Total count the models finished so far and the models of the
+	// call.
+	Done, Total int
+	// Elapsed is how long generating the model took (ModelFinished).
+	Elapsed time.Duration
+	// Err is why the model failed (ModelFinished).
+	Err error
+	// File and Bytes are the path and size of a written file (FileWritten).
+	File  string
+	Bytes int
+}
+
+// modelProgress reports the progress of generating total models to the
+// WithProgress function, one event at a time.
+type modelProgress struct {
+	report func(ProgressEvent)
+	mu     sync.Mutex
+	total  int
+	done   int
+}
+
+// modelProgress returns what reports the progress of generating the models
+// with a filename in filenames.
+func (g *generator) modelProgress(filenames []string) *modelProgress {
+	p := &modelProgress{report: g.progress}
+	for _, filename := range filenames {
+		if filename != "" {
+			p.total++
+		}
+	}
+	return p
+}
+
+// start reports that generating model starts and returns the time it did.
+func (p *modelProgress) start(model any) time.Time {
+	now := time.Now()
+	if p.report == nil {
+		return now
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.report(ProgressEvent{Kind: ModelStarted, Model: toString(model), Done: p.done, Total: p.total})
+	return now
+}
+
+// finish reports that generating model, started at start, ended with err.
+func (p *modelProgress) finish(model any, start time.Time, err error) {
+	if p.report == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.report(ProgressEvent{Kind: ModelFinished, Model: toString(model), Done: p.done, Total: p.total, Elapsed: elapsed, Err: err})
+}
+
+// written reports that size bytes were written to name.
+func (g *generator) written(name string, size int) {
+	if g.progress != nil {
+		g.progress(ProgressEvent{Kind: FileWritten, File: name, Bytes: size})
+	}
+}
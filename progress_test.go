@@ -0,0 +1,47 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	for _, concurrency := range []int{1, 2} {
+		var events []ProgressEvent
+		dir := t.TempDir()
+		gen := NewGenerator(context.Background(), WithConcurrency(concurrency), WithProgress(func(e ProgressEvent) {
+			events = append(events, e)
+		}))
+		if err := gen.WriteSchemas(dir, OrderedBase{}, OrderedRecord{}); err != nil {
+			t.Fatalf("WriteSchemas() error = %v", err)
+		}
+		started, finished := map[string]bool{}, map[string]bool{}
+		written := 0
+		done := 0
+		for _, e := range events {
+			switch e.Kind {
+			case ModelStarted:
+				if finished[e.Model] || e.Total != 2 {
+					t.Errorf("with concurrency %d: unexpected %+v", concurrency, e)
+				}
+				started[e.Model] = true
+			case ModelFinished:
+				if !started[e.Model] || e.Err != nil || e.Done != done+1 || e.Total != 2 || e.Elapsed < 0 {
+					t.Errorf("with concurrency %d: unexpected %+v", concurrency, e)
+				}
+				finished[e.Model] = true
+				done = e.Done
+			case FileWritten:
+				info, err := os.Stat(e.File)
+				if err != nil || info.Size() != int64(e.Bytes) {
+					t.Errorf("with concurrency %d: %+v: %v", concurrency, e, err)
+				}
+				written++
+			}
+		}
+		if !finished["OrderedBase"] || !finished["OrderedRecord"] || written != 2 {
+			t.Errorf("with concurrency %d: events = %+v", concurrency, events)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// schematorPath is the import path of this package.
+var schematorPath = reflect.TypeFor[generator]().PkgPath()
+
+// provenanceComment returns the $comment WithProvenance adds to the schema of
+// model: the schemator version, the module of the package declaring model
+// and the settings differing from the defaults, such as
+//
+//	Generated by pkt.systems/schemator v1.4.0 from github.com/acme/contracts.Order (github.com/acme/contracts v0.9.2) with required=non-pointer, version=1.2.0
+func (g *generator) provenanceComment(model any) string {
+	modules := buildModules()
+	_, version := packageModule(schematorPath, modules)
+	if version == "" {
+		version = "(unknown)"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Generated by %s %s", schematorPath, version)
+	if t := derefType(reflect.TypeOf(model)); t != nil && typeID(t) != "" {
+		fmt.Fprintf(&b, " from %s", typeID(t))
+		if module, version := packageModule(t.PkgPath(), modules); module != "" {
+			fmt.Fprintf(&b, " (%s %s)", module, version)
+		}
+	}
+	if params := g.parameters(); len(params) > 0 {
+		fmt.Fprintf(&b, " with %s", strings.Join(params, ", "))
+	}
+	return b.String()
+}
+
+// parameters returns the generation settings differing from the defaults
+// as key=value pairs, in a fixed order.
+func (g *generator) parameters() []string {
+	var params []string
+	add := func(key string, value fmt.Stringer, isDefault bool) {
+		if !isDefault {
+			params = append(params, key+"="+value.String())
+		}
+	}
+	add("required", g.requiredPolicy, g.requiredPolicy == RequiredWithoutOmitempty)
+	add("embed", g.embedPolicy, g.embedPolicy == EmbedFlatten)
+	add("alias", g.aliasPolicy, g.aliasPolicy == AliasInline)
+	add("any", g.anyPolicy, g.anyPolicy == AnyPermissive)
+	add("internal", g.internalTypes, g.internalTypes == AllowInternalTypes)
+	add("duration", g.durationFormat, g.durationFormat == DurationString)
+	add("int64", g.int64Format, g.int64Format == Int64Number)
+	add("collisions", g.nameCollisions, g.nameCollisions == CollisionSuffix)
+	add("refs", g.refMode, g.refMode == RefPointer)
+	add("order", g.propertyOrder, g.propertyOrder == PropertyDeclarationOrder)
+	flags := []struct {
+		key string
+		set bool
+	}{
+		{"strict", g.strict},
+		{"inline-refs", g.inlineRefs},
+		{"nullable-pointers", g.nullablePointers},
+		{"integer-bounds", g.integerBounds},
+		{"infer-formats", g.inferFormats},
+		{"go-extensions", g.goExtensions},
+	}
+	for _, f := range flags {
+		if f.set {
+			params = append(params, f.key)
+		}
+	}
+	if g.nameTag != "" {
+		params = append(params, "tag="+g.nameTag)
+	}
+	if g.maxDepth > 0 {
+		params = append(params, "max-depth="+strconv.Itoa(g.maxDepth))
+	}
+	if g.overlayDir != "" {
+		params = append(params, "overlays="+g.overlayDir)
+	}
+	if g.version != "" {
+		params = append(params, "version="+g.version)
+	}
+	return params
+}
+
+// addProvenance adds the provenance of model to the $comment of s.
+func (g *generator) addProvenance(model any, s *jsonschema.Schema) {
+	if s.Comments != "" {
+		s.Comments += "\n"
+	}
+	s.Comments += g.provenanceComment(model)
+}
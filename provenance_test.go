@@ -0,0 +1,44 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	gen := NewGenerator(context.Background(),
+		WithProvenance(),
+		WithRequiredPolicy(RequiredNonPointer),
+		WithMaxDepth(3),
+		WithVersion("1.2.0"),
+	)
+	out, err := gen.Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Comment string `json:"$comment"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Generated by pkt.systems/schemator ",
+		" from pkt.systems/schemator.NamerCustomer (pkt.systems/schemator ",
+		" with required=" + RequiredNonPointer.String() + ", max-depth=3, version=1.2.0",
+	} {
+		if !strings.Contains(doc.Comment, want) {
+			t.Errorf("$comment = %q, want %q in it", doc.Comment, want)
+		}
+	}
+
+	out, err = NewGenerator(context.Background()).Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "$comment") {
+		t.Errorf("$comment without WithProvenance:\n%s", out)
+	}
+}
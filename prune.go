@@ -0,0 +1,83 @@
+package schemator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"pkt.systems/logport"
+)
+
+// Prune removes the JSON schema files (*.schema.json) in dir and its
+// subdirectories that are not listed in keep, such as the schemas of models
+// no longer generated, and returns the files it removed (or would remove
+// when dryRun is set) relative to dir. keep holds slash-separated paths
+// relative to dir. Versioned artifacts recorded in the manifest are left to
+// GC, and removed files are dropped from the manifest.
+func Prune(dir string, keep []string, dryRun bool) ([]string, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	versioned := make(map[string]bool)
+	for _, a := range m.Artifacts {
+		if a.Version != "" {
+			versioned[a.File] = true
+		}
+	}
+	var stale []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), JSONSchemaFormat.Extension) {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); !slices.Contains(keep, rel) && !versioned[rel] {
+			stale = append(stale, rel)
+		}
+		return nil
+	})
+	if err != nil || dryRun || len(stale) == 0 {
+		return stale, err
+	}
+	var removed []string
+	for _, file := range stale {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(file))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		removed = append(removed, file)
+	}
+	n := len(m.Artifacts)
+	m.Artifacts = slices.DeleteFunc(m.Artifacts, func(a Artifact) bool {
+		return slices.Contains(removed, a.File)
+	})
+	if len(m.Artifacts) == n {
+		return removed, nil
+	}
+	return removed, WriteManifest(dir, m)
+}
+
+// prune removes the schema files in outputDir not among written, as
+// WithPrune and WithPruneDryRun ask for.
+func (g *generator) prune(outputDir string, written []string) error {
+	if !g.pruneStale {
+		return nil
+	}
+	l := logport.LoggerFromContext(g.ctx).With("outputDir", outputDir)
+	files, err := Prune(outputDir, written, g.pruneDryRun || g.dryRun)
+	for _, file := range files {
+		if g.dryRun {
+			g.plan(PlannedWrite{File: filepath.Join(outputDir, filepath.FromSlash(file)), Action: WriteRemove})
+		} else if g.pruneDryRun {
+			l.Info("Would prune stale schema file", "file", file)
+		} else {
+			l.Info("Pruned stale schema file", "file", file)
+		}
+	}
+	return err
+}
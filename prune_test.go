@@ -0,0 +1,61 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWithPrune(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background(), WithManifest()).WriteSchemas(dir, OrderedBase{}, OrderedRecord{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "notes.txt"), "keep me")
+	writeFile(t, filepath.Join(dir, "nested", "Old.schema.json"), "{}")
+
+	if err := NewGenerator(context.Background(), WithPruneDryRun()).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas(dry run) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "OrderedRecord.schema.json")); err != nil {
+		t.Errorf("dry run removed a file: %v", err)
+	}
+
+	if err := NewGenerator(context.Background(), WithPrune()).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	var files []string
+	_ = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if want := []string{"OrderedBase.schema.json", ManifestFilename, "notes.txt"}; !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 1 || m.Artifacts[0].File != "OrderedBase.schema.json" {
+		t.Errorf("manifest = %+v", m.Artifacts)
+	}
+}
+
+func TestPruneKeepsVersionedArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background(), WithVersion("1.0.0")).WriteSchemas(dir, OrderedBase{}); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := Prune(dir, nil, false)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("Prune() removed %v", removed)
+	}
+}
@@ -0,0 +1,101 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// rootRef is the reference to the root of the document.
+const rootRef = "#"
+
+// applySelfReferences is the schema pass fixing the references a recursive
+// root type makes to itself. The Reflector expands the root struct into the
+// document and removes its definition, leaving those references dangling;
+// they are pointed at the document root, or at a definition of the root
+// named after WithRecursiveRoot.
+func (g *generator) applySelfReferences(rf *reflection, s *jsonschema.Schema) error {
+	root := derefType(reflect.TypeOf(rf.model))
+	if root == nil || s.Ref != "" {
+		return nil
+	}
+	for name, t := range rf.types {
+		if derefType(t) != root {
+			continue
+		}
+		if _, exists := s.Definitions[name]; exists {
+			continue
+		}
+		ref := "#/$defs/" + name
+		if !refersTo(s, ref) {
+			continue
+		}
+		if !g.recursiveRoot {
+			renameRefs(s, ref, rootRef)
+			continue
+		}
+		defName := g.recursiveRootName
+		if defName == "" {
+			defName = name
+		}
+		if _, exists := s.Definitions[defName]; exists {
+			return fmt.Errorf("recursive root %s collides with the definition %s", root, defName)
+		}
+		renameRefs(s, ref, "#/$defs/"+defName)
+		def := copySchemaNode(s)
+		def.Version, def.ID, def.Definitions = "", "", nil
+		defs := s.Definitions
+		if defs == nil {
+			defs = make(jsonschema.Definitions)
+		}
+		defs[defName] = def
+		*s = jsonschema.Schema{
+			Version:     s.Version,
+			ID:          s.ID,
+			Ref:         "#/$defs/" + defName,
+			Definitions: defs,
+		}
+		rf.types[defName] = root
+		return nil
+	}
+	return nil
+}
+
+// refersTo reports whether s or any schema below it refers to ref.
+func refersTo(s *jsonschema.Schema, ref string) bool {
+	found := false
+	_ = walkSchema(s, func(n *jsonschema.Schema) error {
+		found = found || n.Ref == ref
+		return nil
+	})
+	return found
+}
+
+// renameRefs points the references to from in s at to.
+func renameRefs(s *jsonschema.Schema, from, to string) {
+	_ = walkSchema(s, func(n *jsonschema.Schema) error {
+		if n.Ref == from {
+			n.Ref = to
+		}
+		return nil
+	})
+}
+
+// namedRoot returns the root of s as the schema named name, with its
+// self-references pointing at "#/$defs/<name>", for output formats listing
+// every schema by name. The definitions stay in the returned schema.
+func namedRoot(s *jsonschema.Schema, name string) *jsonschema.Schema {
+	if defName, ok := strings.CutPrefix(s.Ref, "#/$defs/"); ok && s.Definitions[defName] != nil && s.Properties == nil {
+		// The root is a definition of its own (WithRecursiveRoot).
+		defs := s.Definitions
+		root := defs[defName]
+		delete(defs, defName)
+		root.Version, root.ID, root.Definitions = s.Version, s.ID, defs
+		s = root
+		renameRefs(s, "#/$defs/"+defName, rootRef)
+	}
+	renameRefs(s, rootRef, "#/$defs/"+name)
+	return s
+}
@@ -0,0 +1,96 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// RecursiveNode is a node of a tree.
+type RecursiveNode struct {
+	Value    int              `json:"value"`
+	Children []*RecursiveNode `json:"children,omitempty"`
+	Parent   *RecursiveNode   `json:"parent,omitempty"`
+}
+
+type RecursiveTree struct {
+	Root RecursiveNode `json:"root"`
+}
+
+func TestRecursiveRoot(t *testing.T) {
+	generate := func(model any, opts ...Option) map[string]any {
+		t.Helper()
+		out, err := NewGenerator(context.Background(), opts...).Generate(model)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	props := func(s any) map[string]any {
+		return s.(map[string]any)["properties"].(map[string]any)
+	}
+
+	doc := generate(RecursiveNode{})
+	if got := compactJSON(props(doc)["children"]); got != `{"items":{"$ref":"#"},"type":"array"}` {
+		t.Errorf("children = %s", got)
+	}
+	if got := compactJSON(props(doc)["parent"]); got != `{"$ref":"#"}` {
+		t.Errorf("parent = %s", got)
+	}
+	if _, ok := doc["$defs"]; ok {
+		t.Errorf("$defs = %v", doc["$defs"])
+	}
+
+	// A recursive type below the root refers to its own definition.
+	doc = generate(RecursiveTree{})
+	node := doc["$defs"].(map[string]any)["RecursiveNode"]
+	if got := compactJSON(props(node)["parent"]); got != `{"$ref":"#/$defs/RecursiveNode"}` {
+		t.Errorf("tree parent = %s", got)
+	}
+
+	for name, want := range map[string]string{"": "RecursiveNode", "Node": "Node"} {
+		doc = generate(RecursiveNode{}, WithRecursiveRoot(name))
+		if got := doc["$ref"]; got != "#/$defs/"+want {
+			t.Errorf("WithRecursiveRoot(%q): $ref = %v", name, got)
+		}
+		def := doc["$defs"].(map[string]any)[want]
+		if got := compactJSON(props(def)["parent"]); got != `{"$ref":"#/$defs/`+want+`"}` {
+			t.Errorf("WithRecursiveRoot(%q): parent = %s", name, got)
+		}
+		if def.(map[string]any)["description"] != "RecursiveNode is a node of a tree." {
+			t.Errorf("WithRecursiveRoot(%q): definition = %v", name, def)
+		}
+	}
+}
+
+func TestRecursiveRootFormats(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithRecursiveRoot("Node")}} {
+		g := NewGenerator(context.Background(), opts...)
+		out, err := g.GenerateOpenAPI30(RecursiveNode{})
+		if err != nil {
+			t.Fatalf("GenerateOpenAPI30() error = %v", err)
+		}
+		var doc openAPI30Document
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Components.Schemas) != 1 {
+			t.Errorf("schemas = %s", out)
+		}
+		if !strings.Contains(string(out), `"$ref": "#/components/schemas/RecursiveNode"`) {
+			t.Errorf("OpenAPI self-reference missing: %s", out)
+		}
+		gql, err := g.GenerateGraphQL(RecursiveNode{})
+		if err != nil {
+			t.Fatalf("GenerateGraphQL() error = %v", err)
+		}
+		if !strings.Contains(string(gql), "parent: RecursiveNode\n") {
+			t.Errorf("GraphQL = %s", gql)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package schemator
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// reflection is a jsonschema.Reflector together with what schemator learned
+// while preparing and running it, used by the passes that post-process the
+// reflected schema.
+type reflection struct {
+	*jsonschema.Reflector
+	// markers are the comment markers of types and fields, keyed like
+	// CommentMap.
+	markers map[string][]string
+	// deprecated holds the "Deprecated:" notes of types and fields, keyed
+	// like markers.
+	deprecated map[string]string
+	// enums holds the exported constants of named types, keyed like
+	// markers.
+	enums map[string][]enumValue
+	// packageDirs maps import paths to their source directories.
+	packageDirs map[string]string
+	// aliases maps the keys of fields declared with a type alias, keyed like
+	// markers, to the alias.
+	aliases map[string]fieldType
+	// types maps the definition names produced by the last reflect to their
+	// Go types.
+	types map[string]reflect.Type
+	// names and owners map struct types to their definition names and
+	// back, telling apart types of different packages with the same name.
+	names  map[reflect.Type]string
+	owners map[string]reflect.Type
+	// collisions describes the struct types that had to be renamed.
+	collisions []string
+	// model is the value passed to the last reflect.
+	model any
+	// warnings are the warnings the passes found.
+	warnings []Warning
+	// dependencies are the external schema files the schema refers to or
+	// embeds.
+	dependencies []string
+	// externalRefs maps the file URIs of the external schema files the schema
+	// refers to by path to the files.
+	externalRefs map[string]string
+}
+
+// schemaPass post-processes a reflected schema.
+type schemaPass func(rf *reflection, s *jsonschema.Schema) error
+
+func newReflection(r *jsonschema.Reflector) *reflection {
+	return &reflection{
+		Reflector:   r,
+		markers:     make(map[string][]string),
+		deprecated:  make(map[string]string),
+		enums:       make(map[string][]enumValue),
+		packageDirs: make(map[string]string),
+		aliases:     make(map[string]fieldType),
+		types:       make(map[string]reflect.Type),
+		names:       make(map[reflect.Type]string),
+		owners:      make(map[string]reflect.Type),
+	}
+}
+
+// passes returns the post-reflection passes in the order they run.
+func (g *generator) passes() []schemaPass {
+	var passes []schemaPass
+	if g.nameCollisions == CollisionError {
+		passes = append(passes, checkNameCollisions)
+	}
+	if g.nameTag == yamlTag || g.nameTag == bsonTag {
+		passes = append(passes, applyLowercaseNames)
+	}
+	passes = append(passes, g.applyUnsupportedKinds, g.applyInternalTypePolicy)
+	if g.requiredPolicy != RequiredExplicit {
+		passes = append(passes, g.applyRequiredPolicy)
+	}
+	passes = append(passes,
+		applyJSONv2Fields,
+		applyConstEnums,
+		applyKubebuilderMarkers,
+		applyValidatorTags,
+		applyExampleTags,
+		applyDefaultTags,
+		g.applyDeprecations,
+		g.applyFreeFormDirectives,
+		applyExternalSchemas,
+		g.applyImplementations,
+		g.applyAdditionalProperties,
+	)
+	if g.inferFormats {
+		passes = append(passes, applyInferredFormats)
+	}
+	passes = append(passes, applySchematorTags, g.applyAnyPolicy)
+	if g.int64Format != Int64Number {
+		passes = append(passes, g.applyInt64Format)
+	}
+	if g.integerBounds {
+		passes = append(passes, applyIntegerBounds)
+	}
+	if g.nullablePointers {
+		passes = append(passes, applyNullablePointers)
+	}
+	if g.embedPolicy != EmbedFlatten {
+		passes = append(passes, g.applyEmbedPolicy)
+	}
+	if g.aliasPolicy == AliasDefinition {
+		passes = append(passes, g.applyAliasDefinitions)
+	}
+	if g.orderExtension {
+		passes = append(passes, applyOrderExtension)
+	}
+	if g.maxDepth > 0 {
+		passes = append(passes, g.applyMaxDepth)
+	}
+	passes = append(passes, g.applySelfReferences)
+	if g.goExtensions {
+		passes = append(passes, g.applyGoExtensions)
+	}
+	if len(g.overrides) > 0 {
+		passes = append(passes, g.applySchemaOverrides)
+	}
+	if len(g.schemaHooks) > 0 {
+		passes = append(passes, g.applySchemaHooks)
+	}
+	return append(passes, g.applyPropertyOrder, checkFields)
+}
+
+func (rf *reflection) reflect(model any) *jsonschema.Schema {
+	namer := rf.Namer
+	rf.Namer = func(t reflect.Type) string {
+		name := ""
+		if namer != nil {
+			name = namer(t)
+		}
+		if name == "" {
+			name = definitionName(t)
+		}
+		name = rf.uniqueName(t, name)
+		if name != "" {
+			rf.types[name] = t
+		}
+		return name
+	}
+	keyNamer := rf.KeyNamer
+	rf.KeyNamer = func(name string) string {
+		name = unquoteKey(name)
+		if keyNamer != nil {
+			name = keyNamer(name)
+		}
+		return name
+	}
+	lookupComment := rf.LookupComment
+	rf.LookupComment = func(t reflect.Type, field string) string {
+		if lookupComment != nil {
+			if doc := lookupComment(t, field); doc != "" {
+				return doc
+			}
+		}
+		// CommentMap is keyed by the generic type, not its instantiations.
+		if genericBase(t) == "" {
+			return ""
+		}
+		key := typeKey(t)
+		if field != "" {
+			key += "." + field
+		}
+		return rf.CommentMap[key]
+	}
+	defer func() { rf.Namer, rf.KeyNamer, rf.LookupComment = namer, keyNamer, lookupComment }()
+	rf.model = model
+	return rf.Reflect(model)
+}
+
+// definitionName returns the name of the definition of t: the name it was
+// reflected under, or the name the Namer or else definitionName gives it.
+func (rf *reflection) definitionName(t reflect.Type) string {
+	if name, ok := rf.names[t]; ok {
+		return name
+	}
+	if rf.Namer != nil {
+		if name := rf.Namer(t); name != "" {
+			return name
+		}
+	}
+	return definitionName(t)
+}
+
+// typeKey returns the CommentMap key of t, or "" for unnamed types. The
+// instantiations of a generic type share the key of the generic type, as
+// they share its comments and markers.
+func typeKey(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	if base := genericBase(t); base != "" {
+		return t.PkgPath() + "." + base
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// typeID returns "<import path>.<Type>" for t, telling the instantiations of
+// generic types apart, or "" for unnamed types.
+func typeID(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// forEachStruct calls fn for the root struct of s and every struct type in
+// s.Definitions, in name order, together with the schema describing it.
+func (rf *reflection) forEachStruct(s *jsonschema.Schema, fn func(t reflect.Type, ts *jsonschema.Schema) error) error {
+	if root := derefType(reflect.TypeOf(rf.model)); root != nil && root.Kind() == reflect.Struct && s.Ref == "" {
+		if err := fn(root, s); err != nil {
+			return err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Definitions)) {
+		def := s.Definitions[name]
+		t := derefType(rf.types[name])
+		if t == nil || def == nil || t.Kind() != reflect.Struct || isBooleanSchema(def) {
+			continue
+		}
+		if err := fn(t, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachType calls fn for the root type of s and every type in
+// s.Definitions, in name order, together with the schema describing it.
+func (rf *reflection) forEachType(s *jsonschema.Schema, fn func(t reflect.Type, ts *jsonschema.Schema) error) error {
+	if root := derefType(reflect.TypeOf(rf.model)); root != nil && s.Ref == "" {
+		if err := fn(root, s); err != nil {
+			return err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Definitions)) {
+		def := s.Definitions[name]
+		t := derefType(rf.types[name])
+		if t == nil || def == nil || isBooleanSchema(def) {
+			continue
+		}
+		if err := fn(t, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldVisit is a struct field together with the property reflected for it.
+type fieldVisit struct {
+	// owner is the struct type declaring field, which differs from the
+	// visited type for fields of embedded structs.
+	owner reflect.Type
+	field reflect.StructField
+	// name is the property name.
+	name string
+	// parent is the object schema holding the property.
+	parent *jsonschema.Schema
+	// schema is the property schema with a nullable oneOf wrapper removed.
+	schema *jsonschema.Schema
+}
+
+// forEachField calls fn for every field of t that has a property in ts,
+// descending into embedded structs the way the Reflector flattens them.
+func (rf *reflection) forEachField(t reflect.Type, ts *jsonschema.Schema, fn func(fieldVisit) error) error {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct || ts.Properties == nil {
+		return nil
+	}
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, t.Field(i))
+	}
+	if rf.AdditionalFields != nil {
+		fields = append(fields, rf.AdditionalFields(t)...)
+	}
+	for _, f := range fields {
+		name, embed := rf.fieldName(f)
+		if name == "" {
+			if embed {
+				if err := rf.forEachField(f.Type, ts, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		prop, ok := ts.Properties.Get(name)
+		if !ok || prop == nil || isBooleanSchema(prop) {
+			continue
+		}
+		if err := fn(fieldVisit{owner: t, field: f, name: name, parent: ts, schema: unwrapNullable(prop)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nameTag returns the struct tag the Reflector reads property names from.
+func (rf *reflection) nameTag() string {
+	if rf.FieldNameTag == "" {
+		return "json"
+	}
+	return rf.FieldNameTag
+}
+
+// fieldName returns the property name the Reflector uses for f, or "" and
+// whether f is an embedded struct whose fields are inherited.
+func (rf *reflection) fieldName(f reflect.StructField) (string, bool) {
+	tagName := rf.nameTag()
+	tagged, opts, err := splitJSONTag(f.Tag.Get(tagName))
+	if err != nil || (tagged == "-" && len(opts) == 0) || slices.Contains(opts, "unknown") {
+		return "", false
+	}
+	jsonTags := append([]string{tagged}, opts...)
+	if schemaTags := strings.Split(f.Tag.Get("jsonschema"), ","); schemaTags[0] == "-" {
+		return "", false
+	}
+	if f.Anonymous && jsonTags[0] == "" {
+		if ft := derefType(f.Type); ft.Kind() == reflect.Struct && (f.Type.Kind() == reflect.Struct || f.Type.Elem().Kind() == reflect.Struct) {
+			return "", true
+		}
+	}
+	for _, opt := range jsonTags[1:] {
+		if opt == "inline" {
+			return "", true
+		}
+	}
+	name := f.Name
+	if jsonTags[0] != "" {
+		name = jsonTags[0]
+	} else if tagName == yamlTag || tagName == bsonTag {
+		name = strings.ToLower(name)
+	}
+	if !f.Anonymous && f.PkgPath != "" {
+		return "", false
+	}
+	if rf.KeyNamer != nil {
+		name = rf.KeyNamer(name)
+	}
+	return name, false
+}
+
+// unwrapNullable returns T for the oneOf [T, {"type": "null"}] wrapper the
+// jsonschema "nullable" tag produces, and s otherwise.
+func unwrapNullable(s *jsonschema.Schema) *jsonschema.Schema {
+	if len(s.OneOf) == 2 && s.OneOf[1] != nil && s.OneOf[1].Type == "null" && s.Type == "" && s.Ref == "" {
+		return s.OneOf[0]
+	}
+	return s
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// removeString returns list without s.
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// appendUnique appends s to list unless it is already present.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// hasDirective reports whether any type or field has the directive name.
+func (rf *reflection) hasDirective(name string) bool {
+	for _, markers := range rf.markers {
+		if len(directiveValues(markers, name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
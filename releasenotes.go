@@ -0,0 +1,190 @@
+package schemator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pkt.systems/logport"
+)
+
+// ReleaseNotesOptions configures ReleaseNotes.
+type ReleaseNotesOptions struct {
+	// RepoDir is a directory inside the git repository (defaults to ".").
+	RepoDir string
+	// OutputDir is the schema output directory relative to the repository
+	// root, e.g. "example/schemas".
+	OutputDir string
+	// Command regenerates the schemas when run from the repository root of a
+	// checkout (defaults to `go generate ./...`). Use an empty, non-nil slice
+	// to compare the committed schema files as they are.
+	Command []string
+	// Heading is the Markdown heading of the section (defaults to "API
+	// schema changes").
+	Heading string
+}
+
+// ReleaseNotes regenerates the schemas at the git refs from and to, diffs
+// them and returns a Markdown section suitable for inclusion in CHANGELOG.md.
+// Each ref is checked out into a temporary git worktree, so the working tree
+// of RepoDir is left untouched.
+func ReleaseNotes(ctx context.Context, from, to string, opts ReleaseNotesOptions) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.OutputDir == "" {
+		return "", fmt.Errorf("release notes require the schema OutputDir")
+	}
+	if opts.RepoDir == "" {
+		opts.RepoDir = "."
+	}
+	if opts.Command == nil {
+		opts.Command = []string{"go", "generate", "./..."}
+	}
+	if opts.Heading == "" {
+		opts.Heading = "API schema changes"
+	}
+	before, err := schemasAtRef(ctx, opts, from)
+	if err != nil {
+		return "", err
+	}
+	after, err := schemasAtRef(ctx, opts, to)
+	if err != nil {
+		return "", err
+	}
+	return renderReleaseNotes(opts.Heading, before, after)
+}
+
+// schemasAtRef returns the *.schema.json files in opts.OutputDir after running
+// opts.Command in a worktree checked out at ref.
+func schemasAtRef(ctx context.Context, opts ReleaseNotesOptions, ref string) (map[string][]byte, error) {
+	l := logport.LoggerFromContext(ctx).With("ref", ref)
+	tmp, err := os.MkdirTemp("", "schemator-notes-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	worktree := filepath.Join(tmp, "worktree")
+	if out, err := runIn(ctx, opts.RepoDir, "git", "worktree", "add", "--detach", worktree, ref); err != nil {
+		return nil, fmt.Errorf("git worktree add %s: %w (output: %s)", ref, err, out)
+	}
+	defer func() {
+		if out, err := runIn(context.Background(), opts.RepoDir, "git", "worktree", "remove", "--force", worktree); err != nil {
+			l.Warn("Unable to remove git worktree", "worktree", worktree, "error", err, "output", string(out))
+		}
+	}()
+	if len(opts.Command) > 0 {
+		l.Debug("Regenerating schemas", "command", opts.Command)
+		if out, err := runIn(ctx, worktree, opts.Command[0], opts.Command[1:]...); err != nil {
+			return nil, fmt.Errorf("regenerate schemas at %s: %w (output: %s)", ref, err, out)
+		}
+	}
+	dir := filepath.Join(worktree, filepath.FromSlash(opts.OutputDir))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]byte{}, nil
+		}
+		return nil, err
+	}
+	schemas := make(map[string][]byte)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".schema.json") {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		schemas[e.Name()] = contents
+	}
+	return schemas, nil
+}
+
+func runIn(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	out, err := cmd.CombinedOutput()
+	return bytes.TrimSpace(out), err
+}
+
+func renderReleaseNotes(heading string, before, after map[string][]byte) (string, error) {
+	files := make([]string, 0, len(before)+len(after))
+	for f := range before {
+		files = append(files, f)
+	}
+	for f := range after {
+		if _, ok := before[f]; !ok {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+
+	var added, removed []string
+	var changed strings.Builder
+	for _, f := range files {
+		oldSchema, inBefore := before[f]
+		newSchema, inAfter := after[f]
+		switch {
+		case !inBefore:
+			added = append(added, f)
+			continue
+		case !inAfter:
+			removed = append(removed, f)
+			continue
+		}
+		var oldDoc, newDoc any
+		if err := json.Unmarshal(oldSchema, &oldDoc); err != nil {
+			return "", fmt.Errorf("parse previous %s: %w", f, err)
+		}
+		if err := json.Unmarshal(newSchema, &newDoc); err != nil {
+			return "", fmt.Errorf("parse current %s: %w", f, err)
+		}
+		var drifts []Drift
+		diffJSON(f, "", newDoc, oldDoc, &drifts)
+		if len(drifts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&changed, "\n#### `%s`\n\n", f)
+		for _, d := range drifts {
+			fmt.Fprintf(&changed, "- `%s`: %s\n", pointerOrRoot(d.Pointer), d.Message)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", heading)
+	if len(added) == 0 && len(removed) == 0 && changed.Len() == 0 {
+		b.WriteString("\nNo API schema changes.\n")
+		return b.String(), nil
+	}
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "- `%s`\n", item)
+		}
+	}
+	writeList("New schemas", added)
+	writeList("Removed schemas", removed)
+	if changed.Len() > 0 {
+		b.WriteString("\n### Changed schemas\n")
+		b.WriteString(changed.String())
+	}
+	return b.String(), nil
+}
+
+func pointerOrRoot(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
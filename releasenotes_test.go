@@ -0,0 +1,69 @@
+package schemator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	writeFile(t, filepath.Join(repo, "schemas", "Subject.schema.json"), `{"properties":{"id":{"type":"integer"}}}`)
+	writeFile(t, filepath.Join(repo, "schemas", "Old.schema.json"), `{}`)
+	git("add", "-A")
+	git("commit", "-qm", "v1")
+	git("tag", "v1")
+	writeFile(t, filepath.Join(repo, "schemas", "Subject.schema.json"), `{"properties":{"id":{"type":"string"},"email":{"type":"string"}}}`)
+	writeFile(t, filepath.Join(repo, "schemas", "New.schema.json"), `{}`)
+	git("rm", "-q", "schemas/Old.schema.json")
+	git("add", "-A")
+	git("commit", "-qm", "v2")
+
+	notes, err := ReleaseNotes(context.Background(), "v1", "HEAD", ReleaseNotesOptions{
+		RepoDir:   repo,
+		OutputDir: "schemas",
+		Command:   []string{},
+	})
+	if err != nil {
+		t.Fatalf("ReleaseNotes() error = %v", err)
+	}
+	for _, want := range []string{
+		"## API schema changes",
+		"### New schemas\n\n- `New.schema.json`",
+		"### Removed schemas\n\n- `Old.schema.json`",
+		"#### `Subject.schema.json`",
+		"- `/properties/email`: added",
+		"- `/properties/id/type`: changed from \"integer\" to \"string\"",
+	} {
+		if !strings.Contains(notes, want) {
+			t.Fatalf("release notes missing %q:\n%s", want, notes)
+		}
+	}
+
+	same, err := ReleaseNotes(context.Background(), "HEAD", "HEAD", ReleaseNotesOptions{RepoDir: repo, OutputDir: "schemas", Command: []string{}})
+	if err != nil {
+		t.Fatalf("ReleaseNotes() error = %v", err)
+	}
+	if !strings.Contains(same, "No API schema changes.") {
+		t.Fatalf("expected no changes, got:\n%s", same)
+	}
+}
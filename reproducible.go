@@ -0,0 +1,27 @@
+package schemator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sourceDateEpochEnv is the environment variable of reproducible builds
+// fixing the timestamps recorded in build artifacts,
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// sourceDate returns the time recorded in the manifest for the artifacts
+// being written: SOURCE_DATE_EPOCH if set, otherwise the current time.
+func sourceDate() (time.Time, error) {
+	epoch, ok := os.LookupEnv(sourceDateEpochEnv)
+	if !ok || epoch == "" {
+		return time.Now().UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be a non-negative number of seconds", sourceDateEpochEnv, epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
@@ -0,0 +1,55 @@
+package schemator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv(sourceDateEpochEnv, "1700000000")
+	gen := NewGenerator(context.Background(), WithVersion("1.0.0"), WithProvenance())
+	var dirs []string
+	for range 2 {
+		dir := t.TempDir()
+		if err := gen.WriteSchemas(dir, NamerCustomer{}, GenericListings{}, DepthRoot{}); err != nil {
+			t.Fatalf("WriteSchemas() error = %v", err)
+		}
+		dirs = append(dirs, dir)
+	}
+	m, err := ReadManifest(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range m.Artifacts {
+		if !a.Created.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("%s created %v", a.File, a.Created)
+		}
+	}
+	entries, err := os.ReadDir(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		first, err := os.ReadFile(filepath.Join(dirs[0], e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := os.ReadFile(filepath.Join(dirs[1], e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Errorf("%s differs between runs:\n%s\n%s", e.Name(), first, second)
+		}
+	}
+
+	t.Setenv(sourceDateEpochEnv, "yesterday")
+	if err := gen.WriteSchemas(t.TempDir(), NamerCustomer{}); err == nil || !strings.Contains(err.Error(), `invalid SOURCE_DATE_EPOCH "yesterday"`) {
+		t.Errorf("WriteSchemas() error = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// RequiredPolicy selects which properties of an object are required. With
+// every policy a `jsonschema:"required"` tag makes its field required, and
+// kubebuilder markers and validate tags are applied on top.
+type RequiredPolicy int
+
+const (
+	// RequiredWithoutOmitempty requires every field without omitempty or
+	// omitzero in its json tag (default).
+	RequiredWithoutOmitempty RequiredPolicy = iota
+	// RequiredNonPointer requires every field that is not a pointer,
+	// whether or not it has omitempty.
+	RequiredNonPointer
+	// RequiredExplicit requires only the fields tagged
+	// `jsonschema:"required"`.
+	RequiredExplicit
+	// RequiredAll requires every field.
+	RequiredAll
+)
+
+func (p RequiredPolicy) String() string {
+	switch p {
+	case RequiredWithoutOmitempty:
+		return "without-omitempty"
+	case RequiredNonPointer:
+		return "non-pointer"
+	case RequiredExplicit:
+		return "explicit"
+	case RequiredAll:
+		return "all"
+	}
+	return fmt.Sprintf("RequiredPolicy(%d)", int(p))
+}
+
+// applyRequiredPolicy is the schema pass recomputing the required
+// properties of every struct. The Reflector implements RequiredExplicit
+// itself, and RequiredWithoutOmitempty except for omitzero.
+func (g *generator) applyRequiredPolicy(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		tagName := rf.nameTag()
+		var required []string
+		err := rf.forEachField(t, ts, func(fv fieldVisit) error {
+			_, opts, _ := splitJSONTag(fv.field.Tag.Get(tagName))
+			if g.requiredPolicy.requires(fv.field, opts) {
+				required = appendUnique(required, fv.name)
+			}
+			return nil
+		})
+		ts.Required = required
+		return err
+	})
+}
+
+// requires reports whether p makes f, with json tag options opts, required.
+func (p RequiredPolicy) requires(f reflect.StructField, opts []string) bool {
+	if slices.Contains(strings.Split(f.Tag.Get("jsonschema"), ","), "required") {
+		return true
+	}
+	switch p {
+	case RequiredWithoutOmitempty:
+		return !slices.Contains(opts, "omitempty") && !slices.Contains(opts, "omitzero")
+	case RequiredNonPointer:
+		return f.Type.Kind() != reflect.Pointer
+	case RequiredAll:
+		return true
+	}
+	return false
+}
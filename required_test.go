@@ -0,0 +1,47 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// RequiredBase is embedded in RequiredOrder.
+type RequiredBase struct {
+	ID string `json:"id"`
+}
+
+// RequiredOrder has fields of every required-ness.
+type RequiredOrder struct {
+	RequiredBase
+	Name     string  `json:"name"`
+	Note     string  `json:"note,omitempty"`
+	Coupon   *string `json:"coupon"`
+	Discount *int    `json:"discount,omitempty" jsonschema:"required"`
+	// +optional
+	Comment string `json:"comment"`
+}
+
+func TestRequiredPolicy(t *testing.T) {
+	for policy, want := range map[RequiredPolicy][]string{
+		RequiredWithoutOmitempty: {"id", "name", "coupon", "discount"},
+		RequiredNonPointer:       {"id", "name", "note", "discount"},
+		RequiredExplicit:         {"discount"},
+		RequiredAll:              {"id", "name", "note", "coupon", "discount"},
+	} {
+		out, err := NewGenerator(context.Background(), WithRequiredPolicy(policy)).Generate(RequiredOrder{})
+		if err != nil {
+			t.Fatalf("Generate(%s) error = %v", policy, err)
+		}
+		var doc struct {
+			Required []string `json:"required"`
+		}
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(doc.Required, want) {
+			t.Errorf("%s: required = %v, want %v", policy, doc.Required, want)
+		}
+	}
+}
@@ -0,0 +1,503 @@
+package schemamock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// maxDepth bounds recursion through recursive types: deeper objects only get
+// their required properties and arrays their minimum number of items.
+const maxDepth = 5
+
+// Payload returns a random value valid against the JSON schema document
+// schema. Local references ("#/$defs/...") are resolved; formats, patterns,
+// bounds, enums and examples are honoured on a best effort basis.
+func Payload(schema []byte, r *rand.Rand) (any, error) {
+	var root map[string]any
+	d := json.NewDecoder(strings.NewReader(string(schema)))
+	d.UseNumber()
+	if err := d.Decode(&root); err != nil {
+		return nil, err
+	}
+	p := &payloader{root: root, r: r}
+	return p.value(root, 0)
+}
+
+type payloader struct {
+	root map[string]any
+	r    *rand.Rand
+}
+
+func (p *payloader) value(node any, depth int) (any, error) {
+	s, ok := node.(map[string]any)
+	if !ok {
+		// true, false or a missing schema.
+		if b, isBool := node.(bool); isBool && !b {
+			return nil, fmt.Errorf("no value satisfies the false schema")
+		}
+		return p.word(3, 8), nil
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := p.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		return p.value(target, depth)
+	}
+	if v, ok := s["const"]; ok {
+		return v, nil
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[p.r.IntN(len(enum))], nil
+	}
+	if examples, ok := s["examples"].([]any); ok && len(examples) > 0 {
+		return examples[p.r.IntN(len(examples))], nil
+	}
+	if allOf, ok := s["allOf"].([]any); ok && len(allOf) > 0 {
+		return p.value(p.mergeAllOf(s, allOf), depth)
+	}
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if branches, ok := s[k].([]any); ok && len(branches) > 0 {
+			// Prefer non-null branches, nullable fields are mostly set.
+			branch := branches[p.r.IntN(len(branches))]
+			if isNullSchema(branch) && len(branches) > 1 && p.r.IntN(4) > 0 {
+				for _, b := range branches {
+					if !isNullSchema(b) {
+						branch = b
+						break
+					}
+				}
+			}
+			return p.value(branch, depth)
+		}
+	}
+	switch schemaType(s) {
+	case "object":
+		return p.object(s, depth)
+	case "array":
+		return p.array(s, depth)
+	case "string":
+		return p.string(s)
+	case "integer":
+		return p.integer(s)
+	case "number":
+		return p.number(s)
+	case "boolean":
+		return p.r.IntN(2) == 1, nil
+	case "null":
+		return nil, nil
+	}
+	return p.word(3, 8), nil
+}
+
+func (p *payloader) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported reference %q, only local references are resolved", ref)
+	}
+	var node any = p.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+		if node, ok = m[token]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// mergeAllOf folds the keywords of the allOf branches into a copy of s.
+// Keywords already set win, so only one of several patterns is honoured.
+func (p *payloader) mergeAllOf(s map[string]any, allOf []any) map[string]any {
+	merged := make(map[string]any, len(s))
+	for k, v := range s {
+		if k != "allOf" {
+			merged[k] = v
+		}
+	}
+	for _, branch := range allOf {
+		b, ok := branch.(map[string]any)
+		if !ok {
+			continue
+		}
+		if ref, ok := b["$ref"].(string); ok {
+			if target, err := p.resolve(ref); err == nil {
+				if t, ok := target.(map[string]any); ok {
+					b = t
+				}
+			}
+		}
+		for k, v := range b {
+			if _, set := merged[k]; !set {
+				merged[k] = v
+			}
+		}
+	}
+	return merged
+}
+
+func isNullSchema(node any) bool {
+	s, ok := node.(map[string]any)
+	return ok && s["type"] == "null"
+}
+
+// schemaType returns the type of s, inferred from its keywords if absent.
+func schemaType(s map[string]any) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+		return "null"
+	}
+	switch {
+	case s["properties"] != nil || s["additionalProperties"] != nil:
+		return "object"
+	case s["items"] != nil || s["prefixItems"] != nil:
+		return "array"
+	}
+	return ""
+}
+
+func (p *payloader) object(s map[string]any, depth int) (any, error) {
+	out := make(map[string]any)
+	required := make(map[string]bool)
+	if req, ok := s["required"].([]any); ok {
+		for _, name := range req {
+			if n, ok := name.(string); ok {
+				required[n] = true
+			}
+		}
+	}
+	props, _ := s["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	// Sorted, so a seed reproduces the payload.
+	sort.Strings(names)
+	for _, name := range names {
+		prop := props[name]
+		if !required[name] && (depth >= maxDepth || p.r.IntN(4) == 0) {
+			continue
+		}
+		v, err := p.value(prop, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[name] = v
+	}
+	if extra, ok := s["additionalProperties"].(map[string]any); ok && len(props) == 0 {
+		n := p.count(s, "minProperties", "maxProperties", 1, 3, depth)
+		keys, _ := s["propertyNames"].(map[string]any)
+		for i := 0; i < 2*n+10 && len(out) < n; i++ {
+			key := fmt.Sprintf("key%d", len(out)+1)
+			if keys != nil {
+				k, err := p.string(keys)
+				if err != nil {
+					return nil, err
+				}
+				key = k.(string)
+			}
+			v, err := p.value(extra, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = v
+		}
+	}
+	return out, nil
+}
+
+func (p *payloader) array(s map[string]any, depth int) (any, error) {
+	out := []any{}
+	prefix, _ := s["prefixItems"].([]any)
+	for _, item := range prefix {
+		v, err := p.value(item, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	items, ok := s["items"]
+	if !ok || items == false {
+		return out, nil
+	}
+	n := p.count(s, "minItems", "maxItems", 1, 3, depth)
+	unique := s["uniqueItems"] == true
+	seen := make(map[string]bool)
+	for attempts := 0; len(out) < n && attempts < 10*n+10; attempts++ {
+		v, err := p.value(items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if unique {
+			key, _ := json.Marshal(v)
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// count picks a size within the minKey and maxKey bounds of s, preferring
+// lo..hi, or the minimum beyond maxDepth.
+func (p *payloader) count(s map[string]any, minKey, maxKey string, lo, hi, depth int) int {
+	minimum, hasMin := intKeyword(s, minKey)
+	maximum, hasMax := intKeyword(s, maxKey)
+	if !hasMin {
+		minimum = 0
+	}
+	if depth >= maxDepth {
+		return minimum
+	}
+	lo, hi = max(lo, minimum), max(hi, minimum)
+	if hasMax {
+		lo, hi = min(lo, maximum), min(hi, maximum)
+	}
+	return lo + p.r.IntN(hi-lo+1)
+}
+
+func intKeyword(s map[string]any, key string) (int, bool) {
+	n, ok := s[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	return int(i), err == nil
+}
+
+func floatKeyword(s map[string]any, key string) (float64, bool) {
+	n, ok := s[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func (p *payloader) string(s map[string]any) (any, error) {
+	if format, ok := s["format"].(string); ok {
+		if v, ok := p.format(format); ok {
+			return v, nil
+		}
+	}
+	minLen, hasMin := intKeyword(s, "minLength")
+	maxLen, hasMax := intKeyword(s, "maxLength")
+	if !hasMin {
+		minLen = 0
+	}
+	if !hasMax {
+		maxLen = max(minLen, 12)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		re = re.Simplify()
+		var last string
+		for range 20 {
+			var b strings.Builder
+			p.regexp(&b, re)
+			last = b.String()
+			if n := len([]rune(last)); n >= minLen && n <= maxLen {
+				break
+			}
+		}
+		return last, nil
+	}
+	lo, hi := max(minLen, min(3, maxLen)), min(maxLen, max(minLen, 12))
+	return p.word(lo, hi), nil
+}
+
+// word returns lower case letters, between lo and hi of them.
+func (p *payloader) word(lo, hi int) string {
+	n := lo
+	if hi > lo {
+		n += p.r.IntN(hi - lo + 1)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + p.r.IntN(26))
+	}
+	return string(b)
+}
+
+func (p *payloader) format(format string) (string, bool) {
+	ts := time.Date(2000+p.r.IntN(30), time.Month(1+p.r.IntN(12)), 1+p.r.IntN(28), p.r.IntN(24), p.r.IntN(60), p.r.IntN(60), 0, time.UTC)
+	switch format {
+	case "date-time":
+		return ts.Format(time.RFC3339), true
+	case "date":
+		return ts.Format(time.DateOnly), true
+	case "time":
+		return ts.Format("15:04:05Z"), true
+	case "email":
+		return p.word(3, 8) + "@example.com", true
+	case "hostname":
+		return p.word(3, 8) + ".example.com", true
+	case "uri", "uri-reference", "iri":
+		return (&url.URL{Scheme: "https", Host: "example.com", Path: "/" + p.word(3, 8)}).String(), true
+	case "uuid":
+		return uuid.Must(uuid.NewRandomFromReader(p.reader())).String(), true
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+p.r.IntN(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+p.r.IntN(0xfffe)), true
+	case "duration":
+		return fmt.Sprintf("PT%dM", 1+p.r.IntN(59)), true
+	}
+	return "", false
+}
+
+// reader adapts the random source to io.Reader for uuid.
+func (p *payloader) reader() randReader { return randReader{p.r} }
+
+type randReader struct{ r *rand.Rand }
+
+func (rr randReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = byte(rr.r.Uint32())
+	}
+	return len(b), nil
+}
+
+// regexp writes a random string matching re.
+func (p *payloader) regexp(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(p.classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(byte('a' + p.r.IntN(26)))
+	case syntax.OpCapture:
+		p.regexp(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			p.regexp(b, sub)
+		}
+	case syntax.OpAlternate:
+		p.regexp(b, re.Sub[p.r.IntN(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, 3
+		case syntax.OpPlus:
+			lo, hi = 1, 4
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 {
+			hi = lo + 3
+		}
+		for n := lo + p.r.IntN(hi-lo+1); n > 0; n-- {
+			p.regexp(b, re.Sub[0])
+		}
+	}
+}
+
+// classRune picks a rune from a character class given as [lo, hi] pairs,
+// preferring printable ASCII.
+func (p *payloader) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '+1), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) == 0 {
+		printable = ranges
+	}
+	if len(printable) == 0 {
+		return 'a'
+	}
+	i := 2 * p.r.IntN(len(printable)/2)
+	lo, hi := printable[i], printable[i+1]
+	r := lo + rune(p.r.IntN(int(hi-lo)+1))
+	if !unicode.IsPrint(r) {
+		return lo
+	}
+	return r
+}
+
+// bounds returns the inclusive range allowed by s, defaulting to lo..hi.
+func bounds(s map[string]any, lo, hi, step float64) (float64, float64) {
+	if v, ok := floatKeyword(s, "minimum"); ok {
+		lo = v
+		hi = max(hi, lo)
+	}
+	if v, ok := floatKeyword(s, "exclusiveMinimum"); ok {
+		lo = v + step
+		hi = max(hi, lo)
+	}
+	if v, ok := floatKeyword(s, "maximum"); ok {
+		hi = v
+		lo = min(lo, hi)
+	}
+	if v, ok := floatKeyword(s, "exclusiveMaximum"); ok {
+		hi = v - step
+		lo = min(lo, hi)
+	}
+	return lo, hi
+}
+
+func (p *payloader) integer(s map[string]any) (any, error) {
+	lo, hi := bounds(s, 0, 1000, 1)
+	lo, hi = math.Ceil(lo), math.Floor(hi)
+	if lo > hi {
+		return nil, fmt.Errorf("no integer between %v and %v", lo, hi)
+	}
+	step := 1.0
+	if m, ok := floatKeyword(s, "multipleOf"); ok && m > 0 {
+		step = m
+		lo = math.Ceil(lo/m) * m
+	}
+	steps := int64((hi - lo) / step)
+	n := lo
+	if steps > 0 {
+		n += float64(p.r.Int64N(min(steps, 1<<31)+1)) * step
+	}
+	return json.Number(strconv.FormatFloat(n, 'f', -1, 64)), nil
+}
+
+func (p *payloader) number(s map[string]any) (any, error) {
+	lo, hi := bounds(s, 0, 1000, 0.01)
+	if lo > hi {
+		return nil, fmt.Errorf("no number between %v and %v", lo, hi)
+	}
+	if m, ok := floatKeyword(s, "multipleOf"); ok && m > 0 {
+		k := math.Ceil(lo / m)
+		n := k + float64(p.r.Int64N(int64(math.Max(0, math.Floor(hi/m)-k))+1))
+		return json.Number(strconv.FormatFloat(n*m, 'f', -1, 64)), nil
+	}
+	n := math.Round((lo+p.r.Float64()*(hi-lo))*100) / 100
+	return json.Number(strconv.FormatFloat(math.Min(math.Max(n, lo), hi), 'f', -1, 64)), nil
+}
@@ -0,0 +1,164 @@
+// Package schemamock serves random, schema-valid payloads for Go models so
+// frontend teams can develop against a contract before the backend exists:
+//
+//	srv := schemamock.New(ctx)
+//	if err := srv.Handle("GET /api/subjects/{id}", example.Subject{}); err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(http.ListenAndServe(":8080", srv))
+//
+//	curl 'http://localhost:8080/api/subjects/1?count=3&seed=42'
+//
+// Every response is generated from the model's JSON schema. The count query
+// parameter returns an array of payloads and seed makes responses
+// reproducible.
+package schemamock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"pkt.systems/logport"
+	"pkt.systems/schemator"
+)
+
+// MaxCount is the largest count a request may ask for.
+const MaxCount = 100
+
+// Server is an http.Handler serving mock payloads at the routes registered
+// with Handle.
+type Server struct {
+	ctx     context.Context
+	options []schemator.Option
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	routes  map[string]schemator.SchemaBytes
+	seeds   *rand.Rand
+}
+
+// New returns a Server generating schemas with opts.
+func New(ctx context.Context, opts ...schemator.Option) *Server {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Server{
+		ctx:     ctx,
+		options: opts,
+		mux:     http.NewServeMux(),
+		routes:  make(map[string]schemator.SchemaBytes),
+		seeds:   rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// Handle serves payloads for model at pattern, an http.ServeMux pattern
+// such as "GET /api/subjects/{id}". The schema is generated once, here.
+func (s *Server) Handle(pattern string, model any) error {
+	out, err := schemator.NewGenerator(s.ctx, s.options...).Generate(model)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pattern, err)
+	}
+	// Fail at registration rather than on the first request.
+	if _, err := Payload(out, rand.New(rand.NewPCG(0, 0))); err != nil {
+		return fmt.Errorf("%s: %w", pattern, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.routes[pattern]; exists {
+		return fmt.Errorf("route %s is already registered", pattern)
+	}
+	s.routes[pattern] = out
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.serve(w, r, pattern, out)
+	})
+	return nil
+}
+
+// Routes returns the registered patterns in sorted order.
+func (s *Server) Routes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routes := make([]string, 0, len(s.routes))
+	for pattern := range s.routes {
+		routes = append(routes, pattern)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// Schema returns the schema served at pattern.
+func (s *Server) Schema(pattern string) (schemator.SchemaBytes, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out, ok := s.routes[pattern]
+	return out, ok
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, pattern string, schema []byte) {
+	l := logport.LoggerFromContext(s.ctx).With("method", r.Method, "path", r.URL.Path, "route", pattern)
+	q := r.URL.Query()
+	count := 0
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > MaxCount {
+			httpError(w, http.StatusBadRequest, fmt.Sprintf("count must be an integer between 0 and %d", MaxCount))
+			return
+		}
+		count = n
+	}
+	var seed uint64
+	if v := q.Get("seed"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, "seed must be an unsigned integer")
+			return
+		}
+		seed = n
+	} else {
+		s.mu.Lock()
+		seed = s.seeds.Uint64()
+		s.mu.Unlock()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	var body any
+	var err error
+	if count == 0 && !q.Has("count") {
+		body, err = Payload(schema, rng)
+	} else {
+		items := make([]any, 0, count)
+		for range count {
+			var v any
+			if v, err = Payload(schema, rng); err != nil {
+				break
+			}
+			items = append(items, v)
+		}
+		body = items
+	}
+	if err != nil {
+		l.Error("Unable to generate payload", "error", err)
+		httpError(w, http.StatusInternalServerError, "payload generation failed")
+		return
+	}
+	w.Header().Set("X-Mock-Seed", strconv.FormatUint(seed, 10))
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,129 @@
+package schemamock
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"regexp"
+	"testing"
+	"time"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestServerServesPayloads(t *testing.T) {
+	srv := New(context.Background())
+	if err := srv.Handle("GET /subjects/{id}", example.Subject{}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if err := srv.Handle("GET /subjects/{id}", example.Subject{}); err == nil {
+		t.Fatal("Handle() of a registered route error = nil")
+	}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+	rec := get("/subjects/1?seed=7")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var subject struct {
+		ID          *int      `json:"id"`
+		Name        *string   `json:"name"`
+		Tags        []string  `json:"tags"`
+		DateOfBirth time.Time `json:"dateOfBirth"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &subject); err != nil {
+		t.Fatalf("payload does not decode into Subject: %v (%s)", err, rec.Body)
+	}
+	// Every Subject property is required.
+	if subject.ID == nil || subject.Name == nil || subject.Tags == nil || subject.DateOfBirth.IsZero() {
+		t.Fatalf("payload misses required properties: %s", rec.Body)
+	}
+	if again := get("/subjects/1?seed=7"); again.Body.String() != rec.Body.String() {
+		t.Fatalf("seeded responses differ:\n%s\n%s", rec.Body, again.Body)
+	}
+
+	rec = get("/subjects/2?count=3")
+	var subjects []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &subjects); err != nil || len(subjects) != 3 {
+		t.Fatalf("count=3 response = %s (%v)", rec.Body, err)
+	}
+	if rec := get("/subjects/1?count=1000"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("count=1000 status = %d", rec.Code)
+	}
+	if rec := get("/missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unregistered route status = %d", rec.Code)
+	}
+	if routes := srv.Routes(); len(routes) != 1 || routes[0] != "GET /subjects/{id}" {
+		t.Fatalf("Routes() = %v", routes)
+	}
+}
+
+func TestPayloadHonoursConstraints(t *testing.T) {
+	schema := `{
+  "$ref": "#/$defs/Account",
+  "$defs": {
+    "Account": {
+      "type": "object",
+      "properties": {
+        "email": {"type": "string", "format": "email"},
+        "code": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{2,4}$"},
+        "name": {"type": "string", "minLength": 2, "maxLength": 4},
+        "age": {"type": "integer", "minimum": 18, "exclusiveMaximum": 21},
+        "ratio": {"type": "number", "minimum": 0, "maximum": 1},
+        "even": {"type": "integer", "multipleOf": 2, "minimum": 1, "maximum": 9},
+        "kind": {"enum": ["a", "b"]},
+        "tags": {"type": "array", "items": {"enum": ["x", "y"]}, "minItems": 2, "maxItems": 2, "uniqueItems": true},
+        "parent": {"oneOf": [{"$ref": "#/$defs/Account"}, {"type": "null"}]}
+      },
+      "required": ["email", "code", "name", "age", "ratio", "even", "kind", "tags"]
+    }
+  }
+}`
+	code := regexp.MustCompile(`^[A-Z]{3}-[0-9]{2,4}$`)
+	for seed := range uint64(50) {
+		v, err := Payload([]byte(schema), rand.New(rand.NewPCG(seed, seed)))
+		if err != nil {
+			t.Fatalf("Payload() error = %v", err)
+		}
+		b, _ := json.Marshal(v)
+		var a struct {
+			Email string   `json:"email"`
+			Code  string   `json:"code"`
+			Name  string   `json:"name"`
+			Age   int      `json:"age"`
+			Ratio float64  `json:"ratio"`
+			Even  int      `json:"even"`
+			Kind  string   `json:"kind"`
+			Tags  []string `json:"tags"`
+		}
+		if err := json.Unmarshal(b, &a); err != nil {
+			t.Fatalf("payload %s: %v", b, err)
+		}
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			t.Fatalf("email %q: %v", a.Email, err)
+		}
+		switch {
+		case !code.MatchString(a.Code):
+			t.Fatalf("code %q does not match the pattern", a.Code)
+		case len(a.Name) < 2 || len(a.Name) > 4:
+			t.Fatalf("name %q violates the length bounds", a.Name)
+		case a.Age < 18 || a.Age >= 21:
+			t.Fatalf("age %d out of bounds", a.Age)
+		case a.Ratio < 0 || a.Ratio > 1:
+			t.Fatalf("ratio %v out of bounds", a.Ratio)
+		case a.Even%2 != 0 || a.Even < 1 || a.Even > 9:
+			t.Fatalf("even = %d", a.Even)
+		case a.Kind != "a" && a.Kind != "b":
+			t.Fatalf("kind = %q", a.Kind)
+		case len(a.Tags) != 2 || a.Tags[0] == a.Tags[1]:
+			t.Fatalf("tags = %v", a.Tags)
+		}
+	}
+}
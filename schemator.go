@@ -8,21 +8,23 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"go/parser"
 	"go/token"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/invopop/jsonschema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"pkt.systems/logport"
 )
 
@@ -31,11 +33,7 @@ import (
 // ImportPath.SourceDirectory, defaults to `./`. This works most of the time,
 // but not for additional external modules you want to generate schemas for.
 func New(ctx context.Context, filesThatMustExist []string, ImportPaths ...ImportPath) Generator {
-	return &generator{
-		ctx:                ctx,
-		filesThatMustExist: filesThatMustExist,
-		importPaths:        ImportPaths,
-	}
+	return NewGenerator(ctx, WithFilesThatMustExist(filesThatMustExist...), WithImportPaths(ImportPaths...))
 }
 
 // If you provide your own ImportPaths and not letting them be automatically
@@ -96,12 +94,86 @@ type Generator interface {
 	// Generate generates a JSON schema from concrete type model and returns a
 	// renedered json schema in SchemaBytes or error if something failed.
 	Generate(model any) (SchemaBytes, error)
+	// GenerateAll generates the JSON schema of every model mentioned, keyed
+	// by the filename WriteSchemas would write it to.
+	GenerateAll(models ...any) (map[string]SchemaBytes, error)
 	// WriteSchema generates a JSON schema from concrete type model and writes a
 	// rendered json schema to filenamePath.
 	WriteSchema(model any, filenamePath string) error
 	// WriteSchemas writes every model mentioned into auto-generated filenames
 	// inside outputDir.
 	WriteSchemas(outputDir string, models ...any) error
+	// WriteNamedSchemas writes the schema of every model mentioned to
+	// <Name>[.<version>].schema.json inside outputDir, for models such as
+	// anonymous structs and generic wrappers that have no fitting name of
+	// their own. Names leading outside outputDir are rejected.
+	WriteNamedSchemas(outputDir string, named ...Named) error
+	// WriteSchemasFS writes every model mentioned into auto-generated
+	// filenames inside dir of fsys instead of the local file system. Unlike
+	// WriteSchemas it writes neither a manifest nor an index.
+	WriteSchemasFS(fsys WriteFS, dir string, models ...any) error
+	// WriteSchemasArchive writes the schema of every model mentioned, under
+	// the filenames WriteSchemas would use, and a manifest into a gzipped
+	// tar archive at archivePath.
+	WriteSchemasArchive(archivePath string, models ...any) error
+	// Verify regenerates the schema of every model and compares it to the
+	// file WriteSchemas would have written inside outputDir. Differences not
+	// covered by a suppression are returned as a *DriftError.
+	Verify(outputDir string, models ...any) error
+	// GenerateXSD generates an XML Schema (XSD) document for model driven by
+	// its `xml:"..."` struct tags.
+	GenerateXSD(model any) (SchemaBytes, error)
+	// GenerateGraphQL generates GraphQL SDL type definitions for model and
+	// every type it references, with Go doc comments as descriptions.
+	GenerateGraphQL(model any) (SchemaBytes, error)
+	// GenerateCRD generates a Kubernetes CustomResourceDefinition manifest
+	// (YAML) for gvk with a structural OpenAPI v3 schema built from the spec
+	// and (optional) status models.
+	GenerateCRD(gvk schema.GroupVersionKind, spec, status any) (SchemaBytes, error)
+	// WriteCRD generates a CustomResourceDefinition manifest like GenerateCRD
+	// and writes it to filenamePath.
+	WriteCRD(gvk schema.GroupVersionKind, spec, status any, filenamePath string) error
+	// GenerateHelmValuesSchema generates a Helm values.schema.json document
+	// for the chart values model: draft-07 dialect, no $ref and lowerCamel
+	// property names.
+	GenerateHelmValuesSchema(model any) (SchemaBytes, error)
+	// WriteHelmValuesSchema writes GenerateHelmValuesSchema output to
+	// values.schema.json next to Chart.yaml in chartDir.
+	WriteHelmValuesSchema(model any, chartDir string) error
+	// WriteAll writes every output in the formats configured for its model
+	// into outputDir and records each file in the manifest.
+	WriteAll(outputDir string, outputs ...Output) error
+	// DescribeType returns the reflected structure of model (fields, Go
+	// types, tags, doc comments and package versions) independent of JSON
+	// Schema, for other emitters and analysis tools.
+	DescribeType(model any) (*TypeDescriptor, error)
+	// SuggestConstraints lists formats and constraints suggested by the names
+	// and types of model's fields (Email → format email, CreatedAt →
+	// date-time, ...) that the schema does not have yet. WithInferredFormats
+	// applies them.
+	SuggestConstraints(model any) ([]Suggestion, error)
+	// GenerateProtoJSON generates the JSON schema of a protoc-gen-go message
+	// type as Connect's JSON codec (protojson) encodes it.
+	GenerateProtoJSON(model any) (SchemaBytes, error)
+	// WriteConnectSchemas writes the request and response schemas of every
+	// Connect-RPC procedure to <outputDir>/<service>/<method>.{request,
+	// response}.schema.json, and a routing manifest to
+	// <outputDir>/connect-routes.json.
+	WriteConnectSchemas(outputDir string, procedures ...Procedure) error
+	// AnalyzeDraft reports the oldest JSON Schema draft the schema of model
+	// can be expressed in, and which keywords a downgrade would lose.
+	AnalyzeDraft(model any) (*DraftReport, error)
+	// GenerateOpenAPI30 generates the schemas of model and every type it
+	// references as OpenAPI 3.0 components: nullable fields use
+	// `nullable: true` and keywords OpenAPI 3.0 does not know are dropped.
+	GenerateOpenAPI30(model any) (SchemaBytes, error)
+}
+
+// Named is a model WriteNamedSchemas writes under Name instead of the name
+// of its type.
+type Named struct {
+	Name  string
+	Model any
 }
 
 type SchemaBytes []byte
@@ -112,13 +184,201 @@ func (b SchemaBytes) String() string {
 
 // Implements Generator
 type generator struct {
-	ctx                context.Context
-	filesThatMustExist []string
-	importPaths        []ImportPath
+	ctx                  context.Context
+	filesThatMustExist   []string
+	importPaths          []ImportPath
+	suppressionFile      string
+	statusFile           string
+	badgeFile            string
+	inferFormats         bool
+	internalTypes        InternalTypePolicy
+	deprecationReasons   bool
+	orderExtension       bool
+	propertyOrder        PropertyOrder
+	indent               string
+	compact              bool
+	trailingNewline      bool
+	perms                permissions
+	filenameFunc         func(model any) string
+	filenameCollisions   FilenameCollisionPolicy
+	layout               OutputLayout
+	manifest             bool
+	pruneStale           bool
+	pruneDryRun          bool
+	dryRun               bool
+	dryRunReport         func(PlannedWrite)
+	concurrency          int
+	packageCache         string
+	goos                 string
+	goarch               string
+	buildTags            []string
+	offline              bool
+	subprocessTimeout    time.Duration
+	continueOnError      bool
+	progress             func(ProgressEvent)
+	tracer               trace.Tracer
+	commentFormat        CommentFormat
+	stripFieldNames      bool
+	namedSchemas         map[string]any
+	writeIndex           bool
+	maxWarnings          int
+	limitWarnings        bool
+	warningHandler       func(Warning)
+	implementations      map[reflect.Type][]any
+	discriminators       map[reflect.Type]string
+	typeMappings         map[reflect.Type]func() *jsonschema.Schema
+	durationFormat       DurationFormat
+	int64Format          Int64Format
+	integerBounds        bool
+	nullablePointers     bool
+	additionalProperties map[reflect.Type]bool
+	requiredPolicy       RequiredPolicy
+	nameTag              string
+	embedPolicy          EmbedPolicy
+	aliasPolicy          AliasPolicy
+	recursiveRoot        bool
+	recursiveRootName    string
+	maxDepth             int
+	anyPolicy            AnyPolicy
+	strict               bool
+	unstructured         func() *jsonschema.Schema
+	namer                func(reflect.Type) string
+	keyNamer             func(string) string
+	nameCollisions       NameCollisionPolicy
+	inlineRefs           bool
+	refMode              RefMode
+	schemaHooks          []func(model any, s *jsonschema.Schema) error
+	overlayDir           string
+	overrides            SchemaOverrides
+	goExtensions         bool
+	provenance           bool
+	version              string
+	versionTags          []string
+
+	// namedInProgress are the named schemas being reflected, to stop
+	// recursion through self-referencing free-form directives.
+	namedInProgress []string
+	// comments caches the comments of the source trees of importPaths.
+	comments *commentCache
 }
 
 func (g *generator) Generate(model any) (SchemaBytes, error) {
-	ctx := g.ctx
+	out, _, err := g.generate(model)
+	return out, err
+}
+
+func (g *generator) GenerateAll(models ...any) (map[string]SchemaBytes, error) {
+	l := logport.LoggerFromContext(g.ctx).With("models", models)
+	filenames, err := g.schemaFilenames(models)
+	if err != nil {
+		return nil, err
+	}
+	results, genErr := g.generateEach(models, filenames, "")
+	if results == nil {
+		return nil, genErr
+	}
+	schemas := make(map[string]SchemaBytes, len(models))
+	for i, model := range models {
+		filename := filenames[i]
+		if filename == "" {
+			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", model)
+			continue
+		}
+		if results[i].err != nil {
+			continue
+		}
+		schemas[filename] = results[i].out
+	}
+	return schemas, genErr
+}
+
+// ctxErr returns the error of the generator's context once it is done,
+// stopping generation and writes between models, passes and files.
+func (g *generator) ctxErr() error {
+	if g.ctx == nil {
+		return nil
+	}
+	return g.ctx.Err()
+}
+
+// generate is Generate returning the reflection as well.
+func (g *generator) generate(model any) (SchemaBytes, *reflection, error) {
+	return g.generateTo(model, "")
+}
+
+// generateTo is generate for a schema written to file, which references to
+// external schema files are relative to. Without a file they are file URIs.
+func (g *generator) generateTo(model any, file string) (_ SchemaBytes, _ *reflection, err error) {
+	ctx, span := g.startSpan(g.ctx, "schemator.Generate", attribute.String("schemator.model", typeName(model)))
+	defer func() { endSpan(span, err) }()
+	rf, s, err := g.reflectModel(ctx, model, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rebaseExternalRefs(rf, s, file); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", typeName(model), err)
+	}
+	if g.inlineRefs {
+		if s, err = inlineRefs(s, s.Definitions); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", typeName(model), err)
+		}
+	}
+	anchorRefs(s, g.refMode)
+	if g.provenance {
+		g.addProvenance(model, s)
+	}
+	out, err := g.marshalJSON(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out, err = g.applyOverlays(model, out); err != nil {
+		return nil, nil, fmt.Errorf("%s: overlay: %w", typeName(model), err)
+	}
+	return out, rf, nil
+}
+
+// reflect returns the JSON schema of model before it is rendered.
+func (g *generator) reflect(model any) (*jsonschema.Schema, error) {
+	return g.reflectWith(model, nil)
+}
+
+// reflectWith reflects model with a Reflector adjusted by configure (may be
+// nil) and runs the post-reflection passes on the result.
+func (g *generator) reflectWith(model any, configure func(*jsonschema.Reflector)) (*jsonschema.Schema, error) {
+	_, s, err := g.reflectModel(g.ctx, model, configure)
+	return s, err
+}
+
+// reflectModel is reflectWith returning the reflection as well, traced as
+// part of the span in ctx. extra passes run after the configured ones.
+func (g *generator) reflectModel(ctx context.Context, model any, configure func(*jsonschema.Reflector), extra ...schemaPass) (_ *reflection, _ *jsonschema.Schema, err error) {
+	rf, err := g.newReflector(ctx, model)
+	if err != nil {
+		return nil, nil, err
+	}
+	if configure != nil {
+		configure(rf.Reflector)
+	}
+	_, span := g.startSpan(ctx, "schemator.Reflect", attribute.String("schemator.model", typeName(model)))
+	defer func() { endSpan(span, err) }()
+	s := rf.reflect(model)
+	for _, pass := range append(g.passes(), extra...) {
+		if err := g.ctxErr(); err != nil {
+			return nil, nil, err
+		}
+		if err := pass(rf, s); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := g.reportWarnings(rf); err != nil {
+		return nil, nil, err
+	}
+	return rf, s, nil
+}
+
+// newReflector resolves import paths for model, checks filesThatMustExist and
+// returns a Reflector with the Go comments of every involved package loaded.
+func (g *generator) newReflector(ctx context.Context, model any) (_ *reflection, err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -139,28 +399,110 @@ func (g *generator) Generate(model any) (SchemaBytes, error) {
 			}
 		}
 	}
-	r := &jsonschema.Reflector{
-		ExpandedStruct:            true,
-		AllowAdditionalProperties: false,
+	// Anonymous structs have no definition to expand, the Reflector
+	// describes them in place.
+	root := derefType(reflect.TypeOf(model))
+	rf := newReflection(&jsonschema.Reflector{
+		ExpandedStruct:             root == nil || root.Name() != "",
+		AllowAdditionalProperties:  false,
+		RequiredFromJSONSchemaTags: g.requiredPolicy == RequiredExplicit,
+		FieldNameTag:               g.nameTag,
+		Mapper:                     g.mapType,
+		Namer:                      g.namer,
+		KeyNamer:                   g.keyNamer,
+	})
+	// Only the packages of the types the model refers to are parsed, which
+	// matters for large source trees such as k8s.io/apimachinery.
+	wanted := make(map[string]bool)
+	for _, pkg := range collectDependentPackages(model) {
+		wanted[pkg] = true
+	}
+	ctx, span := g.startSpan(ctx, "schemator.LoadComments", attribute.String("schemator.model", typeName(model)))
+	defer func() { endSpan(span, err) }()
+	aliases := make(map[string]bool)
+	fieldTypes := make(map[string]fieldType)
+	if err := g.loadComments(ctx, rf, importPaths, wanted, aliases, fieldTypes); err != nil {
+		return nil, err
 	}
-	for _, ip := range importPaths {
-		if err := addGoCommentsForImportPath(r, ip); err != nil {
+	// Aliases may be declared in another package than the fields using
+	// them, which the model does not otherwise refer to.
+	for {
+		missing := false
+		for _, ft := range fieldTypes {
+			if pkg := commentPackage(ft.key); !wanted[pkg] {
+				wanted[pkg] = true
+				missing = true
+			}
+		}
+		if !missing {
+			break
+		}
+		if err := g.loadComments(ctx, rf, importPaths, wanted, aliases, fieldTypes); err != nil {
 			return nil, err
 		}
 	}
-	s := r.Reflect(model)
-	out, err := json.MarshalIndent(s, "", "  ")
-	if err != nil {
-		return nil, err
+	for k, ft := range fieldTypes {
+		if aliases[ft.key] {
+			rf.aliases[k] = ft
+		}
+	}
+	return rf, nil
+}
+
+// loadComments adds the comments of the wanted packages below importPaths to
+// rf, and collects the keys of type aliases in aliases and the types fields
+// are declared with in fieldTypes.
+func (g *generator) loadComments(ctx context.Context, rf *reflection, importPaths []ImportPath, wanted map[string]bool, aliases map[string]bool, fieldTypes map[string]fieldType) error {
+	for _, ip := range importPaths {
+		comments, err := loadGoComments(ctx, rf.Reflector, ip, g.commentFormat, g.comments, wanted)
+		if err != nil {
+			return err
+		}
+		if g.stripFieldNames {
+			for k, field := range comments.fields {
+				if v, ok := rf.CommentMap[k]; ok {
+					rf.CommentMap[k] = stripFieldName(field, v)
+				}
+			}
+		}
+		for k, v := range comments.markers {
+			rf.markers[k] = v
+		}
+		for k, v := range comments.deprecated {
+			rf.deprecated[k] = formatComment(v, commentPackage(k), g.commentFormat)
+		}
+		for k, v := range comments.dirs {
+			rf.packageDirs[k] = v
+		}
+		for k, values := range comments.enums {
+			values = slices.Clone(values)
+			for i := range values {
+				values[i].doc = formatComment(values[i].doc, commentPackage(k), g.commentFormat)
+			}
+			rf.enums[k] = values
+		}
+		for k := range comments.aliases {
+			aliases[k] = true
+		}
+		for k, v := range comments.fieldTypes {
+			fieldTypes[k] = v
+		}
 	}
-	return out, nil
+	return nil
 }
 
 func (g *generator) WriteSchema(model any, filenamePath string) error {
-	out, err := g.Generate(model)
+	out, _, err := g.generateTo(model, filenamePath)
 	if err != nil {
 		return err
 	}
+	return g.writeFile(filenamePath, out, "model", model)
+}
+
+// writeFile atomically writes out to filenamePath, ending with a newline
+// unless WithTrailingNewline(false) was given, and creates parent directories
+// as needed. keyvals are added to the log context.
+func (g *generator) writeFile(filenamePath string, out []byte, keyvals ...any) error {
 	ctx := g.ctx
 	if ctx == nil {
 		ctx = context.Background()
@@ -168,21 +510,10 @@ func (g *generator) WriteSchema(model any, filenamePath string) error {
 	l := logport.LoggerFromContext(ctx).With(
 		"importPaths", g.importPaths,
 		"filesThatMustExist", g.filesThatMustExist,
-		"model", model,
-	)
-	fpath := filepath.Dir(filenamePath)
-	l.Debug("os.MkdirAll", "path", fpath)
-	if err := os.MkdirAll(fpath, 0o0755); err != nil {
-		return err
-	}
-	l.Debug("os.Create", "name", filenamePath)
-	f, err := os.Create(filenamePath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	n, err := fmt.Fprintln(f, out)
-	l.Debug("Wrote JSON schema", "name", filenamePath, "bytesWritten", n, "error", err)
+	).With(keyvals...)
+	content := g.fileContent(out)
+	err := g.write(filenamePath, content)
+	l.Debug("Wrote file", "name", filenamePath, "bytesWritten", len(content), "dryRun", g.dryRun, "error", err)
 	return err
 }
 
@@ -192,17 +523,127 @@ func (g *generator) WriteSchemas(outputDir string, models ...any) error {
 		l.Debug("WriteSchemas: no models provided")
 		return nil
 	}
-	for _, model := range models {
-		filename := toString(model)
+	filenames, err := g.schemaFilenames(models)
+	if err != nil {
+		return err
+	}
+	named := make([]Named, len(models))
+	for i, model := range models {
+		named[i] = Named{Name: toString(model), Model: model}
+	}
+	return g.writeSchemas(outputDir, named, filenames)
+}
+
+func (g *generator) WriteNamedSchemas(outputDir string, named ...Named) error {
+	filenames := make([]string, len(named))
+	for i, n := range named {
+		if n.Name == "" {
+			return fmt.Errorf("schema of %T needs a name", n.Model)
+		}
+		filenames[i] = n.Name + JSONSchemaFormat.Extension
+		if g.version != "" {
+			filenames[i] = n.Name + "." + g.version + JSONSchemaFormat.Extension
+		}
+		filenames[i] = g.layoutPath(n.Model, filenames[i])
+		if !filepath.IsLocal(filepath.FromSlash(filenames[i])) {
+			return fmt.Errorf("schema name %q leaves the output directory", n.Name)
+		}
+		if slices.Contains(filenames[:i], filenames[i]) {
+			return fmt.Errorf("schemas named %s collide in %s", n.Name, filenames[i])
+		}
+	}
+	return g.writeSchemas(outputDir, named, filenames)
+}
+
+// writeSchemas writes the schema of every model in named to its filename in
+// filenames, skipping those without one, and records them as configured.
+func (g *generator) writeSchemas(outputDir string, named []Named, filenames []string) error {
+	l := logport.LoggerFromContext(g.ctx).With("outputDir", outputDir)
+	models := make([]any, len(named))
+	for i, n := range named {
+		models[i] = n.Model
+	}
+	results, genErr := g.generateEach(models, filenames, outputDir)
+	if results == nil {
+		return genErr
+	}
+	var artifacts []Artifact
+	// The files of failing models are left as they are, and not pruned.
+	var failed []string
+	recordManifest := g.version != "" || g.manifest
+	for i, n := range named {
+		filename := filenames[i]
 		if filename == "" {
-			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", model)
+			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", n.Model)
 			continue
 		}
-		if err := g.WriteSchema(model, filepath.Join(outputDir, filename+".schema.json")); err != nil {
-			return err
+		if results[i].err != nil {
+			failed = append(failed, filename)
+			continue
+		}
+		out, rf := results[i].out, results[i].rf
+		if err := g.writeFile(filepath.Join(outputDir, filepath.FromSlash(filename)), out, "model", n.Model); err != nil {
+			return errors.Join(genErr, err)
+		}
+		a := g.newArtifact(filename, n.Model, JSONSchemaFormat.Name, out)
+		a.Type = n.Name
+		a.Dependencies = dependencyPaths(outputDir, rf.dependencies)
+		artifacts = append(artifacts, a)
+		recordManifest = recordManifest || len(rf.dependencies) > 0
+	}
+	if recordManifest {
+		created, err := sourceDate()
+		if err != nil {
+			return errors.Join(genErr, err)
+		}
+		if err := recordArtifacts(outputDir, artifacts, created, g.write); err != nil {
+			return errors.Join(genErr, err)
 		}
 	}
-	return nil
+	return errors.Join(genErr, g.finishOutputDir(outputDir, artifacts, failed...))
+}
+
+// finishOutputDir prunes what WithPrune asks for and writes what WithIndex
+// asks for once every file of a WriteSchemas or WriteAll call, artifacts, is
+// in outputDir. The files in keep, and the hand-written schema files the
+// artifacts depend on, are not pruned either.
+func (g *generator) finishOutputDir(outputDir string, artifacts []Artifact, keep ...string) error {
+	written := slices.Clone(keep)
+	for _, a := range artifacts {
+		written = append(written, a.File)
+		written = append(written, a.Dependencies...)
+	}
+	if err := g.prune(outputDir, written); err != nil {
+		return err
+	}
+	if !g.writeIndex {
+		return nil
+	}
+	return writeIndex(outputDir, g.write)
+}
+
+// schemaFilename returns the filename WriteSchemas uses for model, or "" if
+// no name can be derived.
+func (g *generator) schemaFilename(model any) string {
+	if g.filenameFunc != nil {
+		if name := g.filenameFunc(model); name != "" {
+			return g.layoutPath(model, name)
+		}
+	}
+	return g.artifactFilename(model, JSONSchemaFormat.Extension)
+}
+
+// artifactFilename returns <Type>[.<version>]<ext> for model, in the
+// subdirectory of the layout, or "" if no name can be derived.
+func (g *generator) artifactFilename(model any, ext string) string {
+	name := toString(model)
+	if name == "" {
+		return ""
+	}
+	if g.version != "" {
+		return g.layoutPath(model, name+"."+g.version+ext)
+	}
+	return g.layoutPath(model, name+ext)
 }
 
 // Helper functions...
@@ -211,27 +652,34 @@ func toString(x any) string {
 	if x == nil {
 		return ""
 	}
-	t := reflect.TypeOf(x)
+	return modelName(reflect.TypeOf(x))
+}
+
+// modelName names a model of type t after its named, exported type: named
+// maps, interfaces and generic instantiations keep their (readable) name,
+// slices and arrays of such types are spelled <Elem>Slice and maps of them
+// MapOf<Key>To<Elem>. Other types have no name.
+func modelName(t reflect.Type) string {
 	// unwrap top-level pointers
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
-	// 1) direct named, exported type
 	if n := exportedName(t); n != "" {
 		return n
 	}
-	// 2) slice/array of named, exported element type
-	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
-		elem := t.Elem()
-		for elem.Kind() == reflect.Ptr {
-			elem = elem.Elem()
-		}
-		if n := exportedName(elem); n != "" {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if n := modelName(t.Elem()); n != "" {
 			return n + "Slice"
 		}
+	case reflect.Map:
+		if modelName(t.Elem()) != "" {
+			return readableTypeName(t.String())
+		}
 	}
 	return ""
 }
+
 func exportedName(t reflect.Type) string {
 	// only defined types have a name
 	if t.Name() == "" {
@@ -241,7 +689,7 @@ func exportedName(t reflect.Type) string {
 	if !token.IsExported(t.Name()) {
 		return ""
 	}
-	return t.Name()
+	return definitionName(t)
 }
 
 func collectDependentPackages(models ...any) []string {
@@ -296,10 +744,10 @@ func visitTypeForPackages(t reflect.Type, packages map[string]struct{}, visited
 	}
 }
 
-func (g *generator) resolveImportPaths(ctx context.Context, models ...any) ([]ImportPath, error) {
-	if ctx == nil {
-		ctx = context.Background()
-	}
+func (g *generator) resolveImportPaths(ctx context.Context, models ...any) (_ []ImportPath, err error) {
+	ctx, span := g.startSpan(ctx, "schemator.ResolveImportPaths", attribute.Int("schemator.models", len(models)))
+	defer func() { endSpan(span, err) }()
+	ctx = withLoaderConfig(ctx, loaderConfig{offline: g.offline, timeout: g.subprocessTimeout})
 
 	existing := make(map[string]int, len(g.importPaths))
 	for i, ip := range g.importPaths {
@@ -310,11 +758,15 @@ func (g *generator) resolveImportPaths(ctx context.Context, models ...any) ([]Im
 
 	if len(g.importPaths) == 0 {
 		ip, err := inferLocalImportPath(ctx, "./")
-		if err != nil {
+		// The root of a go.work workspace has no package of its own, the
+		// packages of the models are found in the workspace modules.
+		if err != nil && !inWorkspace("./") {
 			return nil, err
 		}
-		g.importPaths = append(g.importPaths, ip)
-		existing[ip.ModuleImportPath] = len(g.importPaths) - 1
+		if err == nil {
+			g.importPaths = append(g.importPaths, ip)
+			existing[ip.ModuleImportPath] = len(g.importPaths) - 1
+		}
 	}
 
 	inferredPkgs := collectDependentPackages(models...)
@@ -329,6 +781,20 @@ func (g *generator) resolveImportPaths(ctx context.Context, models ...any) ([]Im
 		existing[pkg] = len(g.importPaths) - 1
 	}
 
+	if g.packageCache != "" {
+		if err := loadPackageCache(g.packageCache); err != nil {
+			return nil, err
+		}
+	}
+	var unresolved []string
+	for _, ip := range g.importPaths {
+		if ip.SourceDirectory == "" && ip.ModuleImportPath != "" {
+			unresolved = append(unresolved, ip.ModuleImportPath)
+		}
+	}
+	if err := lookupPackageDirs(ctx, unresolved, ""); err != nil {
+		return nil, err
+	}
 	resolved := make([]ImportPath, 0, len(g.importPaths))
 	for i, ip := range g.importPaths {
 		if ip.ModuleImportPath == "" {
@@ -341,46 +807,42 @@ func (g *generator) resolveImportPaths(ctx context.Context, models ...any) ([]Im
 		g.importPaths[i] = resolvedIP
 		resolved = append(resolved, resolvedIP)
 	}
+	if g.packageCache != "" && !g.dryRun {
+		if err := savePackageCache(g.packageCache, g.perms); err != nil {
+			return nil, fmt.Errorf("package cache %s: %w", g.packageCache, err)
+		}
+	}
 	return resolved, nil
 }
 
-var chdirMu sync.Mutex
-
 func addGoCommentsForImportPath(r *jsonschema.Reflector, ip ImportPath) error {
+	_, err := loadGoComments(context.Background(), r, ip, CommentText, nil, nil)
+	return err
+}
+
+// loadGoComments adds the comments found in ip.SourceDirectory, rendered in
+// format, to r.CommentMap and returns them together with the markers
+// (+optional, +kubebuilder:...) that were removed from them and the
+// deprecation notes. Only the packages in wanted are loaded, unless it is
+// nil. The returned comments come from cache and must not be modified.
+func loadGoComments(ctx context.Context, r *jsonschema.Reflector, ip ImportPath, format CommentFormat, cache *commentCache, wanted map[string]bool) (*goComments, error) {
 	if ip.ModuleImportPath == "" {
-		return fmt.Errorf("missing module import path")
+		return nil, fmt.Errorf("missing module import path")
 	}
 	if ip.SourceDirectory == "" {
-		return fmt.Errorf("source directory is empty for %s", ip.ModuleImportPath)
+		return nil, fmt.Errorf("source directory is empty for %s", ip.ModuleImportPath)
 	}
-	dir := ip.SourceDirectory
-	if !filepath.IsAbs(dir) {
-		relPath := filepath.Clean(dir)
-		if err := r.AddGoComments(ip.ModuleImportPath, relPath); err != nil {
-			return err
-		}
-		sanitizeCommentMap(r.CommentMap)
-		return nil
+	comments, err := cache.extract(ctx, ip, wanted)
+	if err != nil {
+		return nil, err
 	}
-	absDir := filepath.Clean(dir)
-	return withWorkingDir(absDir, func() error {
-		if err := r.AddGoComments(ip.ModuleImportPath, "."); err != nil {
-			return err
-		}
-		sanitizeCommentMap(r.CommentMap)
-		return nil
-	})
-}
-
-func sanitizeCommentMap(m map[string]string) {
-	if m == nil {
-		return
+	if r.CommentMap == nil {
+		r.CommentMap = make(map[string]string, len(comments.text))
 	}
-	for k, v := range m {
-		if sanitized := sanitizeCommentText(v); sanitized != v {
-			m[k] = sanitized
-		}
+	for k, v := range comments.text {
+		r.CommentMap[k] = formatComment(v, commentPackage(k), format)
 	}
+	return comments, nil
 }
 
 func sanitizeCommentText(text string) string {
@@ -390,22 +852,6 @@ func sanitizeCommentText(text string) string {
 	return strings.Join(strings.Fields(text), " ")
 }
 
-func withWorkingDir(dir string, fn func() error) error {
-	chdirMu.Lock()
-	defer chdirMu.Unlock()
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = os.Chdir(cwd)
-	}()
-	if err := os.Chdir(dir); err != nil {
-		return err
-	}
-	return fn()
-}
-
 func ensureSourceDirectory(ctx context.Context, ip ImportPath) (ImportPath, error) {
 	if ip.SourceDirectory != "" {
 		return ip, nil
@@ -415,7 +861,7 @@ func ensureSourceDirectory(ctx context.Context, ip ImportPath) (ImportPath, erro
 		return ip, fmt.Errorf("resolve source directory for %s: %w", ip.ModuleImportPath, err)
 	}
 	if dir == "" {
-		return ip, fmt.Errorf("go list returned empty directory for %s", ip.ModuleImportPath)
+		return ip, fmt.Errorf("empty source directory for %s", ip.ModuleImportPath)
 	}
 	ip.SourceDirectory = dir
 	return ip, nil
@@ -472,31 +918,45 @@ func inferLocalImportPath(ctx context.Context, sourceDir string) (ImportPath, er
 	}, nil
 }
 
+// lookupPackageDir returns the source directory of importPath as resolved
+// in workDir, and whether it is a standard library package.
+// Lookups are cached for the life of the process.
 func lookupPackageDir(ctx context.Context, importPath, workDir string) (string, bool, error) {
-	if ctx == nil {
-		ctx = context.Background()
+	key, err := lookupKey(importPath, workDir)
+	if err != nil {
+		return "", false, err
 	}
-	format := "{{.Dir}}\t{{.Standard}}"
-	cmd := exec.CommandContext(ctx, "go", "list", "-f", format, importPath)
-	cmd.Env = os.Environ()
-	if workDir != "" {
-		cmd.Dir = workDir
+	if pd, ok := cachedPackageDir(key); ok {
+		return pd.Dir, pd.Standard, nil
 	}
-	out, err := cmd.CombinedOutput()
+	// Packages of workspace modules, local replacements and the vendor
+	// directory are on disk already.
+	modules, err := localModules(key.WorkDir)
 	if err != nil {
-		return "", false, fmt.Errorf("go list %s failed: %w (output: %s)", importPath, err, bytes.TrimSpace(out))
+		return "", false, err
 	}
-	line := strings.TrimSpace(string(out))
-	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
-		line = line[:idx]
+	dir, ok := localPackageDir(modules, importPath)
+	if !ok {
+		vendor, err := vendorDir(key.WorkDir)
+		if err != nil {
+			return "", false, err
+		}
+		dir, ok = vendoredPackageDir(vendor, importPath)
+	}
+	if ok {
+		cachePackageDir(key, packageLookup{Dir: dir})
+		return dir, false, nil
+	}
+	pds, err := loadPackageDirs(ctx, []string{importPath}, workDir)
+	if err != nil {
+		return "", false, err
 	}
-	if line == "" {
-		return "", false, fmt.Errorf("go list %s returned empty output", importPath)
+	pd := pds[importPath]
+	if pd.err != nil {
+		return "", false, pd.err
 	}
-	parts := strings.Split(line, "\t")
-	dir := strings.TrimSpace(parts[0])
-	isStd := len(parts) > 1 && parts[1] == "true"
-	return dir, isStd, nil
+	cachePackageDir(key, pd.packageLookup)
+	return pd.Dir, pd.Standard, nil
 }
 
 func findModulePath(startDir string) (string, string, error) {
@@ -516,7 +976,7 @@ func findModulePath(startDir string) (string, string, error) {
 		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			return "", "", fmt.Errorf("go.mod not found starting from %s", startDir)
+			return "", "", fmt.Errorf("%w starting from %s", ErrModuleNotFound, startDir)
 		}
 		dir = parent
 	}
@@ -561,5 +1021,5 @@ func detectPackageName(dir string) (string, error) {
 		}
 		return parsed.Name.Name, nil
 	}
-	return "", fmt.Errorf("no go source files found in %s", dir)
+	return "", fmt.Errorf("%w in %s", ErrNoGoFiles, dir)
 }
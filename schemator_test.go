@@ -1,13 +1,16 @@
 package schemator
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -191,6 +194,57 @@ type DemoType struct {
 	}
 }
 
+func TestAddGoCommentsForImportPathConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "types.go"), `package foo
+
+// DemoType is an example type.
+type DemoType struct {
+	// Info is a field.
+	Info string
+}
+`)
+	writeFile(t, filepath.Join(dir, "sub", "sub.go"), `package sub
+
+type Nested struct {
+	// Value is nested.
+	Value int
+}
+`)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd error: %v", err)
+	}
+	ip := ImportPath{ModuleImportPath: "example.com/temp/foo", SourceDirectory: dir}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := &jsonschema.Reflector{}
+			if err := addGoCommentsForImportPath(r, ip); err != nil {
+				errs <- err
+				return
+			}
+			if got := r.CommentMap["example.com/temp/foo.DemoType.Info"]; got != "Info is a field." {
+				errs <- fmt.Errorf("unexpected comment %q", got)
+			}
+			if got := r.CommentMap["example.com/temp/foo/sub.Nested.Value"]; got != "Value is nested." {
+				errs <- fmt.Errorf("unexpected nested comment %q in %v", got, r.CommentMap)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if now, _ := os.Getwd(); now != cwd {
+		t.Fatalf("working directory changed from %q to %q", cwd, now)
+	}
+}
+
 func writeFile(t *testing.T, path, content string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
@@ -275,6 +329,23 @@ func TestToStringAndExportedName(t *testing.T) {
 	if got := toString([]*example.Subject{}); got != "SubjectSlice" {
 		t.Fatalf("toString slice = %q", got)
 	}
+	for _, tt := range []struct {
+		model any
+		want  string
+	}{
+		{UnstructuredLabels{}, "UnstructuredLabels"},
+		{(*fmt.Stringer)(nil), "Stringer"},
+		{[][]example.Subject{}, "SubjectSliceSlice"},
+		{map[string]*example.Subject{}, "MapOfStringToSubject"},
+		{map[string][]example.Subject{}, "MapOfStringToSubjectSlice"},
+		{map[string]int{}, ""},
+		{[]map[string]string{}, ""},
+		{GenericPage[map[string]GenericUser]{}, "GenericPageOfMapOfStringToGenericUser"},
+	} {
+		if got := toString(tt.model); got != tt.want {
+			t.Errorf("toString(%T) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
 
 	if name := exportedName(reflect.TypeOf(example.Subject{})); name != "Subject" {
 		t.Fatalf("exportedName = %q", name)
@@ -322,3 +393,111 @@ func TestWriteSchemasGeneratesMultiple(t *testing.T) {
 		t.Fatalf("expected at least two schema files, got %d", len(files))
 	}
 }
+
+func TestInlineRefs(t *testing.T) {
+	gen := NewGenerator(context.Background(), WithInlineRefs())
+	out, err := gen.Generate(NamerCustomer{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(out), "$ref") || strings.Contains(string(out), "$defs") {
+		t.Fatalf("schema still has references: %s", out)
+	}
+	if !strings.Contains(string(out), `"$schema"`) || !strings.Contains(string(out), `"$id"`) {
+		t.Errorf("schema lost $schema or $id: %s", out)
+	}
+	if _, err := gen.Generate(RecursiveNode{}); err == nil || !strings.Contains(err.Error(), "recursive") {
+		t.Errorf("recursive model: error = %v", err)
+	}
+}
+
+func TestInlineRefsKeepsSiblingKeywords(t *testing.T) {
+	props := jsonschema.NewProperties()
+	props.Set("address", &jsonschema.Schema{
+		Ref:         "#/$defs/Address",
+		Description: "Where to ship.",
+		ReadOnly:    true,
+		Deprecated:  true,
+		Default:     map[string]any{"city": "Stockholm"},
+		Examples:    []any{map[string]any{"city": "Oslo"}},
+		Extras:      map[string]any{"x-internal": true},
+	})
+	defs := jsonschema.Definitions{"Address": {Type: "object", Description: "A postal address.", Extras: map[string]any{"x-kind": "address"}}}
+	s, err := inlineRefs(&jsonschema.Schema{Type: "object", Properties: props, Definitions: defs}, defs)
+	if err != nil {
+		t.Fatalf("inlineRefs() error = %v", err)
+	}
+	got, _ := s.Properties.Get("address")
+	if got.Type != "object" || got.Description != "Where to ship." || !got.ReadOnly || !got.Deprecated ||
+		!reflect.DeepEqual(got.Default, map[string]any{"city": "Stockholm"}) ||
+		!reflect.DeepEqual(got.Examples, []any{map[string]any{"city": "Oslo"}}) ||
+		got.Extras["x-internal"] != true || got.Extras["x-kind"] != "address" {
+		t.Errorf("inlined address = %+v", got)
+	}
+	if defs["Address"].Description != "A postal address." || len(defs["Address"].Extras) != 1 {
+		t.Errorf("definition was modified: %+v", defs["Address"])
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	gen := NewGenerator(context.Background(), WithVersion("2.0.0"))
+	schemas, err := gen.GenerateAll(OrderedBase{}, &OrderedRecord{})
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("GenerateAll() = %d schemas, want 2", len(schemas))
+	}
+	want, err := gen.Generate(OrderedBase{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schemas["OrderedBase.2.0.0.schema.json"]; !bytes.Equal(got, want) {
+		t.Errorf("OrderedBase.2.0.0.schema.json = %s, want %s", got, want)
+	}
+	if _, ok := schemas["OrderedRecord.2.0.0.schema.json"]; !ok {
+		t.Errorf("GenerateAll() = %v, missing OrderedRecord", schemas)
+	}
+}
+
+func TestWriteNamedSchemas(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewGenerator(context.Background(), WithVersion("1.0.0"))
+	err := gen.WriteNamedSchemas(dir,
+		Named{Name: "Envelope", Model: struct {
+			ID string `json:"id"`
+		}{}},
+		Named{Name: "UserPage", Model: GenericPage[GenericUser]{}},
+	)
+	if err != nil {
+		t.Fatalf("WriteNamedSchemas() error = %v", err)
+	}
+	for _, file := range []string{"Envelope.1.0.0.schema.json", "UserPage.1.0.0.schema.json"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+	m, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 2 || m.Artifacts[0].Type != "Envelope" || m.Artifacts[1].Type != "UserPage" {
+		t.Errorf("manifest = %+v", m.Artifacts)
+	}
+
+	if err := gen.WriteNamedSchemas(dir, Named{Model: struct{}{}}); err == nil || !strings.Contains(err.Error(), "needs a name") {
+		t.Errorf("WriteNamedSchemas() without a name error = %v", err)
+	}
+	if err := gen.WriteNamedSchemas(dir, Named{Name: "A", Model: OrderedBase{}}, Named{Name: "A", Model: OrderedRecord{}}); err == nil || !strings.Contains(err.Error(), "collide") {
+		t.Errorf("WriteNamedSchemas() with duplicate names error = %v", err)
+	}
+	outside := filepath.Join(dir, "nested")
+	for _, name := range []string{"../../x", "/etc/x", "a/../../x"} {
+		if err := gen.WriteNamedSchemas(outside, Named{Name: name, Model: OrderedBase{}}); err == nil || !strings.Contains(err.Error(), "leaves the output directory") {
+			t.Errorf("WriteNamedSchemas(%q) error = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.1.0.0.schema.json")); err == nil {
+		t.Errorf("WriteNamedSchemas() wrote outside the output directory")
+	}
+}
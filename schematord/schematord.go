@@ -0,0 +1,174 @@
+// Package schematord is a small embeddable HTTP service that generates JSON
+// schemas on demand. Platform teams register the Go types they want to offer
+// (Go cannot load types by name at runtime) and clients POST a package/type
+// reference to receive the generated schema:
+//
+//	srv := schematord.New(ctx, []string{"pkt.systems/schemator/example"})
+//	if err := srv.Register(example.Subject{}, example.Example{}); err != nil {
+//		log.Fatal(err)
+//	}
+//	http.Handle("/schemas", srv)
+//
+//	curl -d '{"package":"pkt.systems/schemator/example","type":"Subject"}' http://localhost:8080/schemas
+package schematord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"pkt.systems/logport"
+	"pkt.systems/schemator"
+)
+
+// Reference identifies a registered type by import path and type name.
+type Reference struct {
+	Package string `json:"package"`
+	Type    string `json:"type"`
+}
+
+func (r Reference) String() string {
+	return r.Package + "." + r.Type
+}
+
+// Server implements http.Handler. GET lists the registered references, POST
+// with a Reference body responds with the generated schema.
+type Server struct {
+	ctx            context.Context
+	allowedModules []string
+	options        []schemator.Option
+	mu             sync.RWMutex
+	models         map[Reference]any
+}
+
+// New returns a Server that only serves types whose package is one of
+// allowedModules or a sub-package of one. opts are applied to the Generator
+// created for every request.
+func New(ctx context.Context, allowedModules []string, opts ...schemator.Option) *Server {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Server{
+		ctx:            ctx,
+		allowedModules: allowedModules,
+		options:        opts,
+		models:         make(map[Reference]any),
+	}
+}
+
+// Register makes models available to clients. Models must be named types
+// from an allowed module.
+func (s *Server) Register(models ...any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, model := range models {
+		if model == nil {
+			return fmt.Errorf("cannot register nil model")
+		}
+		t := reflect.TypeOf(model)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		ref := Reference{Package: t.PkgPath(), Type: t.Name()}
+		if ref.Package == "" || ref.Type == "" {
+			return fmt.Errorf("cannot register unnamed type %s", t)
+		}
+		if !s.allowed(ref.Package) {
+			return fmt.Errorf("package %s is not within the allowed modules %v", ref.Package, s.allowedModules)
+		}
+		s.models[ref] = reflect.New(t).Elem().Interface()
+	}
+	return nil
+}
+
+// References returns the registered references in sorted order.
+func (s *Server) References() []Reference {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	refs := make([]Reference, 0, len(s.models))
+	for ref := range s.models {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	return refs
+}
+
+func (s *Server) allowed(pkg string) bool {
+	for _, m := range s.allowedModules {
+		if pkg == m || strings.HasPrefix(pkg, m+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := logport.LoggerFromContext(s.ctx).With("method", r.Method, "path", r.URL.Path)
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.References())
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var ref Reference
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&ref); err != nil {
+		httpError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if ref.Package == "" || ref.Type == "" {
+		httpError(w, http.StatusBadRequest, "package and type are required")
+		return
+	}
+	if !s.allowed(ref.Package) {
+		httpError(w, http.StatusForbidden, fmt.Sprintf("package %s is not within the allowed modules", ref.Package))
+		return
+	}
+	s.mu.RLock()
+	model, ok := s.models[ref]
+	s.mu.RUnlock()
+	if !ok {
+		httpError(w, http.StatusNotFound, fmt.Sprintf("type %s is not registered", ref))
+		return
+	}
+	ctx := logport.ContextWithLogger(r.Context(), logport.LoggerFromContext(s.ctx))
+	// A Generator caches resolved import paths and is not safe for
+	// concurrent use, so every request gets its own. Seeding it with the
+	// model's package avoids inferring a local module from the server's
+	// working directory.
+	opts := append([]schemator.Option{
+		schemator.WithImportPaths(schemator.ImportPath{ModuleImportPath: ref.Package}),
+	}, s.options...)
+	out, err := schemator.NewGenerator(ctx, opts...).Generate(model)
+	if err != nil {
+		l.Error("Unable to generate schema", "reference", ref.String(), "error", err)
+		httpError(w, http.StatusInternalServerError, "schema generation failed")
+		return
+	}
+	l.Debug("Generated schema", "reference", ref.String(), "bytes", len(out))
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,61 @@
+package schematord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestServerGeneratesRegisteredTypes(t *testing.T) {
+	srv := New(context.Background(), []string{"pkt.systems/schemator"})
+	if err := srv.Register(example.Subject{}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	body := `{"package":"pkt.systems/schemator/example","type":"Subject"}`
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schemas", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if _, ok := doc["properties"]; !ok {
+		t.Fatalf("schema missing properties: %v", doc)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	if !strings.Contains(rec.Body.String(), `"type":"Subject"`) {
+		t.Fatalf("listing missing Subject: %s", rec.Body)
+	}
+}
+
+func TestServerRejectsRequests(t *testing.T) {
+	srv := New(context.Background(), []string{"pkt.systems/schemator/example"})
+	if err := srv.Register(struct{}{}); err == nil {
+		t.Fatalf("Register() of unnamed type error = nil")
+	}
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"package":"github.com/google/uuid","type":"UUID"}`, http.StatusForbidden},
+		{`{"package":"pkt.systems/schemator/example","type":"Missing"}`, http.StatusNotFound},
+		{`{"package":""}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/schemas", strings.NewReader(tc.body)))
+		if rec.Code != tc.want {
+			t.Fatalf("body %s: status = %d, want %d", tc.body, rec.Code, tc.want)
+		}
+	}
+}
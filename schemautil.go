@@ -0,0 +1,233 @@
+package schemator
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// mapSubschemas replaces every direct sub-schema of s with the result of fn.
+func mapSubschemas(s *jsonschema.Schema, fn func(*jsonschema.Schema) (*jsonschema.Schema, error)) error {
+	if s == nil || isBooleanSchema(s) {
+		return nil
+	}
+	var err error
+	apply := func(child *jsonschema.Schema) *jsonschema.Schema {
+		if child == nil || err != nil {
+			return child
+		}
+		var out *jsonschema.Schema
+		out, err = fn(child)
+		return out
+	}
+	applyAll := func(children []*jsonschema.Schema) {
+		for i := range children {
+			children[i] = apply(children[i])
+		}
+	}
+	applyMap := func(children map[string]*jsonschema.Schema) {
+		// In key order, so the passes behave the same on every run.
+		for _, k := range slices.Sorted(maps.Keys(children)) {
+			children[k] = apply(children[k])
+		}
+	}
+	applyAll(s.AllOf)
+	applyAll(s.AnyOf)
+	applyAll(s.OneOf)
+	applyAll(s.PrefixItems)
+	s.Not = apply(s.Not)
+	s.If = apply(s.If)
+	s.Then = apply(s.Then)
+	s.Else = apply(s.Else)
+	applyMap(s.DependentSchemas)
+	s.Items = apply(s.Items)
+	s.Contains = apply(s.Contains)
+	if s.Properties != nil {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			pair.Value = apply(pair.Value)
+		}
+	}
+	applyMap(s.PatternProperties)
+	s.AdditionalProperties = apply(s.AdditionalProperties)
+	s.PropertyNames = apply(s.PropertyNames)
+	s.ContentSchema = apply(s.ContentSchema)
+	applyMap(s.Definitions)
+	return err
+}
+
+// walkSchema calls fn for s and every schema nested below it, parents first.
+func walkSchema(s *jsonschema.Schema, fn func(*jsonschema.Schema) error) error {
+	if s == nil || isBooleanSchema(s) {
+		return nil
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return mapSubschemas(s, func(child *jsonschema.Schema) (*jsonschema.Schema, error) {
+		return child, walkSchema(child, fn)
+	})
+}
+
+func isBooleanSchema(s *jsonschema.Schema) bool {
+	return s == jsonschema.TrueSchema || s == jsonschema.FalseSchema
+}
+
+// cloneSchema returns a deep copy of s.
+func cloneSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil || isBooleanSchema(s) {
+		return s
+	}
+	c := copySchemaNode(s)
+	_ = mapSubschemas(c, func(child *jsonschema.Schema) (*jsonschema.Schema, error) {
+		return cloneSchema(child), nil
+	})
+	return c
+}
+
+// copySchemaNode copies s and its slices and maps but shares the sub-schemas.
+func copySchemaNode(s *jsonschema.Schema) *jsonschema.Schema {
+	c := *s
+	c.AllOf = append([]*jsonschema.Schema(nil), s.AllOf...)
+	c.AnyOf = append([]*jsonschema.Schema(nil), s.AnyOf...)
+	c.OneOf = append([]*jsonschema.Schema(nil), s.OneOf...)
+	c.PrefixItems = append([]*jsonschema.Schema(nil), s.PrefixItems...)
+	c.Required = append([]string(nil), s.Required...)
+	c.Enum = append([]any(nil), s.Enum...)
+	c.Examples = append([]any(nil), s.Examples...)
+	c.DependentSchemas = cloneSchemaMap(s.DependentSchemas)
+	c.PatternProperties = cloneSchemaMap(s.PatternProperties)
+	c.Definitions = cloneSchemaMap(s.Definitions)
+	if s.Properties != nil {
+		c.Properties = jsonschema.NewProperties()
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			c.Properties.Set(pair.Key, pair.Value)
+		}
+	}
+	if s.Extras != nil {
+		c.Extras = make(map[string]any, len(s.Extras))
+		for k, v := range s.Extras {
+			c.Extras[k] = v
+		}
+	}
+	return &c
+}
+
+func cloneSchemaMap[M ~map[string]*jsonschema.Schema](m M) M {
+	if m == nil {
+		return nil
+	}
+	out := make(M, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// inlineRefs returns a copy of s where every local "#/$defs/..." reference is
+// replaced by a copy of the definition it points to. Recursive definitions
+// cannot be inlined and produce an error.
+func inlineRefs(s *jsonschema.Schema, defs jsonschema.Definitions) (*jsonschema.Schema, error) {
+	return inlineRefsVisiting(s, defs, make(map[string]bool))
+}
+
+func inlineRefsVisiting(s *jsonschema.Schema, defs jsonschema.Definitions, visiting map[string]bool) (*jsonschema.Schema, error) {
+	if s == nil || isBooleanSchema(s) {
+		return s, nil
+	}
+	if s.Ref == rootRef {
+		return nil, fmt.Errorf("schema is recursive and cannot be inlined")
+	}
+	if name, ok := strings.CutPrefix(s.Ref, "#/$defs/"); ok {
+		def, found := defs[name]
+		if !found {
+			return nil, fmt.Errorf("reference %s points to a missing definition", s.Ref)
+		}
+		if visiting[name] {
+			return nil, fmt.Errorf("definition %s is recursive and cannot be inlined", name)
+		}
+		visiting[name] = true
+		inlined, err := inlineRefsVisiting(def, defs, visiting)
+		delete(visiting, name)
+		if err != nil {
+			return nil, err
+		}
+		if isBooleanSchema(inlined) {
+			return inlined, nil
+		}
+		// Keywords next to $ref (e.g. a field description, readOnly or
+		// default) take precedence over the definition's.
+		siblings := copySchemaNode(s)
+		siblings.Ref = ""
+		siblings.Definitions = nil
+		if err := mapSubschemas(siblings, func(child *jsonschema.Schema) (*jsonschema.Schema, error) {
+			return inlineRefsVisiting(child, defs, visiting)
+		}); err != nil {
+			return nil, err
+		}
+		return mergeSiblingKeywords(inlined, siblings), nil
+	}
+	c := copySchemaNode(s)
+	c.Definitions = nil
+	err := mapSubschemas(c, func(child *jsonschema.Schema) (*jsonschema.Schema, error) {
+		return inlineRefsVisiting(child, defs, visiting)
+	})
+	return c, err
+}
+
+// mergeSiblingKeywords sets every non-zero keyword of siblings on dst, a
+// copy of an inlined definition, and returns it.
+func mergeSiblingKeywords(dst, siblings *jsonschema.Schema) *jsonschema.Schema {
+	src, out := reflect.ValueOf(siblings).Elem(), reflect.ValueOf(dst).Elem()
+	for i := range src.NumField() {
+		field := src.Type().Field(i)
+		if !field.IsExported() || field.Name == "Extras" || src.Field(i).IsZero() {
+			continue
+		}
+		out.Field(i).Set(src.Field(i))
+	}
+	if len(siblings.Extras) > 0 {
+		extras := maps.Clone(dst.Extras)
+		if extras == nil {
+			extras = make(map[string]any, len(siblings.Extras))
+		}
+		maps.Copy(extras, siblings.Extras)
+		dst.Extras = extras
+	}
+	return dst
+}
+
+// pruneDefinitions removes the definitions of s no longer referenced from s
+// or from other referenced definitions, e.g. after properties were removed.
+func pruneDefinitions(s *jsonschema.Schema) {
+	if len(s.Definitions) == 0 {
+		return
+	}
+	defs := s.Definitions
+	used := make(map[string]bool)
+	var mark func(*jsonschema.Schema) error
+	mark = func(n *jsonschema.Schema) error {
+		name, ok := strings.CutPrefix(n.Ref, "#/$defs/")
+		if !ok || used[name] {
+			return nil
+		}
+		used[name] = true
+		if def := defs[name]; def != nil {
+			return walkSchema(def, mark)
+		}
+		return nil
+	}
+	// Walk the schema without its definitions; definitions are visited
+	// when referenced.
+	s.Definitions = nil
+	_ = walkSchema(s, mark)
+	s.Definitions = defs
+	for name := range defs {
+		if !used[name] {
+			delete(defs, name)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package schemator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Model states reported in a Status file.
+const (
+	StatusOK         = "ok"
+	StatusSuppressed = "suppressed"
+	StatusDrift      = "drift"
+)
+
+// Status summarizes the latest Verify run, written by WithStatusFile so
+// dashboards can surface contract health per model.
+type Status struct {
+	// Checked is when Verify ran.
+	Checked time.Time `json:"checked"`
+	// OK is true when Verify returned no error.
+	OK     bool          `json:"ok"`
+	Models []ModelStatus `json:"models"`
+	// ExpiredSuppressions counts suppressions past their expiry date.
+	ExpiredSuppressions int `json:"expiredSuppressions,omitempty"`
+}
+
+// ModelStatus is the verify result of one model.
+type ModelStatus struct {
+	Model string `json:"model"`
+	File  string `json:"file"`
+	// Status is StatusOK, StatusSuppressed (all differences are suppressed)
+	// or StatusDrift.
+	Status string `json:"status"`
+	// Drifts counts unsuppressed differences.
+	Drifts int `json:"drifts,omitempty"`
+	// Suppressed counts differences covered by a suppression.
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Badge is a shields.io endpoint payload
+// (https://shields.io/badges/endpoint-badge), written by WithBadgeFile.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// newStatus builds the Status of a Verify run from every difference found
+// (drifts), the models checked and the error Verify returns.
+func newStatus(checked []ModelStatus, drifts []Drift, verifyErr error, now time.Time) Status {
+	st := Status{Checked: now.UTC(), OK: verifyErr == nil, Models: checked}
+	var derr *DriftError
+	unsuppressed := make(map[string]int)
+	if errors.As(verifyErr, &derr) {
+		for _, d := range derr.Drifts {
+			unsuppressed[d.File]++
+		}
+		st.ExpiredSuppressions = len(derr.Expired)
+	}
+	total := make(map[string]int)
+	for _, d := range drifts {
+		total[d.File]++
+	}
+	for i := range st.Models {
+		m := &st.Models[i]
+		m.Drifts = unsuppressed[m.File]
+		m.Suppressed = total[m.File] - m.Drifts
+		switch {
+		case m.Drifts > 0:
+			m.Status = StatusDrift
+		case m.Suppressed > 0:
+			m.Status = StatusSuppressed
+		default:
+			m.Status = StatusOK
+		}
+	}
+	return st
+}
+
+// Badge returns the shields.io endpoint payload for s: green when every
+// schema matches, yellow when only suppressed differences remain and red
+// otherwise.
+func (s Status) Badge() Badge {
+	b := Badge{SchemaVersion: 1, Label: "schemas"}
+	drifted, suppressed := 0, 0
+	for _, m := range s.Models {
+		switch m.Status {
+		case StatusDrift:
+			drifted++
+		case StatusSuppressed:
+			suppressed++
+		}
+	}
+	switch {
+	case drifted > 0:
+		b.Message = fmt.Sprintf("%d of %d drifted", drifted, len(s.Models))
+		b.Color = "red"
+	case s.ExpiredSuppressions > 0:
+		b.Message = fmt.Sprintf("%d expired suppression(s)", s.ExpiredSuppressions)
+		b.Color = "red"
+	case suppressed > 0:
+		b.Message = fmt.Sprintf("%d of %d suppressed", suppressed, len(s.Models))
+		b.Color = "yellow"
+	default:
+		b.Message = fmt.Sprintf("%d in sync", len(s.Models))
+		b.Color = "brightgreen"
+	}
+	return b
+}
+
+func (g *generator) writeStatus(st Status) error {
+	if g.statusFile != "" {
+		out, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := g.writeFile(g.statusFile, out, "status", g.statusFile); err != nil {
+			return err
+		}
+	}
+	if g.badgeFile != "" {
+		out, err := json.MarshalIndent(st.Badge(), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := g.writeFile(g.badgeFile, out, "badge", g.badgeFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestVerifyWritesStatusAndBadge(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	if err := New(ctx, nil).WriteSchemas(outDir, example.Subject{}, example.Example{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	file := filepath.Join(outDir, "Subject.schema.json")
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	writeFile(t, file, strings.Replace(string(contents), `"type": "integer"`, `"type": "string"`, 1))
+
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	badgeFile := filepath.Join(t.TempDir(), "badge.json")
+	gen := NewGenerator(ctx, WithStatusFile(statusFile), WithBadgeFile(badgeFile))
+	if err := gen.Verify(outDir, example.Subject{}, example.Example{}); err == nil {
+		t.Fatalf("Verify() error = nil, want drift")
+	}
+
+	var st Status
+	readJSON(t, statusFile, &st)
+	if st.OK || len(st.Models) != 2 {
+		t.Fatalf("status = %+v", st)
+	}
+	if m := st.Models[0]; m.Model != "Subject" || m.Status != StatusDrift || m.Drifts != 1 {
+		t.Fatalf("Subject status = %+v", m)
+	}
+	if m := st.Models[1]; m.Status != StatusOK {
+		t.Fatalf("Example status = %+v", m)
+	}
+	var b Badge
+	readJSON(t, badgeFile, &b)
+	if b.SchemaVersion != 1 || b.Color != "red" || b.Message != "1 of 2 drifted" {
+		t.Fatalf("badge = %+v", b)
+	}
+}
+
+func TestStatusBadgeSuppressed(t *testing.T) {
+	drifts := []Drift{{File: "A.schema.json", Pointer: "/x", Message: "added"}}
+	st := newStatus([]ModelStatus{{Model: "A", File: "A.schema.json"}}, drifts, nil, time.Now())
+	if st.Models[0].Status != StatusSuppressed || st.Models[0].Suppressed != 1 {
+		t.Fatalf("status = %+v", st.Models[0])
+	}
+	if b := st.Badge(); b.Color != "yellow" {
+		t.Fatalf("badge = %+v, want yellow", b)
+	}
+}
+
+func readJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if err := json.Unmarshal(contents, v); err != nil {
+		t.Fatalf("Unmarshal %s: %v", path, err)
+	}
+}
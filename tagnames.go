@@ -0,0 +1,78 @@
+package schemator
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Struct tags property names can be read from besides json. Both yaml.v3
+// and the MongoDB driver name untagged fields by their field name in lower
+// case.
+const (
+	yamlTag = "yaml"
+	bsonTag = "bson"
+)
+
+// applyLowercaseNames is the schema pass renaming the properties of fields
+// without a yaml or bson tag name to their field name in lower case. The
+// Reflector only reads names from tags. It runs first, so every other pass
+// finds properties under their final names.
+func applyLowercaseNames(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		if ts.Properties == nil {
+			return nil
+		}
+		renames := make(map[string]string)
+		rf.lowercaseRenames(t, renames)
+		if len(renames) == 0 {
+			return nil
+		}
+		props := jsonschema.NewProperties()
+		for pair := ts.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			name := pair.Key
+			if renamed, ok := renames[name]; ok {
+				name = renamed
+			}
+			props.Set(name, pair.Value)
+		}
+		ts.Properties = props
+		for i, name := range ts.Required {
+			if renamed, ok := renames[name]; ok {
+				ts.Required[i] = renamed
+			}
+		}
+		return nil
+	})
+}
+
+// lowercaseRenames records in renames the property name the Reflector gave
+// each untagged field of t, and of the structs t embeds, and its name in
+// lower case.
+func (rf *reflection) lowercaseRenames(t reflect.Type, renames map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := splitJSONTag(f.Tag.Get(rf.nameTag()))
+		if name == "-" || (!f.Anonymous && !f.IsExported()) {
+			continue
+		}
+		if (f.Anonymous && name == "") || slices.Contains(opts, "inline") {
+			if ft := derefType(f.Type); ft.Kind() == reflect.Struct {
+				rf.lowercaseRenames(ft, renames)
+			}
+			continue
+		}
+		if name != "" {
+			continue
+		}
+		from, to := f.Name, strings.ToLower(f.Name)
+		if rf.KeyNamer != nil {
+			from, to = rf.KeyNamer(from), rf.KeyNamer(to)
+		}
+		if from != to {
+			renames[from] = to
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// YAMLServer is a configuration section.
+type YAMLServer struct {
+	ListenAddr string `yaml:"listen_addr"`
+	Timeout    int    `yaml:",omitempty"`
+}
+
+// YAMLLogging is inlined into YAMLConfig.
+type YAMLLogging struct {
+	LogLevel string
+}
+
+// YAMLConfig is only ever read from YAML.
+type YAMLConfig struct {
+	Name        string      `yaml:"name" json:"displayName"`
+	MaxRetries  int         `json:"max_retries"`
+	Server      YAMLServer  `yaml:"server"`
+	Logging     YAMLLogging `yaml:",inline"`
+	Ignored     string      `yaml:"-"`
+	DefaultPort int         `yaml:"DefaultPort,omitempty"`
+}
+
+func TestYAMLNames(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithYAMLNames()).Generate(YAMLConfig{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range doc.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"DefaultPort", "loglevel", "maxretries", "name", "server"}; !slices.Equal(names, want) {
+		t.Errorf("properties = %v, want %v", names, want)
+	}
+	if want := []string{"name", "maxretries", "server", "loglevel"}; !slices.Equal(doc.Required, want) {
+		t.Errorf("required = %v, want %v", doc.Required, want)
+	}
+	server := doc.Defs["YAMLServer"]
+	if _, ok := server.Properties["timeout"]; !ok || !slices.Equal(server.Required, []string{"listen_addr"}) {
+		t.Errorf("YAMLServer = %+v", server)
+	}
+}
+
+// BSONAudit is inlined into BSONUser.
+type BSONAudit struct {
+	CreatedBy string `bson:"created_by"`
+}
+
+// BSONUser is a MongoDB document.
+type BSONUser struct {
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	Email     string    `bson:"email"`
+	LastLogin int64     `bson:",minsize"`
+	Audit     BSONAudit `bson:",inline"`
+}
+
+func TestBSONNames(t *testing.T) {
+	out, err := NewGenerator(context.Background(), WithBSONNames()).Generate(BSONUser{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range doc.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"_id", "created_by", "email", "lastlogin"}; !slices.Equal(names, want) {
+		t.Errorf("properties = %v, want %v", names, want)
+	}
+	if want := []string{"email", "lastlogin", "created_by"}; !slices.Equal(doc.Required, want) {
+		t.Errorf("required = %v, want %v", doc.Required, want)
+	}
+}
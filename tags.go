@@ -0,0 +1,160 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// schematorTag is a parsed `schemator:"..."` struct tag.
+type schematorTag struct {
+	skip        bool
+	deprecated  bool
+	readOnly    bool
+	writeOnly   bool
+	title       string
+	description string
+	format      string
+	mediaType   string
+	// anyPolicy overrides WithAnyPolicy for the field.
+	anyPolicy *AnyPolicy
+}
+
+// parseSchematorTag parses a comma separated `schemator:"..."` tag:
+//
+//	schemator:"title=Display name,description=Shown to users\, verbatim,format=email,deprecated,readonly"
+//
+// Commas inside values are escaped as `\,`. `schemator:"-"` is short for
+// `schemator:"skip"`. "mediatype=image/png" sets the contentMediaType of a
+// string, typically a []byte written as base64. "any=object" describes an
+// interface field that accepts any value as the AnyPolicy of that name
+// would.
+func parseSchematorTag(tag string) (schematorTag, error) {
+	var st schematorTag
+	if tag == "-" {
+		st.skip = true
+		return st, nil
+	}
+	for _, opt := range splitEscaped(tag, ',') {
+		key, value, hasValue := strings.Cut(opt, "=")
+		switch key = strings.TrimSpace(key); key {
+		case "":
+		case "skip":
+			st.skip = true
+		case "deprecated":
+			st.deprecated = true
+		case "readonly":
+			st.readOnly = true
+		case "writeonly":
+			st.writeOnly = true
+		case "title":
+			st.title = value
+		case "description":
+			st.description = value
+		case "format":
+			st.format = value
+		case "mediatype":
+			st.mediaType = value
+		case "any":
+			policy, err := parseAnyPolicy(value)
+			if err != nil {
+				return st, err
+			}
+			st.anyPolicy = &policy
+		default:
+			return st, fmt.Errorf("unknown schemator tag option %q", key)
+		}
+		if hasValue && (key == "skip" || key == "deprecated" || key == "readonly" || key == "writeonly") {
+			return st, fmt.Errorf("schemator tag option %q takes no value", key)
+		}
+	}
+	if st.readOnly && st.writeOnly {
+		return st, fmt.Errorf("schemator tag options readonly and writeonly are mutually exclusive")
+	}
+	return st, nil
+}
+
+// splitEscaped splits s at sep unless sep is preceded by a backslash.
+func splitEscaped(s string, sep byte) []string {
+	var out []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == sep:
+			b.WriteByte(sep)
+			i++
+		case s[i] == sep:
+			out = append(out, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(out, b.String())
+}
+
+// applySchematorTags applies `schemator:"..."` struct tags. They run after
+// every other pass so their overrides win over doc comments, markers and
+// jsonschema tags. Fields tagged `schemator:"-"` or "skip", or documented
+// with a "// schemator:ignore" line, are removed even though they are
+// serialized. "// schemator:readonly" and "// schemator:writeonly" lines are
+// equivalent to the tag options.
+func applySchematorTags(rf *reflection, s *jsonschema.Schema) error {
+	removed := false
+	err := rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			fieldKey := typeKey(fv.owner) + "." + fv.field.Name
+			tag, ok := fv.field.Tag.Lookup("schemator")
+			markers := rf.markers[fieldKey]
+			ignored := hasMarker(markers, "schemator:ignore")
+			readOnly := hasMarker(markers, "schemator:readonly")
+			writeOnly := hasMarker(markers, "schemator:writeonly")
+			if !ok && !ignored && !readOnly && !writeOnly {
+				return nil
+			}
+			st, err := parseSchematorTag(tag)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fieldKey, err)
+			}
+			st.readOnly = st.readOnly || readOnly
+			st.writeOnly = st.writeOnly || writeOnly
+			if st.readOnly && st.writeOnly {
+				return fmt.Errorf("%s: a field cannot be both read-only and write-only", fieldKey)
+			}
+			if st.skip || ignored {
+				fv.parent.Properties.Delete(fv.name)
+				fv.parent.Required = removeString(fv.parent.Required, fv.name)
+				removed = true
+				return nil
+			}
+			if st.title != "" {
+				fv.schema.Title = st.title
+			}
+			if st.description != "" {
+				fv.schema.Description = st.description
+			}
+			if st.deprecated {
+				fv.schema.Deprecated = true
+			}
+			if st.readOnly {
+				fv.schema.ReadOnly = true
+			}
+			if st.writeOnly {
+				fv.schema.WriteOnly = true
+			}
+			if st.format != "" {
+				fv.schema.Format = st.format
+			}
+			if st.mediaType != "" {
+				fv.schema.ContentMediaType = st.mediaType
+			}
+			return nil
+		})
+	})
+	if removed {
+		pruneDefinitions(s)
+	}
+	return err
+}
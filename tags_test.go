@@ -0,0 +1,125 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TaggedAudit is a bookkeeping type only referenced by a skipped field.
+type TaggedAudit struct {
+	By string `json:"by"`
+}
+
+// TaggedUser uses schemator struct tags.
+type TaggedUser struct {
+	// Name is overridden by the tag.
+	Name  string      `json:"name" schemator:"title=Display name,description=Shown to users\\, verbatim"`
+	Email string      `json:"email" schemator:"format=email,deprecated"`
+	Audit TaggedAudit `json:"audit" schemator:"skip"`
+}
+
+func TestSchematorTags(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(TaggedUser{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]any            `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	name := doc.Properties["name"]
+	if name["title"] != "Display name" || name["description"] != "Shown to users, verbatim" {
+		t.Fatalf("name = %v", name)
+	}
+	email := doc.Properties["email"]
+	if email["format"] != "email" || email["deprecated"] != true {
+		t.Fatalf("email = %v", email)
+	}
+	if _, ok := doc.Properties["audit"]; ok || slices.Contains(doc.Required, "audit") {
+		t.Fatalf("skipped field still present: %s", out)
+	}
+	if _, ok := doc.Defs["TaggedAudit"]; ok {
+		t.Fatalf("definition of skipped field not pruned: %s", out)
+	}
+}
+
+func TestSchematorTagUnknownOption(t *testing.T) {
+	type bad struct {
+		Name string `json:"name" schemator:"titel=x"`
+	}
+	_, err := New(context.Background(), nil).Generate(bad{})
+	if err == nil || !strings.Contains(err.Error(), "titel") {
+		t.Fatalf("Generate() error = %v, want unknown option error", err)
+	}
+}
+
+// IgnoredFields hides bookkeeping fields from the schema.
+type IgnoredFields struct {
+	Name string `json:"name"`
+	// Revision is internal bookkeeping.
+	// schemator:ignore
+	Revision int    `json:"revision"`
+	Token    string `json:"token" schemator:"-"`
+	Shard    int    `json:"shard"` //schemator:ignore
+}
+
+func TestFieldExclusion(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(IgnoredFields{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string       `json:"required"`
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Properties) != 1 || doc.Properties["name"] == nil || !slices.Equal(doc.Required, []string{"name"}) {
+		t.Fatalf("excluded fields still present: %s", out)
+	}
+}
+
+// AccessAccount has read-only and write-only fields.
+type AccessAccount struct {
+	ID       string `json:"id" schemator:"readonly"`
+	Password string `json:"password" schemator:"writeonly"`
+	// Created is set by the server.
+	// schemator:readonly
+	Created string `json:"created"`
+	Name    string `json:"name"`
+}
+
+func TestReadOnlyWriteOnly(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(AccessAccount{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for prop, want := range map[string][2]any{
+		"id":       {true, nil},
+		"password": {nil, true},
+		"created":  {true, nil},
+		"name":     {nil, nil},
+	} {
+		got := doc.Properties[prop]
+		if got["readOnly"] != want[0] || got["writeOnly"] != want[1] {
+			t.Fatalf("%s = %v, want readOnly %v writeOnly %v", prop, got, want[0], want[1])
+		}
+	}
+	if _, err := parseSchematorTag("readonly,writeonly"); err == nil {
+		t.Fatal("parseSchematorTag(readonly,writeonly) error = nil")
+	}
+}
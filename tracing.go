@@ -0,0 +1,49 @@
+package schemator
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the spans of schemator.
+const tracerName = "pkt.systems/schemator"
+
+// tracerKey is the context key of the tracer that package loading and
+// comment parsing, which only see a context, record their spans with.
+type tracerKey struct{}
+
+// startSpan starts the span name as a child of the span in ctx, recorded
+// with the WithTracerProvider tracer, and passes the tracer on to the
+// returned context.
+func (g *generator) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if g.tracer != nil {
+		ctx = context.WithValue(ctx, tracerKey{}, g.tracer)
+	}
+	return startSpan(ctx, name, attrs...)
+}
+
+// startSpan starts the span name as a child of the span in ctx with the
+// tracer of ctx. Without one the span is not recorded.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer, ok := ctx.Value(tracerKey{}).(trace.Tracer)
+	if !ok {
+		tracer = noop.Tracer{}
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, marking it failed with err unless err is nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,55 @@
+package schemator
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+	gen := NewGenerator(ctx, WithTracerProvider(tp))
+	if err := gen.WriteSchemas(t.TempDir(), OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	root.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	for name, parent := range map[string]string{
+		"schemator.Generate":           "root",
+		"schemator.ResolveImportPaths": "schemator.Generate",
+		"schemator.LoadComments":       "schemator.Generate",
+		"schemator.ParseComments":      "schemator.LoadComments",
+		"schemator.Reflect":            "schemator.Generate",
+		"schemator.Write":              "root",
+	} {
+		s, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if p := spans[parent]; p == nil || s.Parent().SpanID() != p.SpanContext().SpanID() {
+			t.Errorf("%s span is not a child of %s", name, parent)
+		}
+	}
+
+	recorder = tracetest.NewSpanRecorder()
+	tp = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, root = tp.Tracer("test").Start(context.Background(), "root")
+	for _, opts := range [][]Option{nil, {WithTracerProvider(tp), WithTracerProvider(nil)}} {
+		if _, err := NewGenerator(ctx, opts...).Generate(OrderedBase{}); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	root.End()
+	if n := len(recorder.Ended()); n != 1 {
+		t.Errorf("%d spans recorded without a tracer provider, want 1", n)
+	}
+}
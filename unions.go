@@ -0,0 +1,247 @@
+package schemator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// RegisterImplementations returns an Option rendering fields of interface
+// type T as a oneOf over the schemas of impls, instead of a schema accepting
+// any value. impls are values of the concrete types implementing T (directly
+// or through a pointer); their definitions are added to $defs.
+//
+//	gen := schemator.NewGenerator(ctx,
+//		schemator.RegisterImplementations[Shape](Circle{}, Square{}),
+//		schemator.WithDiscriminator[Shape]("kind"),
+//	)
+func RegisterImplementations[T any](impls ...any) Option {
+	iface := reflect.TypeFor[T]()
+	return func(g *generator) {
+		if g.implementations == nil {
+			g.implementations = make(map[reflect.Type][]any)
+		}
+		g.implementations[iface] = append(g.implementations[iface], impls...)
+	}
+}
+
+// WithDiscriminator names the property telling the implementations of T
+// registered with RegisterImplementations apart. Each implementation's
+// schema requires the property with a constant value: the value the
+// registered implementation serializes it with, or its type name if that is
+// empty. The union gets an OpenAPI style "discriminator" keyword mapping
+// values to definitions.
+func WithDiscriminator[T any](property string) Option {
+	iface := reflect.TypeFor[T]()
+	return func(g *generator) {
+		if g.discriminators == nil {
+			g.discriminators = make(map[reflect.Type]string)
+		}
+		g.discriminators[iface] = property
+	}
+}
+
+// oneOfDirective lists the implementations of an interface type in its doc
+// comment, as an alternative to RegisterImplementations:
+//
+//	// Shape is a geometric shape.
+//	// schemator:oneof=Circle,Square
+//	type Shape interface{ Area() float64 }
+//
+// Names without an import path are types of the interface's package. As Go
+// cannot look types up by name at run time, every listed type must be known
+// to the generator: registered with RegisterImplementations or
+// WithNamedSchema, or used by the reflected model.
+const oneOfDirective = schematorDirective + "oneof"
+
+// oneOfModels returns values of the types names lists for iface.
+func (g *generator) oneOfModels(rf *reflection, iface reflect.Type, names []string) ([]any, error) {
+	known := make(map[string]any)
+	add := func(model any) {
+		if t := derefType(reflect.TypeOf(model)); t != nil && typeID(t) != "" {
+			known[typeID(t)] = model
+		}
+	}
+	for _, t := range rf.types {
+		add(reflect.New(derefType(t)).Elem().Interface())
+	}
+	for _, model := range g.namedSchemas {
+		add(model)
+	}
+	for _, impls := range g.implementations {
+		for _, model := range impls {
+			add(model)
+		}
+	}
+	var models []any
+	for _, list := range names {
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			key := name
+			if !strings.Contains(name, ".") {
+				key = iface.PkgPath() + "." + name
+			}
+			model, ok := known[key]
+			if !ok {
+				return nil, fmt.Errorf("%s: %s lists %s, which is neither registered nor used by the model", typeKey(iface), oneOfDirective, name)
+			}
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// implementation is a registered implementation of an interface.
+type implementation struct {
+	model any
+	name  string
+	// tag is the discriminator value, if the interface has a discriminator.
+	tag string
+}
+
+// applyImplementations is the schema pass replacing properties of
+// interface types with registered implementations by unions.
+func (g *generator) applyImplementations(rf *reflection, s *jsonschema.Schema) error {
+	if len(g.implementations) == 0 && !rf.hasDirective(oneOfDirective) {
+		return nil
+	}
+	added := make(map[string]bool)
+	union := func(iface reflect.Type) (*jsonschema.Schema, error) {
+		impls, err := g.resolveImplementations(rf, iface)
+		if err != nil || impls == nil {
+			return nil, err
+		}
+		u := &jsonschema.Schema{}
+		property := g.discriminators[iface]
+		mapping := make(map[string]any)
+		for _, impl := range impls {
+			if !added[impl.name] {
+				added[impl.name] = true
+				if err := g.addNamedDefinition(rf, s, impl.name, impl.model); err != nil {
+					return nil, err
+				}
+				if def := s.Definitions[impl.name]; def != nil && property != "" {
+					setDiscriminator(def, property, impl.tag)
+				}
+			}
+			ref := "#/$defs/" + impl.name
+			u.OneOf = append(u.OneOf, &jsonschema.Schema{Ref: ref})
+			if property != "" {
+				mapping[impl.tag] = ref
+			}
+		}
+		if property != "" {
+			setExtra(u, "discriminator", map[string]any{"propertyName": property, "mapping": mapping})
+		}
+		return u, nil
+	}
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			if err := g.applyUnion(fv.field.Type, fv.schema, union); err != nil {
+				return fmt.Errorf("%s: %w", fieldRef(fv.owner, fv.field), err)
+			}
+			return nil
+		})
+	})
+}
+
+// applyUnion replaces s, or the schema of the elements of t within s, by the
+// union of the implementations of its interface type.
+func (g *generator) applyUnion(t reflect.Type, s *jsonschema.Schema, union func(reflect.Type) (*jsonschema.Schema, error)) error {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) || s.Ref != "" {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if !acceptsAnything(s) {
+			return nil
+		}
+		u, err := union(t)
+		if err != nil || u == nil {
+			return err
+		}
+		s.OneOf = u.OneOf
+		s.Extras = u.Extras
+	case reflect.Slice, reflect.Array:
+		return g.applyUnion(t.Elem(), s.Items, union)
+	case reflect.Map:
+		return g.applyUnion(t.Elem(), s.AdditionalProperties, union)
+	}
+	return nil
+}
+
+// resolveImplementations checks the implementations of iface, listed by a
+// oneof directive or registered, and names them, sorted by name. It returns
+// nil if iface has neither.
+func (g *generator) resolveImplementations(rf *reflection, iface reflect.Type) ([]implementation, error) {
+	models := g.implementations[iface]
+	if names := directiveValues(rf.markers[typeKey(iface)], oneOfDirective); len(names) > 0 {
+		var err error
+		if models, err = g.oneOfModels(rf, iface, names); err != nil {
+			return nil, err
+		}
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+	property := g.discriminators[iface]
+	var impls []implementation
+	seen := make(map[string]bool)
+	for _, model := range models {
+		t := reflect.TypeOf(model)
+		if t == nil || (!t.Implements(iface) && !reflect.PointerTo(t).Implements(iface)) {
+			return nil, fmt.Errorf("%T does not implement %s", model, iface)
+		}
+		impl := implementation{model: model, name: rf.definitionName(derefType(t))}
+		if impl.name == "" {
+			return nil, fmt.Errorf("implementation %s of %s is not a named type", t, iface)
+		}
+		if seen[impl.name] {
+			continue
+		}
+		seen[impl.name] = true
+		if property != "" {
+			impl.tag = discriminatorValue(model, property)
+			if impl.tag == "" {
+				impl.tag = impl.name
+			}
+		}
+		impls = append(impls, impl)
+	}
+	sort.Slice(impls, func(i, j int) bool { return impls[i].name < impls[j].name })
+	return impls, nil
+}
+
+// discriminatorValue returns the string model serializes property as, or
+// "".
+func discriminatorValue(model any, property string) string {
+	b, err := json.Marshal(model)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return ""
+	}
+	v, _ := fields[property].(string)
+	return v
+}
+
+// setDiscriminator makes def require property with the constant value tag.
+func setDiscriminator(def *jsonschema.Schema, property, tag string) {
+	if def.Properties == nil {
+		def.Properties = jsonschema.NewProperties()
+	}
+	prop, ok := def.Properties.Get(property)
+	if !ok || prop == nil || isBooleanSchema(prop) {
+		prop = &jsonschema.Schema{Type: "string"}
+		def.Properties.Set(property, prop)
+	}
+	prop.Const = tag
+	def.Required = appendUnique(def.Required, property)
+}
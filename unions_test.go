@@ -0,0 +1,146 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// UnionShape is implemented by UnionCircle and UnionSquare.
+type UnionShape interface{ Area() float64 }
+
+// UnionCircle is a circle.
+type UnionCircle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (c UnionCircle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+// UnionSquare is a square.
+type UnionSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s *UnionSquare) Area() float64 { return s.Side * s.Side }
+
+// UnionDrawing holds shapes.
+type UnionDrawing struct {
+	Main   UnionShape   `json:"main"`
+	Others []UnionShape `json:"others"`
+}
+
+func TestRegisterImplementations(t *testing.T) {
+	g := NewGenerator(context.Background(), RegisterImplementations[UnionShape](UnionSquare{}, UnionCircle{Kind: "circle"}))
+	out, err := g.Generate(UnionDrawing{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	union := `{"oneOf":[{"$ref":"#/$defs/UnionCircle"},{"$ref":"#/$defs/UnionSquare"}]}`
+	if got := compactJSON(doc.Properties["main"]); got != union {
+		t.Fatalf("main = %s, want %s", got, union)
+	}
+	if got := compactJSON(doc.Properties["others"]); got != `{"items":`+union+`,"type":"array"}` {
+		t.Fatalf("others = %s", got)
+	}
+	if doc.Defs["UnionCircle"] == nil || doc.Defs["UnionSquare"] == nil {
+		t.Fatalf("implementation definitions missing: %s", out)
+	}
+}
+
+func TestDiscriminator(t *testing.T) {
+	g := NewGenerator(context.Background(),
+		RegisterImplementations[UnionShape](UnionCircle{Kind: "circle"}, UnionSquare{}),
+		WithDiscriminator[UnionShape]("kind"),
+	)
+	out, err := g.Generate(UnionDrawing{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"mapping":{"UnionSquare":"#/$defs/UnionSquare","circle":"#/$defs/UnionCircle"},"propertyName":"kind"}`
+	if got := compactJSON(doc.Properties["main"]["discriminator"]); got != want {
+		t.Fatalf("discriminator = %s, want %s", got, want)
+	}
+	for name, tag := range map[string]string{"UnionCircle": "circle", "UnionSquare": "UnionSquare"} {
+		def := doc.Defs[name]
+		if def.Properties["kind"]["const"] != tag || !strings.Contains(strings.Join(def.Required, ","), "kind") {
+			t.Fatalf("%s = %+v, want required kind %q", name, def, tag)
+		}
+	}
+}
+
+func TestRegisterImplementationsRejectsNonImplementations(t *testing.T) {
+	_, err := NewGenerator(context.Background(), RegisterImplementations[UnionShape](TaggedUser{})).Generate(UnionDrawing{})
+	if err == nil || !strings.Contains(err.Error(), "does not implement") {
+		t.Fatalf("Generate() error = %v, want implementation error", err)
+	}
+}
+
+// UnionAnimal is a sum type declared in source.
+// schemator:oneof=UnionCat, UnionDog
+type UnionAnimal interface{ Sound() string }
+
+// UnionCat meows.
+type UnionCat struct {
+	Lives int `json:"lives"`
+}
+
+func (UnionCat) Sound() string { return "meow" }
+
+// UnionDog barks.
+type UnionDog struct {
+	Breed string `json:"breed"`
+}
+
+func (UnionDog) Sound() string { return "woof" }
+
+// UnionFarm keeps animals.
+type UnionFarm struct {
+	Pet    UnionAnimal `json:"pet"`
+	Barker UnionDog    `json:"barker"`
+}
+
+func TestOneOfDirective(t *testing.T) {
+	g := NewGenerator(context.Background(), WithNamedSchema("UnionCat", UnionCat{}))
+	out, err := g.Generate(UnionFarm{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"oneOf":[{"$ref":"#/$defs/UnionCat"},{"$ref":"#/$defs/UnionDog"}]}`
+	if got := compactJSON(doc.Properties["pet"]); got != want {
+		t.Fatalf("pet = %s, want %s", got, want)
+	}
+	if doc.Defs["UnionCat"] == nil {
+		t.Fatalf("UnionCat not defined: %s", out)
+	}
+
+	_, err = NewGenerator(context.Background()).Generate(UnionFarm{})
+	if err == nil || !strings.Contains(err.Error(), "UnionCat") {
+		t.Fatalf("Generate() error = %v, want unresolved UnionCat", err)
+	}
+}
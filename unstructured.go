@@ -0,0 +1,51 @@
+package schemator
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// rawMessageType is json.RawMessage, which holds JSON of any shape.
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// unstructured reports whether values of t hold JSON of any shape:
+// json.RawMessage, maps from strings to any, and the Kubernetes types
+// carrying embedded objects, such as runtime.RawExtension.
+func unstructured(t reflect.Type) bool {
+	if t == rawMessageType {
+		return true
+	}
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.Interface && t.Elem().NumMethod() == 0 {
+		return true
+	}
+	switch typeKey(t) {
+	case "k8s.io/apimachinery/pkg/runtime.RawExtension",
+		"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured.Unstructured",
+		"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON":
+		return true
+	}
+	return false
+}
+
+// WithUnstructuredSchema sets the schema of fields holding JSON of any
+// shape: json.RawMessage, map[string]any and the Kubernetes
+// runtime.RawExtension, unstructured.Unstructured and apiextensions JSON
+// types. It defaults to a free-form object, {"type": "object"}. fn is called
+// for every field, so each gets its own schema; jsonschema tags and
+// "schemator:ref" directives still override it per field.
+func WithUnstructuredSchema(fn func() *jsonschema.Schema) Option {
+	return func(g *generator) {
+		g.unstructured = fn
+	}
+}
+
+// unstructuredSchema returns the schema of a field holding JSON of any
+// shape, as set with WithUnstructuredSchema.
+func (g *generator) unstructuredSchema() *jsonschema.Schema {
+	if g.unstructured != nil {
+		return g.unstructured()
+	}
+	return &jsonschema.Schema{Type: "object"}
+}
@@ -0,0 +1,57 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+type UnstructuredLabels map[string]any
+
+type UnstructuredEvent struct {
+	Payload json.RawMessage    `json:"payload"`
+	Attrs   map[string]any     `json:"attrs"`
+	Labels  UnstructuredLabels `json:"labels"`
+	Batch   json.RawMessage    `json:"batch" jsonschema:"type=array"`
+	Counts  map[string]int     `json:"counts"`
+}
+
+func TestUnstructuredFields(t *testing.T) {
+	generate := func(opts ...Option) map[string]any {
+		t.Helper()
+		out, err := NewGenerator(context.Background(), opts...).Generate(UnstructuredEvent{})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc["properties"].(map[string]any)
+	}
+	props := generate()
+	for name, want := range map[string]string{
+		"payload": `{"type":"object"}`,
+		"attrs":   `{"type":"object"}`,
+		"labels":  `{"type":"object"}`,
+		"batch":   `{"type":"array"}`,
+		"counts":  `{"additionalProperties":{"type":"integer"},"type":"object"}`,
+	} {
+		if got := compactJSON(props[name]); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	// Accept any JSON value, not only objects.
+	props = generate(WithUnstructuredSchema(func() *jsonschema.Schema {
+		return &jsonschema.Schema{}
+	}))
+	if got := compactJSON(props["payload"]); got != "true" {
+		t.Errorf("payload = %s", got)
+	}
+	if got := compactJSON(props["batch"]); got != `{"type":"array"}` {
+		t.Errorf("batch = %s", got)
+	}
+}
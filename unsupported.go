@@ -0,0 +1,52 @@
+package schemator
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+)
+
+// unsupported reports whether neither encoding/json nor the Reflector can
+// handle t: channels, functions, unsafe pointers and complex numbers without
+// a registered type mapping or JSONSchema method, and pointers, slices,
+// arrays and maps of them.
+func (g *generator) unsupported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		if _, ok := g.typeMappings[t]; ok {
+			return false
+		}
+		return !t.Implements(schemaDescriberType) && !reflect.PointerTo(t).Implements(schemaDescriberType)
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return g.unsupported(t.Elem())
+	}
+	return false
+}
+
+// schemaDescriberType is the interface of types with a JSONSchema method,
+// which the Reflector uses instead of reflecting them.
+var schemaDescriberType = reflect.TypeFor[interface {
+	JSONSchema() *jsonschema.Schema
+}]()
+
+// applyUnsupportedKinds is the schema pass removing the properties of fields
+// of kinds encoding/json cannot encode, which the Mapper left as empty
+// schemas, or failing on them in strict mode.
+func (g *generator) applyUnsupportedKinds(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			ft := fv.field.Type
+			if !g.unsupported(ft) {
+				return nil
+			}
+			if g.strict {
+				return fmt.Errorf("%s: %s cannot be encoded as JSON", fieldRef(fv.owner, fv.field), ft)
+			}
+			rf.warn(SkippedField, fieldRef(fv.owner, fv.field), "%s cannot be encoded as JSON, the field is left out", ft)
+			fv.parent.Properties.Delete(fv.name)
+			fv.parent.Required = removeString(fv.parent.Required, fv.name)
+			return nil
+		})
+	})
+}
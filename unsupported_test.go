@@ -0,0 +1,89 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/invopop/jsonschema"
+)
+
+// UnsupportedCallback describes itself, so it is kept.
+type UnsupportedCallback func()
+
+func (UnsupportedCallback) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "string"}
+}
+
+type UnsupportedKinds struct {
+	Name     string              `json:"name"`
+	Done     chan struct{}       `json:"done"`
+	Handler  func() error        `json:"handler"`
+	Raw      unsafe.Pointer      `json:"raw"`
+	Phase    complex128          `json:"phase"`
+	Hooks    map[string]func()   `json:"hooks"`
+	Callback UnsupportedCallback `json:"callback"`
+}
+
+func TestUnsupportedKinds(t *testing.T) {
+	var warnings []string
+	out, err := NewGenerator(context.Background(), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w.Field)
+	})).Generate(UnsupportedKinds{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range doc.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"callback", "name"}) || !slices.Equal(doc.Required, []string{"name", "callback"}) {
+		t.Errorf("properties = %v, required = %v", names, doc.Required)
+	}
+	if len(warnings) != 5 {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	_, err = NewGenerator(context.Background(), WithStrict()).Generate(UnsupportedKinds{})
+	if err == nil || !strings.Contains(err.Error(), "pkt.systems/schemator.UnsupportedKinds.Done: chan struct {} cannot be encoded as JSON") {
+		t.Fatalf("WithStrict(): error = %v", err)
+	}
+}
+
+type unsupportedUnexported struct{}
+
+func TestStrictUnnamedModels(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewGenerator(context.Background()).WriteSchemas(dir, struct{ ID string }{}, OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	gen := NewGenerator(context.Background(), WithStrict())
+	for _, tt := range []struct {
+		model any
+		want  string
+	}{
+		{struct{ ID string }{}, "cannot derive a filename for model struct { ID string }: it is an anonymous struct"},
+		{unsupportedUnexported{}, "its type is unexported"},
+		{map[string]int{}, "its type map[string]int has no name"},
+	} {
+		err := gen.WriteSchemas(dir, OrderedBase{}, tt.model)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("WriteSchemas(%T) error = %v, want %q", tt.model, err, tt.want)
+		}
+		err = gen.WriteAll(dir, Output{Model: tt.model})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("WriteAll(%T) error = %v, want %q", tt.model, err, tt.want)
+		}
+	}
+}
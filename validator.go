@@ -0,0 +1,261 @@
+package schemator
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// validatorFormats maps go-playground/validator baked-in tags to JSON Schema
+// formats.
+var validatorFormats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"uri":              "uri-reference",
+	"http_url":         "uri",
+	"hostname":         "hostname",
+	"fqdn":             "hostname",
+	"ipv4":             "ipv4",
+	"ip4_addr":         "ipv4",
+	"ipv6":             "ipv6",
+	"ip6_addr":         "ipv6",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"hostname_rfc1123": "hostname",
+}
+
+// validatorPatterns maps go-playground/validator baked-in tags to the
+// regular expressions they check.
+var validatorPatterns = map[string]string{
+	"alpha":        "^[a-zA-Z]+$",
+	"alphanum":     "^[a-zA-Z0-9]+$",
+	"alphaunicode": `^[\p{L}]+$`,
+	"numeric":      `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":       "^[0-9]+$",
+	"hexadecimal":  "^(0[xX])?[0-9a-fA-F]+$",
+	"lowercase":    "^[^A-Z]*$",
+	"uppercase":    "^[^a-z]*$",
+	"e164":         `^\+[1-9]?[0-9]{7,14}$`,
+}
+
+// applyValidatorTags translates go-playground/validator struct tags
+// (`validate:"required,min=1,max=64,oneof=a b c,email"`) into the matching
+// JSON Schema keywords, so constraints need not be repeated in jsonschema
+// tags. Bounds apply to the length of strings, the size of slices and maps
+// and the value of numbers; tags after "dive" apply to the elements.
+// Alternatives ("a|b"), cross-field tags and unknown rules cannot be
+// expressed and are ignored with a warning.
+func applyValidatorTags(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			tag, ok := fv.field.Tag.Lookup("validate")
+			if !ok || tag == "-" {
+				return nil
+			}
+			rules := splitValidatorTag(tag)
+			for _, r := range rules {
+				if r == "required" {
+					fv.parent.Required = appendUnique(fv.parent.Required, fv.name)
+				}
+				if r == "dive" {
+					break
+				}
+			}
+			for _, r := range applyValidatorRules(fv.field.Type, fv.schema, rules) {
+				rf.warn(UnknownTag, fieldRef(fv.owner, fv.field), "validate rule %q has no JSON Schema equivalent", r)
+			}
+			return nil
+		})
+	})
+}
+
+// splitValidatorTag splits a validate tag into its comma separated rules,
+// restoring the 0x2C escaped commas validator supports inside parameters.
+func splitValidatorTag(tag string) []string {
+	rules := strings.Split(tag, ",")
+	for i, r := range rules {
+		rules[i] = strings.ReplaceAll(strings.ReplaceAll(r, "0x2C", ","), "0x7C", "|")
+	}
+	return rules
+}
+
+// applyValidatorRules applies rules to s and returns the rules it could not
+// translate.
+func applyValidatorRules(t reflect.Type, s *jsonschema.Schema, rules []string) []string {
+	t = derefType(t)
+	if s == nil || isBooleanSchema(s) {
+		return nil
+	}
+	s = unwrapNullable(s)
+	var ignored []string
+	for i := 0; i < len(rules); i++ {
+		rule := rules[i]
+		if strings.Contains(rule, "|") {
+			ignored = append(ignored, rule)
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "", "required", "omitempty", "omitnil":
+		case "dive":
+			rest := rules[i+1:]
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				ignored = append(ignored, applyValidatorRules(t.Elem(), s.Items, rest)...)
+			case reflect.Map:
+				var keys []string
+				rest, keys = applyValidatorKeys(t.Key(), s, rest)
+				ignored = append(ignored, keys...)
+				ignored = append(ignored, applyValidatorRules(t.Elem(), s.AdditionalProperties, rest)...)
+			}
+			return ignored
+		case "min", "gte":
+			setBound(t, s, param, &s.Minimum, &s.MinLength, &s.MinItems, &s.MinProperties)
+		case "max", "lte":
+			setBound(t, s, param, &s.Maximum, &s.MaxLength, &s.MaxItems, &s.MaxProperties)
+		case "gt":
+			if isNumericKind(t.Kind()) {
+				_ = setNumber(&s.ExclusiveMinimum, param)
+			} else if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+				n++
+				setBound(t, s, strconv.FormatUint(n, 10), &s.Minimum, &s.MinLength, &s.MinItems, &s.MinProperties)
+			}
+		case "lt":
+			if isNumericKind(t.Kind()) {
+				_ = setNumber(&s.ExclusiveMaximum, param)
+			} else if n, err := strconv.ParseUint(param, 10, 64); err == nil && n > 0 {
+				n--
+				setBound(t, s, strconv.FormatUint(n, 10), &s.Maximum, &s.MaxLength, &s.MaxItems, &s.MaxProperties)
+			}
+		case "len":
+			setBound(t, s, param, &s.Minimum, &s.MinLength, &s.MinItems, &s.MinProperties)
+			setBound(t, s, param, &s.Maximum, &s.MaxLength, &s.MaxItems, &s.MaxProperties)
+		case "eq":
+			if v, ok := validatorValue(t, param); ok {
+				s.Const = v
+			}
+		case "oneof":
+			s.Enum = nil
+			for _, item := range splitOneOf(param) {
+				if v, ok := validatorValue(t, item); ok {
+					s.Enum = append(s.Enum, v)
+				}
+			}
+		case "unique":
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+				s.UniqueItems = true
+			}
+		case "base64":
+			s.ContentEncoding = "base64"
+		case "contains":
+			setPattern(s, regexp.QuoteMeta(param))
+		case "startswith":
+			setPattern(s, "^"+regexp.QuoteMeta(param))
+		case "endswith":
+			setPattern(s, regexp.QuoteMeta(param)+"$")
+		default:
+			if format, ok := validatorFormats[name]; ok && s.Format == "" {
+				s.Format = format
+			} else if pattern, ok := validatorPatterns[name]; ok {
+				setPattern(s, pattern)
+			} else if _, ok := validatorFormats[name]; !ok {
+				ignored = append(ignored, rule)
+			}
+		}
+	}
+	return ignored
+}
+
+// applyValidatorKeys applies the rules between "keys" and "endkeys" to the
+// property names of a map and returns the rules following them and the key
+// rules it could not translate.
+func applyValidatorKeys(key reflect.Type, s *jsonschema.Schema, rules []string) ([]string, []string) {
+	if len(rules) == 0 || rules[0] != "keys" {
+		return rules, nil
+	}
+	for i, r := range rules {
+		if r == "endkeys" {
+			if s.PropertyNames == nil {
+				s.PropertyNames = &jsonschema.Schema{Type: "string"}
+			}
+			return rules[i+1:], applyValidatorRules(key, s.PropertyNames, rules[1:i])
+		}
+	}
+	return nil, nil
+}
+
+// setBound sets the bound matching the kind of t: a number for numbers, a
+// length for strings, an item count for slices and a property count for
+// maps.
+func setBound(t reflect.Type, s *jsonschema.Schema, param string, number *json.Number, length, items, properties **uint64) {
+	switch kind := t.Kind(); {
+	case isNumericKind(kind):
+		_ = setNumber(number, param)
+	case kind == reflect.String:
+		_ = setCount(length, param)
+	case kind == reflect.Slice || kind == reflect.Array:
+		_ = setCount(items, param)
+	case kind == reflect.Map:
+		_ = setCount(properties, param)
+	}
+}
+
+func setPattern(s *jsonschema.Schema, pattern string) {
+	if s.Pattern == "" {
+		s.Pattern = pattern
+		return
+	}
+	s.AllOf = append(s.AllOf, &jsonschema.Schema{Pattern: pattern})
+}
+
+// splitOneOf splits the space separated oneof parameter, honouring single
+// quotes around values containing spaces.
+func splitOneOf(param string) []string {
+	var out []string
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if param[0] == '\'' {
+			if end := strings.IndexByte(param[1:], '\''); end >= 0 {
+				out = append(out, param[1:end+1])
+				param = param[end+2:]
+				continue
+			}
+		}
+		item, rest, _ := strings.Cut(param, " ")
+		out = append(out, item)
+		param = rest
+	}
+	return out
+}
+
+// validatorValue converts a tag parameter to a value of kind t.
+func validatorValue(t reflect.Type, param string) (any, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return param, true
+	case reflect.Bool:
+		b, err := strconv.ParseBool(param)
+		return b, err == nil
+	}
+	if isNumericKind(t.Kind()) {
+		if _, err := strconv.ParseFloat(param, 64); err == nil {
+			return json.Number(param), true
+		}
+	}
+	return nil, false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
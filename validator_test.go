@@ -0,0 +1,63 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// ValidatedRequest is annotated with go-playground/validator tags.
+type ValidatedRequest struct {
+	Name   string            `json:"name,omitempty" validate:"required,min=1,max=64"`
+	Kind   string            `json:"kind" validate:"oneof=a b 'c d'"`
+	Email  string            `json:"email" validate:"omitempty,email"`
+	Age    int               `json:"age" validate:"gte=0,lt=150"`
+	Tags   []string          `json:"tags" validate:"max=5,unique,dive,alphanum,len=3"`
+	Labels map[string]string `json:"labels" validate:"dive,keys,startswith=x-,endkeys,max=10"`
+	Either string            `json:"either" validate:"email|url"`
+}
+
+func TestValidatorTags(t *testing.T) {
+	out, err := New(context.Background(), nil).Generate(ValidatedRequest{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var doc struct {
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(doc.Required, "name") {
+		t.Fatalf("required = %v, want name", doc.Required)
+	}
+	check := func(prop string, want map[string]any) {
+		t.Helper()
+		got := doc.Properties[prop]
+		for k, v := range want {
+			if !reflect.DeepEqual(got[k], v) {
+				t.Fatalf("%s.%s = %#v, want %#v (%v)", prop, k, got[k], v, got)
+			}
+		}
+	}
+	check("name", map[string]any{"minLength": 1.0, "maxLength": 64.0})
+	check("kind", map[string]any{"enum": []any{"a", "b", "c d"}})
+	check("email", map[string]any{"format": "email"})
+	check("age", map[string]any{"minimum": 0.0, "exclusiveMaximum": 150.0})
+	check("tags", map[string]any{"maxItems": 5.0, "uniqueItems": true})
+	check("either", map[string]any{"format": nil})
+	items := doc.Properties["tags"]["items"].(map[string]any)
+	if items["pattern"] != "^[a-zA-Z0-9]+$" || items["minLength"] != 3.0 || items["maxLength"] != 3.0 {
+		t.Fatalf("tags.items = %v", items)
+	}
+	labels := doc.Properties["labels"]
+	if names := labels["propertyNames"].(map[string]any); names["pattern"] != "^x-" {
+		t.Fatalf("labels.propertyNames = %v", names)
+	}
+	if values := labels["additionalProperties"].(map[string]any); values["maxLength"] != 10.0 {
+		t.Fatalf("labels.additionalProperties = %v", values)
+	}
+}
@@ -0,0 +1,92 @@
+package schemator
+
+import (
+	"go/version"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// vendorDir returns the vendor directory the go command reads the
+// dependencies of dir from, or "" if it does not vendor them. The vendor
+// directory of the go.work workspace or else the main module of dir is used
+// when GOFLAGS has -mod=vendor, or without a -mod flag when it holds a
+// modules.txt and the go.work or go.mod declares a Go version vendoring by
+// default (1.22 and 1.14).
+func vendorDir(dir string) (string, error) {
+	mode := ""
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if m, ok := strings.CutPrefix(strings.TrimLeft(flag, "-"), "mod="); ok {
+			mode = m
+		}
+	}
+	if mode != "" && mode != "vendor" {
+		return "", nil
+	}
+	root, goFile, minVersion := "", "", ""
+	workFile, err := findWorkFile(dir)
+	if err != nil {
+		return "", err
+	}
+	if workFile != "" {
+		root, goFile, minVersion = filepath.Dir(workFile), workFile, "go1.22"
+	} else {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", err
+		}
+		moduleDir, _, err := findModulePath(absDir)
+		if err != nil {
+			return "", nil
+		}
+		root, goFile, minVersion = moduleDir, filepath.Join(moduleDir, "go.mod"), "go1.14"
+	}
+	vendor := filepath.Join(root, "vendor")
+	if _, err := os.Stat(filepath.Join(vendor, "modules.txt")); err != nil {
+		return "", nil
+	}
+	if mode == "vendor" {
+		return vendor, nil
+	}
+	data, err := os.ReadFile(goFile)
+	if err != nil {
+		return "", err
+	}
+	goVersion := ""
+	if workFile != "" {
+		wf, err := modfile.ParseWork(goFile, data, nil)
+		if err != nil {
+			return "", err
+		}
+		if wf.Go != nil {
+			goVersion = wf.Go.Version
+		}
+	} else {
+		f, err := modfile.Parse(goFile, data, nil)
+		if err != nil {
+			return "", err
+		}
+		if f.Go != nil {
+			goVersion = f.Go.Version
+		}
+	}
+	if goVersion == "" || version.Compare("go"+goVersion, minVersion) < 0 {
+		return "", nil
+	}
+	return vendor, nil
+}
+
+// vendoredPackageDir returns the directory of importPath in vendor, if it
+// was vendored.
+func vendoredPackageDir(vendor, importPath string) (string, bool) {
+	if vendor == "" {
+		return "", false
+	}
+	dir := filepath.Join(vendor, filepath.FromSlash(importPath))
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
@@ -0,0 +1,49 @@
+package schemator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestVendoredPackages(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n")
+	writeFile(t, filepath.Join(dir, "vendor", "modules.txt"), "# example.com/dep v1.0.0\n## explicit\nexample.com/dep\n")
+	writeFile(t, filepath.Join(dir, "vendor", "example.com", "dep", "dep.go"), "package dep\n\n// Thing is vendored.\ntype Thing struct{}\n")
+	vendored := filepath.Join(dir, "vendor", "example.com", "dep")
+
+	for _, tt := range []struct {
+		goflags string
+		want    string
+	}{
+		{"", filepath.Join(dir, "vendor")},
+		{"-mod=vendor", filepath.Join(dir, "vendor")},
+		{"-mod=mod", ""},
+		{"-trimpath --mod=readonly", ""},
+	} {
+		t.Setenv("GOFLAGS", tt.goflags)
+		if got, err := vendorDir(dir); err != nil || got != tt.want {
+			t.Errorf("GOFLAGS=%q: vendorDir() = %q, %v, want %q", tt.goflags, got, err, tt.want)
+		}
+	}
+
+	t.Setenv("GOFLAGS", "")
+	got, _, err := lookupPackageDir(context.Background(), "example.com/dep", dir)
+	if err != nil || got != vendored {
+		t.Errorf("lookupPackageDir() = %q, %v, want %q", got, err, vendored)
+	}
+
+	// Before Go 1.14, vendoring has to be asked for.
+	old := t.TempDir()
+	writeFile(t, filepath.Join(old, "go.mod"), "module example.com/old\n\ngo 1.13\n")
+	writeFile(t, filepath.Join(old, "vendor", "modules.txt"), "")
+	if got, err := vendorDir(old); err != nil || got != "" {
+		t.Errorf("go 1.13: vendorDir() = %q, %v", got, err)
+	}
+	t.Setenv("GOFLAGS", "-mod=vendor")
+	if got, err := vendorDir(old); err != nil || got != filepath.Join(old, "vendor") {
+		t.Errorf("go 1.13 with -mod=vendor: vendorDir() = %q, %v", got, err)
+	}
+}
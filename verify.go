@@ -0,0 +1,313 @@
+package schemator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"pkt.systems/logport"
+)
+
+// Drift is a single difference between a generated schema and the schema
+// file committed in the output directory.
+type Drift struct {
+	// File is the schema filename relative to the output directory.
+	File string `json:"file"`
+	// Pointer is the RFC 6901 JSON pointer of the differing value. The empty
+	// pointer refers to the whole document.
+	Pointer string `json:"pointer"`
+	// Message describes the difference.
+	Message string `json:"message"`
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s#%s: %s", d.File, d.Pointer, d.Message)
+}
+
+// DriftError is returned by Verify when generated schemas differ from the
+// committed files in ways not covered by an active suppression, or when the
+// suppression file contains expired entries.
+type DriftError struct {
+	Drifts  []Drift
+	Expired []Suppression
+}
+
+func (e *DriftError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema drift detected (%d difference(s), %d expired suppression(s))", len(e.Drifts), len(e.Expired))
+	for _, d := range e.Drifts {
+		b.WriteString("\n  ")
+		b.WriteString(d.String())
+	}
+	for _, s := range e.Expired {
+		fmt.Fprintf(&b, "\n  suppression %s#%s expired %s: %s", s.File, s.Pointer, s.Expires.Format(time.DateOnly), s.Reason)
+	}
+	return b.String()
+}
+
+// Suppression marks a known and accepted difference between generated and
+// committed schemas, e.g. during a migration window.
+type Suppression struct {
+	// File limits the suppression to one schema file. Empty matches any file.
+	File string `json:"file,omitempty"`
+	// Pointer is the JSON pointer of the accepted difference. Differences at
+	// or below the pointer are suppressed.
+	Pointer string `json:"pointer"`
+	// Reason documents why the drift is accepted.
+	Reason string `json:"reason"`
+	// Expires is the date after which the suppression no longer applies and
+	// is reported as an error instead.
+	Expires time.Time `json:"expires"`
+}
+
+// Matches reports whether s covers d.
+func (s Suppression) Matches(d Drift) bool {
+	if s.File != "" && s.File != d.File {
+		return false
+	}
+	return d.Pointer == s.Pointer || s.Pointer == "" || strings.HasPrefix(d.Pointer, s.Pointer+"/")
+}
+
+// Expired reports whether s has expired at now.
+func (s Suppression) Expired(now time.Time) bool {
+	return !s.Expires.IsZero() && now.After(s.Expires)
+}
+
+func (s *Suppression) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		File    string `json:"file"`
+		Pointer string `json:"pointer"`
+		Reason  string `json:"reason"`
+		Expires string `json:"expires"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Reason == "" {
+		return fmt.Errorf("suppression %s#%s is missing a reason", raw.File, raw.Pointer)
+	}
+	*s = Suppression{File: raw.File, Pointer: raw.Pointer, Reason: raw.Reason}
+	if raw.Expires == "" {
+		return nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw.Expires); err == nil {
+		// A date expires at the end of that day.
+		s.Expires = t.Add(24*time.Hour - time.Nanosecond)
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, raw.Expires)
+	if err != nil {
+		return fmt.Errorf("suppression %s#%s: invalid expires %q", raw.File, raw.Pointer, raw.Expires)
+	}
+	s.Expires = t
+	return nil
+}
+
+func (s Suppression) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		File    string `json:"file,omitempty"`
+		Pointer string `json:"pointer"`
+		Reason  string `json:"reason"`
+		Expires string `json:"expires,omitempty"`
+	}{File: s.File, Pointer: s.Pointer, Reason: s.Reason}
+	if !s.Expires.IsZero() {
+		raw.Expires = s.Expires.Format(time.RFC3339)
+	}
+	return json.Marshal(raw)
+}
+
+// LoadSuppressions reads a suppression file of the form
+//
+//	{
+//	  "suppressions": [
+//	    {"file": "Subject.schema.json", "pointer": "/properties/id", "reason": "migrating to uuid", "expires": "2026-12-31"}
+//	  ]
+//	}
+//
+// expires is either a date (inclusive) or an RFC 3339 timestamp.
+func LoadSuppressions(path string) ([]Suppression, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Suppressions []Suppression `json:"suppressions"`
+	}
+	if err := json.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("parse suppression file %s: %w", path, err)
+	}
+	return doc.Suppressions, nil
+}
+
+func (g *generator) Verify(outputDir string, models ...any) error {
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := logport.LoggerFromContext(ctx).With("outputDir", outputDir, "models", models)
+	var suppressions []Suppression
+	if g.suppressionFile != "" {
+		s, err := LoadSuppressions(g.suppressionFile)
+		if err != nil {
+			return err
+		}
+		suppressions = s
+	}
+	filenames, err := g.schemaFilenames(models)
+	if err != nil {
+		return err
+	}
+	results, genErr := g.generateEach(models, filenames, outputDir)
+	if results == nil {
+		return genErr
+	}
+	var drifts []Drift
+	var checked []ModelStatus
+	for i, model := range models {
+		filename := filenames[i]
+		if filename == "" {
+			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", model)
+			continue
+		}
+		if results[i].err != nil {
+			continue
+		}
+		d, err := diffSchemaFile(filepath.Join(outputDir, filepath.FromSlash(filename)), filename, results[i].out)
+		if err != nil {
+			return errors.Join(genErr, err)
+		}
+		drifts = append(drifts, d...)
+		checked = append(checked, ModelStatus{Model: toString(model), File: filename})
+	}
+	now := time.Now()
+	verifyErr := errors.Join(genErr, applySuppressions(l, drifts, suppressions, now))
+	if g.statusFile == "" && g.badgeFile == "" {
+		return verifyErr
+	}
+	if err := g.writeStatus(newStatus(checked, drifts, verifyErr, now)); err != nil {
+		return errors.Join(verifyErr, err)
+	}
+	return verifyErr
+}
+
+func applySuppressions(l logport.ForLogging, drifts []Drift, suppressions []Suppression, now time.Time) error {
+	derr := &DriftError{}
+	var active []Suppression
+	for _, s := range suppressions {
+		if s.Expired(now) {
+			derr.Expired = append(derr.Expired, s)
+			continue
+		}
+		active = append(active, s)
+	}
+	for _, d := range drifts {
+		suppressed := false
+		for _, s := range active {
+			if s.Matches(d) {
+				l.Info("Suppressed schema drift", "file", d.File, "pointer", d.Pointer, "reason", s.Reason)
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			derr.Drifts = append(derr.Drifts, d)
+		}
+	}
+	if len(derr.Drifts) == 0 && len(derr.Expired) == 0 {
+		return nil
+	}
+	return derr
+}
+
+func diffSchemaFile(path, filename string, generated []byte) ([]Drift, error) {
+	committed, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Drift{{File: filename, Message: "schema file does not exist"}}, nil
+		}
+		return nil, err
+	}
+	var want, got any
+	if err := json.Unmarshal(generated, &want); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(committed, &got); err != nil {
+		return []Drift{{File: filename, Message: fmt.Sprintf("committed schema is not valid JSON: %v", err)}}, nil
+	}
+	var drifts []Drift
+	diffJSON(filename, "", want, got, &drifts)
+	return drifts, nil
+}
+
+// diffJSON appends the differences between the generated value want and the
+// committed value got to drifts.
+func diffJSON(filename, pointer string, want, got any, drifts *[]Drift) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := pointer + "/" + escapeJSONPointer(k)
+			wv, inWant := w[k]
+			gv, inGot := g[k]
+			switch {
+			case !inGot:
+				*drifts = append(*drifts, Drift{File: filename, Pointer: p, Message: "added"})
+			case !inWant:
+				*drifts = append(*drifts, Drift{File: filename, Pointer: p, Message: "removed"})
+			default:
+				diffJSON(filename, p, wv, gv, drifts)
+			}
+		}
+		return
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			break
+		}
+		for i := range w {
+			diffJSON(filename, fmt.Sprintf("%s/%d", pointer, i), w[i], g[i], drifts)
+		}
+		return
+	default:
+		if reflect.DeepEqual(want, got) {
+			return
+		}
+	}
+	*drifts = append(*drifts, Drift{
+		File:    filename,
+		Pointer: pointer,
+		Message: fmt.Sprintf("changed from %s to %s", compactJSON(got), compactJSON(want)),
+	})
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,81 @@
+package schemator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pkt.systems/schemator/example"
+)
+
+func TestVerifyDetectsDrift(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	gen := New(ctx, nil)
+	if err := gen.WriteSchemas(outDir, example.Subject{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	if err := gen.Verify(outDir, example.Subject{}); err != nil {
+		t.Fatalf("Verify() on fresh output error = %v", err)
+	}
+
+	file := filepath.Join(outDir, "Subject.schema.json")
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	edited := strings.Replace(string(contents), `"type": "integer"`, `"type": "string"`, 1)
+	writeFile(t, file, edited)
+
+	err = gen.Verify(outDir, example.Subject{})
+	var derr *DriftError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Verify() error = %v, want *DriftError", err)
+	}
+	if len(derr.Drifts) != 1 || derr.Drifts[0].Pointer != "/properties/id/type" {
+		t.Fatalf("unexpected drifts: %+v", derr.Drifts)
+	}
+}
+
+func TestVerifySuppressions(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	gen := New(ctx, nil)
+	if err := gen.WriteSchemas(outDir, example.Subject{}); err != nil {
+		t.Fatalf("WriteSchemas() error = %v", err)
+	}
+	file := filepath.Join(outDir, "Subject.schema.json")
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	writeFile(t, file, strings.Replace(string(contents), `"type": "integer"`, `"type": "string"`, 1))
+
+	active := filepath.Join(t.TempDir(), "active.json")
+	writeFile(t, active, `{"suppressions":[{"file":"Subject.schema.json","pointer":"/properties/id","reason":"migration","expires":"2999-01-01"}]}`)
+	if err := NewGenerator(ctx, WithSuppressionFile(active)).Verify(outDir, example.Subject{}); err != nil {
+		t.Fatalf("Verify() with active suppression error = %v", err)
+	}
+
+	expired := filepath.Join(t.TempDir(), "expired.json")
+	writeFile(t, expired, `{"suppressions":[{"pointer":"/properties/id","reason":"migration","expires":"2000-01-01"}]}`)
+	err = NewGenerator(ctx, WithSuppressionFile(expired)).Verify(outDir, example.Subject{})
+	var derr *DriftError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Verify() error = %v, want *DriftError", err)
+	}
+	if len(derr.Expired) != 1 || len(derr.Drifts) != 1 {
+		t.Fatalf("expected expired suppression and resurfaced drift, got %+v", derr)
+	}
+}
+
+func TestLoadSuppressionsRequiresReason(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.json")
+	writeFile(t, path, `{"suppressions":[{"pointer":"/x"}]}`)
+	if _, err := LoadSuppressions(path); err == nil {
+		t.Fatalf("LoadSuppressions() error = nil, want error")
+	}
+}
@@ -0,0 +1,147 @@
+package schemator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"pkt.systems/logport"
+)
+
+// WarningKind classifies a Warning.
+type WarningKind string
+
+const (
+	// UnknownTag is a struct tag rule, comment marker or directive schemator
+	// does not understand or cannot express, and therefore ignores.
+	UnknownTag WarningKind = "unknown-tag"
+	// SkippedField is a field that looks meant to be serialized but has no
+	// property in the schema, such as an unexported field with a json tag or
+	// a field excluded with `jsonschema:"-"`.
+	SkippedField WarningKind = "skipped-field"
+	// UnmappedType is a field whose type has no schema, leaving a property
+	// that accepts any value.
+	UnmappedType WarningKind = "unmapped-type"
+)
+
+// Warning is a problem found while generating a schema that does not stop
+// generation on its own. Every warning is logged and passed to the
+// WithWarningHandler function; WithMaxWarnings turns too many into an error.
+type Warning struct {
+	Kind WarningKind
+	// Field is the field the warning is about ("<import path>.<Type>.<Field>"),
+	// or the type when it concerns a type.
+	Field   string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s (%s)", w.Field, w.Message, w.Kind)
+}
+
+// WarningBudgetError is returned when a model produces more warnings than
+// WithMaxWarnings allows.
+type WarningBudgetError struct {
+	Model    string
+	Max      int
+	Warnings []Warning
+}
+
+func (e *WarningBudgetError) Error() string {
+	list := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		list[i] = w.String()
+	}
+	return fmt.Sprintf("%s: %d warnings exceed the maximum of %d: %s", e.Model, len(e.Warnings), e.Max, strings.Join(list, "; "))
+}
+
+// warn records a warning about field.
+func (rf *reflection) warn(kind WarningKind, field string, format string, args ...any) {
+	rf.warnings = append(rf.warnings, Warning{Kind: kind, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// fieldRef names field of owner in warnings and errors.
+func fieldRef(owner reflect.Type, field reflect.StructField) string {
+	if key := typeKey(owner); key != "" {
+		return key + "." + field.Name
+	}
+	return owner.String() + "." + field.Name
+}
+
+// reportWarnings logs and hands out the warnings of rf and enforces
+// g.maxWarnings.
+func (g *generator) reportWarnings(rf *reflection) error {
+	if len(rf.warnings) == 0 {
+		return nil
+	}
+	l := logport.LoggerFromContext(g.ctx)
+	for _, w := range rf.warnings {
+		l.Warn("Schema generation warning", "kind", string(w.Kind), "field", w.Field, "message", w.Message)
+		if g.warningHandler != nil {
+			g.warningHandler(w)
+		}
+	}
+	if g.limitWarnings && len(rf.warnings) > g.maxWarnings {
+		return &WarningBudgetError{Model: typeName(rf.model), Max: g.maxWarnings, Warnings: rf.warnings}
+	}
+	return nil
+}
+
+// checkFields is the schema pass warning about fields that are skipped or
+// whose type has no schema. It runs last, after markers and tags had their
+// chance to describe a field.
+func checkFields(rf *reflection, s *jsonschema.Schema) error {
+	return rf.forEachStruct(s, func(t reflect.Type, ts *jsonschema.Schema) error {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get(rf.nameTag()), ",")
+			if f.Anonymous || name == "-" {
+				continue
+			}
+			switch {
+			case !f.IsExported() && name != "":
+				rf.warn(SkippedField, fieldRef(t, f), "unexported field has %s tag %q but is not serialized", rf.nameTag(), name)
+			case f.IsExported() && strings.Split(f.Tag.Get("jsonschema"), ",")[0] == "-":
+				rf.warn(SkippedField, fieldRef(t, f), "field is serialized but excluded by its jsonschema tag")
+			}
+		}
+		return rf.forEachField(t, ts, func(fv fieldVisit) error {
+			ft := derefType(fv.field.Type)
+			if ft.Kind() == reflect.Interface && acceptsAnything(fv.schema) {
+				rf.warn(UnmappedType, fieldRef(fv.owner, fv.field), "%s has no schema, any value is accepted", ft)
+			}
+			return nil
+		})
+	})
+}
+
+// acceptsAnything reports whether s places no constraint on the type of a
+// value.
+func acceptsAnything(s *jsonschema.Schema) bool {
+	return s.Type == "" && s.Ref == "" && len(s.AnyOf) == 0 && len(s.OneOf) == 0 &&
+		len(s.AllOf) == 0 && s.Enum == nil && s.Const == nil && s.Not == nil
+}
+
+// schematorDirectives are the known "schemator:" comment directives.
+var schematorDirectives = []string{"ignore", "readonly", "writeonly", "prefix", "values", "ref", "embed", "oneof", "additionalproperties"}
+
+// warnUnknownMarkers warns about kubebuilder validation markers and
+// schemator directives of key that are not understood. Other markers belong
+// to other tools and are ignored silently.
+func (rf *reflection) warnUnknownMarkers(key string, markers []string) {
+	for _, m := range markers {
+		if directive, ok := strings.CutPrefix(m, schematorDirective); ok {
+			name, _, _ := strings.Cut(directive, "=")
+			if !slices.Contains(schematorDirectives, name) {
+				rf.warn(UnknownTag, key, "unknown directive %q", m)
+			}
+			continue
+		}
+		if errors.Is(applyMarker(&jsonschema.Schema{}, m), errUnknownMarker) {
+			rf.warn(UnknownTag, key, "unknown marker +%s", m)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package schemator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// WarnedConfig has one field of every warning kind.
+type WarnedConfig struct {
+	Name string `json:"name" validate:"required,min=1,email|url"`
+	// Port is the listen port.
+	// +kubebuilder:validation:Minimun=1
+	// +optional
+	Port   int    `json:"port"`
+	Extra  any    `json:"extra"`
+	Secret string `json:"secret" jsonschema:"-"`
+	// Mode is the run mode.
+	// schemator:readonyl
+	Mode string `json:"mode"`
+}
+
+func TestWarnings(t *testing.T) {
+	var got []Warning
+	g := NewGenerator(context.Background(), WithWarningHandler(func(w Warning) {
+		got = append(got, w)
+	}))
+	if _, err := g.Generate(WarnedConfig{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	const prefix = "pkt.systems/schemator.WarnedConfig."
+	want := map[Warning]bool{
+		{Kind: UnknownTag, Field: prefix + "Name", Message: `validate rule "email|url" has no JSON Schema equivalent`}:    true,
+		{Kind: UnknownTag, Field: prefix + "Port", Message: "unknown marker +kubebuilder:validation:Minimun=1"}:           true,
+		{Kind: UnknownTag, Field: prefix + "Mode", Message: `unknown directive "schemator:readonyl"`}:                     true,
+		{Kind: UnmappedType, Field: prefix + "Extra", Message: "interface {} has no schema, any value is accepted"}:       true,
+		{Kind: SkippedField, Field: prefix + "Secret", Message: "field is serialized but excluded by its jsonschema tag"}: true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("warnings = %v, want %d", got, len(want))
+	}
+	for _, w := range got {
+		if !want[w] {
+			t.Errorf("unexpected warning %v", w)
+		}
+	}
+}
+
+func TestMaxWarnings(t *testing.T) {
+	_, err := NewGenerator(context.Background(), WithMaxWarnings(5)).Generate(WarnedConfig{})
+	if err != nil {
+		t.Fatalf("Generate() with budget 5 error = %v", err)
+	}
+	_, err = NewGenerator(context.Background(), WithMaxWarnings(4)).Generate(WarnedConfig{})
+	var budget *WarningBudgetError
+	if !errors.As(err, &budget) || budget.Max != 4 || len(budget.Warnings) != 5 {
+		t.Fatalf("Generate() with budget 4 error = %v, want *WarningBudgetError", err)
+	}
+	if _, err := NewGenerator(context.Background(), WithMaxWarnings(0)).Generate(TaggedUser{}); err != nil {
+		t.Fatalf("Generate() of a clean model error = %v", err)
+	}
+	kinds := make([]WarningKind, 0, len(budget.Warnings))
+	for _, w := range budget.Warnings {
+		kinds = append(kinds, w.Kind)
+	}
+	if !slices.Contains(kinds, SkippedField) {
+		t.Fatalf("warning kinds = %v", kinds)
+	}
+}
@@ -0,0 +1,162 @@
+package schemator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// localModule is a module whose source is on disk: a module of a go.work
+// workspace, the main module, or the target of a local replace directive.
+type localModule struct {
+	Path string
+	Dir  string
+}
+
+// findWorkFile returns the go.work file governing dir the way the go command
+// finds it: GOWORK if set, none if GOWORK is off, and else the nearest
+// go.work in dir or a parent. It returns "" without a workspace.
+func findWorkFile(dir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return "", nil
+	case "":
+	default:
+		return gowork, nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		name := filepath.Join(dir, "go.work")
+		if fi, err := os.Stat(name); err == nil && !fi.IsDir() {
+			return name, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// inWorkspace reports whether a go.work file governs dir.
+func inWorkspace(dir string) bool {
+	workFile, err := findWorkFile(dir)
+	return err == nil && workFile != ""
+}
+
+// localModules returns the modules the go command reads from disk in dir:
+// the modules used by the go.work file governing dir, or else the main
+// module of dir, together with the targets of their local replace
+// directives.
+func localModules(dir string) ([]localModule, error) {
+	workFile, err := findWorkFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	var modules []localModule
+	addModule := func(moduleDir string) ([]*modfile.Replace, error) {
+		name := filepath.Join(moduleDir, "go.mod")
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := modfile.Parse(name, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		if f.Module != nil {
+			modules = append(modules, localModule{Path: f.Module.Mod.Path, Dir: moduleDir})
+		}
+		return f.Replace, nil
+	}
+	addReplacements := func(baseDir string, replacements []*modfile.Replace) {
+		for _, r := range replacements {
+			if !modfile.IsDirectoryPath(r.New.Path) {
+				continue
+			}
+			target := r.New.Path
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(baseDir, target)
+			}
+			modules = append(modules, localModule{Path: r.Old.Path, Dir: target})
+		}
+	}
+	if workFile == "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		moduleDir, _, err := findModulePath(absDir)
+		if err != nil {
+			// Outside a module, nothing is read from disk.
+			return nil, nil
+		}
+		replacements, err := addModule(moduleDir)
+		if err != nil {
+			return nil, err
+		}
+		addReplacements(moduleDir, replacements)
+		return modules, nil
+	}
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	workDir := filepath.Dir(workFile)
+	// Replace directives of go.work win over those of the modules.
+	addReplacements(workDir, wf.Replace)
+	for _, use := range wf.Use {
+		moduleDir := use.Path
+		if !filepath.IsAbs(moduleDir) {
+			moduleDir = filepath.Join(workDir, moduleDir)
+		}
+		replacements, err := addModule(moduleDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		addReplacements(moduleDir, replacements)
+	}
+	return modules, nil
+}
+
+// localPackageDir returns the directory of importPath within the module of
+// modules with the longest matching path, if that directory exists and is
+// not part of a nested module. The first of several modules with the same
+// path wins.
+func localPackageDir(modules []localModule, importPath string) (string, bool) {
+	var best *localModule
+	for i, m := range modules {
+		if importPath != m.Path && !strings.HasPrefix(importPath, m.Path+"/") {
+			continue
+		}
+		if best == nil || len(m.Path) > len(best.Path) {
+			best = &modules[i]
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	dir := filepath.Join(best.Dir, filepath.FromSlash(strings.TrimPrefix(importPath, best.Path)))
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", false
+	}
+	for d := dir; d != best.Dir && d != filepath.Dir(d); d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return "", false
+		}
+	}
+	return dir, true
+}
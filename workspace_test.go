@@ -0,0 +1,60 @@
+package schemator
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	// Workspace mode rejects -mod=mod.
+	t.Setenv("GOFLAGS", "")
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.work"), "go 1.22\n\nuse (\n\t./a\n\t./b\n)\n")
+	writeFile(t, filepath.Join(root, "a", "go.mod"), "module example.com/a\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "a", "api", "api.go"), "package api\n")
+	writeFile(t, filepath.Join(root, "a", "nested", "go.mod"), "module example.com/a/nested\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "a", "nested", "nested.go"), "package nested\n")
+	writeFile(t, filepath.Join(root, "b", "go.mod"), "module example.com/b\n\ngo 1.22\n\nreplace example.com/c => ../c\n")
+	writeFile(t, filepath.Join(root, "c", "go.mod"), "module example.com/c\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "c", "c.go"), "package c\n")
+
+	modules, err := localModules(filepath.Join(root, "b"))
+	if err != nil {
+		t.Fatalf("localModules() error = %v", err)
+	}
+	for _, tt := range []struct {
+		importPath string
+		want       string
+	}{
+		{"example.com/a/api", filepath.Join(root, "a", "api")},
+		{"example.com/b", filepath.Join(root, "b")},
+		{"example.com/c", filepath.Join(root, "c")},
+		{"example.com/a/nested", ""},
+		{"example.com/a/missing", ""},
+		{"example.com/d", ""},
+	} {
+		got, ok := localPackageDir(modules, tt.importPath)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("localPackageDir(%s) = %q, %v, want %q", tt.importPath, got, ok, tt.want)
+		}
+	}
+
+	// Sibling modules resolve from the workspace root without a module of
+	// its own.
+	dir, _, err := lookupPackageDir(context.Background(), "example.com/c", root)
+	if err != nil || dir != filepath.Join(root, "c") {
+		t.Errorf("lookupPackageDir() = %q, %v", dir, err)
+	}
+	t.Chdir(root)
+	if _, err := NewGenerator(context.Background()).(*generator).resolveImportPaths(context.Background(), token.Position{}); err != nil {
+		t.Errorf("resolveImportPaths() at the workspace root error = %v", err)
+	}
+
+	t.Setenv("GOWORK", "off")
+	if inWorkspace(root) {
+		t.Error("inWorkspace() with GOWORK=off = true")
+	}
+}
@@ -0,0 +1,67 @@
+package schemator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	"pkt.systems/logport"
+)
+
+// WriteFS is a file system WriteSchemasFS writes to, such as an in-memory
+// file system in tests, a *zip.Writer or a cloud storage adapter. Names are
+// slash-separated paths as in io/fs. Writers that are also io.Closers are
+// closed once the file is written.
+type WriteFS interface {
+	Create(name string) (io.Writer, error)
+}
+
+func (g *generator) WriteSchemasFS(fsys WriteFS, dir string, models ...any) error {
+	l := logport.LoggerFromContext(g.ctx).With("dir", dir, "models", models)
+	filenames, err := g.schemaFilenames(models)
+	if err != nil {
+		return err
+	}
+	results, genErr := g.generateEach(models, filenames, "")
+	if results == nil {
+		return genErr
+	}
+	for i, model := range models {
+		filename := filenames[i]
+		if filename == "" {
+			l.Debug("Unable to reflect filename (string) from model (any), skipping", "model", model)
+			continue
+		}
+		if results[i].err != nil {
+			continue
+		}
+		out := results[i].out
+		if err := g.ctxErr(); err != nil {
+			return err
+		}
+		name, content := path.Join(dir, filename), g.fileContent(out)
+		_, span := g.startSpan(g.ctx, "schemator.Write", attribute.String("schemator.file", name), attribute.Int("schemator.bytes", len(content)))
+		err := writeFS(fsys, name, content)
+		endSpan(span, err)
+		if err != nil {
+			return errors.Join(genErr, fmt.Errorf("%s: %w", filename, err))
+		}
+		g.written(name, len(content))
+	}
+	return genErr
+}
+
+// writeFS writes data to name in fsys.
+func writeFS(fsys WriteFS, name string, data []byte) error {
+	w, err := fsys.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	if c, ok := w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
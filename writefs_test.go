@@ -0,0 +1,56 @@
+package schemator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// memFS is a WriteFS keeping files in memory.
+type memFS map[string]*bytes.Buffer
+
+func (m memFS) Create(name string) (io.Writer, error) {
+	m[name] = new(bytes.Buffer)
+	return m[name], nil
+}
+
+func TestWriteSchemasFS(t *testing.T) {
+	gen := NewGenerator(context.Background())
+	fsys := memFS{}
+	if err := gen.WriteSchemasFS(fsys, "schemas", OrderedBase{}, OrderedRecord{}); err != nil {
+		t.Fatalf("WriteSchemasFS() error = %v", err)
+	}
+	want, err := gen.Generate(OrderedBase{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fsys["schemas/OrderedBase.schema.json"]; got == nil || got.String() != string(want)+"\n" {
+		t.Errorf("OrderedBase.schema.json = %v", got)
+	}
+	if _, ok := fsys["schemas/OrderedRecord.schema.json"]; !ok || len(fsys) != 2 {
+		t.Errorf("files = %v", fsys)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := gen.WriteSchemasFS(zw, "", OrderedBase{}); err != nil {
+		t.Fatalf("WriteSchemasFS(zip) error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "OrderedBase.schema.json" {
+		t.Errorf("zip files = %s", got)
+	}
+}
@@ -0,0 +1,380 @@
+package schemator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	xmlNameType = reflect.TypeOf(xml.Name{})
+)
+
+type xsdSchema struct {
+	XMLName            xml.Name         `xml:"xs:schema"`
+	XMLNS              string           `xml:"xmlns:xs,attr"`
+	TargetNamespace    string           `xml:"targetNamespace,attr,omitempty"`
+	XMLNSTarget        string           `xml:"xmlns,attr,omitempty"`
+	ElementFormDefault string           `xml:"elementFormDefault,attr,omitempty"`
+	Elements           []xsdElement     `xml:"xs:element"`
+	ComplexTypes       []xsdComplexType `xml:"xs:complexType"`
+}
+
+type xsdAnnotation struct {
+	Documentation string `xml:"xs:documentation"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr,omitempty"`
+	Type        string          `xml:"type,attr,omitempty"`
+	MinOccurs   string          `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs   string          `xml:"maxOccurs,attr,omitempty"`
+	Annotation  *xsdAnnotation  `xml:"xs:annotation,omitempty"`
+	ComplexType *xsdComplexType `xml:"xs:complexType,omitempty"`
+}
+
+type xsdAttribute struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Use        string         `xml:"use,attr,omitempty"`
+	Annotation *xsdAnnotation `xml:"xs:annotation,omitempty"`
+}
+
+type xsdAny struct {
+	MinOccurs string `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs string `xml:"maxOccurs,attr,omitempty"`
+	Process   string `xml:"processContents,attr,omitempty"`
+}
+
+type xsdSequence struct {
+	Elements []xsdElement `xml:"xs:element"`
+	Any      *xsdAny      `xml:"xs:any,omitempty"`
+}
+
+type xsdExtension struct {
+	Base       string         `xml:"base,attr"`
+	Attributes []xsdAttribute `xml:"xs:attribute"`
+}
+
+type xsdSimpleContent struct {
+	Extension xsdExtension `xml:"xs:extension"`
+}
+
+type xsdComplexType struct {
+	Name          string            `xml:"name,attr,omitempty"`
+	Annotation    *xsdAnnotation    `xml:"xs:annotation,omitempty"`
+	Sequence      *xsdSequence      `xml:"xs:sequence,omitempty"`
+	SimpleContent *xsdSimpleContent `xml:"xs:simpleContent,omitempty"`
+	Attributes    []xsdAttribute    `xml:"xs:attribute"`
+}
+
+func (g *generator) GenerateXSD(model any) (SchemaBytes, error) {
+	if model == nil {
+		return nil, fmt.Errorf("cannot generate XSD for nil model")
+	}
+	r, err := g.newReflector(g.ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("XSD root must be a struct, got %s", t)
+	}
+	b := &xsdBuilder{
+		comments: r.CommentMap,
+		types:    make(map[reflect.Type]string),
+		names:    make(map[string]reflect.Type),
+	}
+	typeName, err := b.typeRef(t)
+	if err != nil {
+		return nil, err
+	}
+	namespace, rootName := xmlRootName(t)
+	doc := xsdSchema{
+		XMLNS: xsdNamespace,
+		Elements: []xsdElement{{
+			Name:       rootName,
+			Type:       typeName,
+			Annotation: b.annotation(t, ""),
+		}},
+		ComplexTypes: b.complexTypes,
+	}
+	if namespace != "" {
+		doc.TargetNamespace = namespace
+		doc.XMLNSTarget = namespace
+		doc.ElementFormDefault = "qualified"
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// xsdBuilder collects the named complex types reachable from a root type.
+type xsdBuilder struct {
+	comments     map[string]string
+	types        map[reflect.Type]string
+	names        map[string]reflect.Type
+	complexTypes []xsdComplexType
+}
+
+func (b *xsdBuilder) annotation(t reflect.Type, field string) *xsdAnnotation {
+	if b.comments == nil || t.Name() == "" {
+		return nil
+	}
+	key := t.PkgPath() + "." + t.Name()
+	if field != "" {
+		key += "." + field
+	}
+	if doc := b.comments[key]; doc != "" {
+		return &xsdAnnotation{Documentation: doc}
+	}
+	return nil
+}
+
+// typeRef returns the XSD type name for t, registering a named complex type
+// when t is a struct.
+func (b *xsdBuilder) typeRef(t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name := xsdSimpleType(t); name != "" {
+		return name, nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+	case reflect.Interface:
+		return "xs:anyType", nil
+	default:
+		return "", fmt.Errorf("type %s cannot be represented in XSD", t)
+	}
+	if name, ok := b.types[t]; ok {
+		return name, nil
+	}
+	name := t.Name()
+	if name == "" {
+		return "", fmt.Errorf("anonymous struct types cannot be represented as named XSD types")
+	}
+	if other, taken := b.names[name]; taken && other != t {
+		name = strings.ReplaceAll(t.PkgPath(), "/", "_") + "_" + name
+	}
+	b.types[t] = name
+	b.names[name] = t
+	idx := len(b.complexTypes)
+	b.complexTypes = append(b.complexTypes, xsdComplexType{Name: name})
+	ct, err := b.complexType(t)
+	if err != nil {
+		return "", err
+	}
+	ct.Name = name
+	ct.Annotation = b.annotation(t, "")
+	b.complexTypes[idx] = ct
+	return name, nil
+}
+
+func (b *xsdBuilder) complexType(t reflect.Type) (xsdComplexType, error) {
+	ct := xsdComplexType{}
+	seq := &xsdSequence{}
+	var attrs []xsdAttribute
+	chardata := ""
+	wrappers := make(map[string]*xsdSequence)
+	if err := b.addFields(t, t, seq, &attrs, &chardata, wrappers); err != nil {
+		return ct, err
+	}
+	if chardata != "" {
+		if len(seq.Elements) > 0 || seq.Any != nil {
+			return ct, fmt.Errorf("%s mixes chardata and child elements, which XSD simple content does not allow", t)
+		}
+		ct.SimpleContent = &xsdSimpleContent{Extension: xsdExtension{Base: chardata, Attributes: attrs}}
+		return ct, nil
+	}
+	if len(seq.Elements) > 0 || seq.Any != nil {
+		ct.Sequence = seq
+	}
+	ct.Attributes = attrs
+	return ct, nil
+}
+
+func (b *xsdBuilder) addFields(owner, t reflect.Type, seq *xsdSequence, attrs *[]xsdAttribute, chardata *string, wrappers map[string]*xsdSequence) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type == xmlNameType {
+			continue
+		}
+		tag := f.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := b.addFields(ft, ft, seq, attrs, chardata, wrappers); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if _, local, found := strings.Cut(name, " "); found {
+			name = local
+		}
+		flags := make(map[string]bool)
+		for _, o := range strings.Split(opts, ",") {
+			if o != "" {
+				flags[o] = true
+			}
+		}
+		switch {
+		case flags["innerxml"], flags["comment"]:
+			continue
+		case flags["any"]:
+			seq.Any = &xsdAny{MinOccurs: "0", MaxOccurs: "unbounded", Process: "lax"}
+			continue
+		case flags["chardata"], flags["cdata"]:
+			typ, err := b.typeRef(f.Type)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", owner, f.Name, err)
+			}
+			*chardata = typ
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if flags["attr"] {
+			typ, err := b.typeRef(f.Type)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", owner, f.Name, err)
+			}
+			if !strings.HasPrefix(typ, "xs:") || typ == "xs:anyType" {
+				return fmt.Errorf("%s.%s: attribute must have a simple type, got %s", owner, f.Name, f.Type)
+			}
+			attr := xsdAttribute{Name: name, Type: typ, Annotation: b.annotation(owner, f.Name)}
+			if !flags["omitempty"] && f.Type.Kind() != reflect.Ptr {
+				attr.Use = "required"
+			}
+			*attrs = append(*attrs, attr)
+			continue
+		}
+		el, err := b.element(owner, f, flags["omitempty"])
+		if err != nil {
+			return err
+		}
+		// Nested paths such as `xml:"a>b>c"` produce anonymous wrapper
+		// elements shared by sibling fields with the same prefix.
+		parts := strings.Split(name, ">")
+		el.Name = parts[len(parts)-1]
+		target := seq
+		prefix := ""
+		for _, p := range parts[:len(parts)-1] {
+			prefix += p + ">"
+			w, ok := wrappers[prefix]
+			if !ok {
+				w = &xsdSequence{}
+				target.Elements = append(target.Elements, xsdElement{
+					Name:        p,
+					ComplexType: &xsdComplexType{Sequence: w},
+				})
+				wrappers[prefix] = w
+			}
+			target = w
+		}
+		target.Elements = append(target.Elements, el)
+	}
+	return nil
+}
+
+func (b *xsdBuilder) element(owner reflect.Type, f reflect.StructField, omitempty bool) (xsdElement, error) {
+	el := xsdElement{Annotation: b.annotation(owner, f.Name)}
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr || omitempty {
+		el.MinOccurs = "0"
+	}
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && !isXMLBytes(ft) {
+		el.MinOccurs = "0"
+		el.MaxOccurs = "unbounded"
+		ft = ft.Elem()
+	}
+	typ, err := b.typeRef(ft)
+	if err != nil {
+		return el, fmt.Errorf("%s.%s: %w", owner, f.Name, err)
+	}
+	el.Type = typ
+	return el, nil
+}
+
+// xmlRootName returns the namespace and element name encoding/xml uses for
+// the root element of t.
+func xmlRootName(t reflect.Type) (string, string) {
+	if f, ok := t.FieldByName("XMLName"); ok && f.Type == xmlNameType {
+		name, _, _ := strings.Cut(f.Tag.Get("xml"), ",")
+		if ns, local, found := strings.Cut(name, " "); found {
+			return ns, local
+		}
+		if name != "" {
+			return "", name
+		}
+	}
+	return "", t.Name()
+}
+
+func xsdSimpleType(t reflect.Type) string {
+	if t == timeType {
+		return "xs:dateTime"
+	}
+	// encoding/xml writes bytes as they are, not base64 encoded.
+	if isXMLBytes(t) {
+		return "xs:string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "xs:string"
+	case reflect.Bool:
+		return "xs:boolean"
+	case reflect.Int8:
+		return "xs:byte"
+	case reflect.Int16:
+		return "xs:short"
+	case reflect.Int32:
+		return "xs:int"
+	case reflect.Int, reflect.Int64:
+		return "xs:long"
+	case reflect.Uint8:
+		return "xs:unsignedByte"
+	case reflect.Uint16:
+		return "xs:unsignedShort"
+	case reflect.Uint32:
+		return "xs:unsignedInt"
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return "xs:unsignedLong"
+	case reflect.Float32:
+		return "xs:float"
+	case reflect.Float64:
+		return "xs:double"
+	}
+	return ""
+}
+
+// isXMLBytes reports whether t is a slice or array of bytes, which
+// encoding/xml writes as character data instead of repeated elements.
+func isXMLBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
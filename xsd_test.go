@@ -0,0 +1,105 @@
+package schemator
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// XSDOrder is an order submitted over SOAP.
+type XSDOrder struct {
+	XMLName xml.Name `xml:"urn:example:orders Order"`
+	// ID identifies the order.
+	ID       string      `xml:"id,attr"`
+	Currency string      `xml:"currency,attr,omitempty"`
+	Placed   time.Time   `xml:"placed"`
+	Lines    []XSDLine   `xml:"lines>line"`
+	Note     *string     `xml:"note"`
+	Amount   XSDAmount   `xml:"amount"`
+	Internal string      `xml:"-"`
+	Raw      string      `xml:",innerxml"`
+	Extra    interface{} `xml:"extra,omitempty"`
+}
+
+type XSDLine struct {
+	SKU      string `xml:"sku"`
+	Quantity uint16 `xml:"quantity"`
+}
+
+type XSDAmount struct {
+	Unit  string  `xml:"unit,attr"`
+	Value float64 `xml:",chardata"`
+}
+
+func TestGenerateXSD(t *testing.T) {
+	gen := New(context.Background(), nil)
+	out, err := gen.GenerateXSD(XSDOrder{})
+	if err != nil {
+		t.Fatalf("GenerateXSD() error = %v", err)
+	}
+	doc := out.String()
+	for _, want := range []string{
+		`targetNamespace="urn:example:orders"`,
+		`<xs:element name="Order" type="XSDOrder">`,
+		`<xs:attribute name="id" type="xs:string" use="required">`,
+		`<xs:attribute name="currency" type="xs:string"></xs:attribute>`,
+		`<xs:element name="placed" type="xs:dateTime">`,
+		`<xs:element name="lines">`,
+		`<xs:element name="line" type="XSDLine" minOccurs="0" maxOccurs="unbounded">`,
+		`<xs:element name="note" type="xs:string" minOccurs="0">`,
+		`<xs:extension base="xs:double">`,
+		`<xs:element name="quantity" type="xs:unsignedShort">`,
+		`<xs:documentation>ID identifies the order.</xs:documentation>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Fatalf("XSD missing %q:\n%s", want, doc)
+		}
+	}
+	for _, unwanted := range []string{"Internal", "Raw"} {
+		if strings.Contains(doc, unwanted) {
+			t.Fatalf("XSD should not mention %q:\n%s", unwanted, doc)
+		}
+	}
+	var parsed struct{}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("generated XSD is not well-formed XML: %v", err)
+	}
+}
+
+func TestGenerateXSDBytes(t *testing.T) {
+	type XSDBlob struct {
+		Data     []byte  `xml:"data"`
+		Checksum [4]byte `xml:"checksum"`
+	}
+	out, err := New(context.Background(), nil).GenerateXSD(XSDBlob{})
+	if err != nil {
+		t.Fatalf("GenerateXSD() error = %v", err)
+	}
+	for _, want := range []string{
+		`<xs:element name="data" type="xs:string">`,
+		`<xs:element name="checksum" type="xs:string">`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("XSD missing %q:\n%s", want, out)
+		}
+	}
+	// The XSD describes what encoding/xml writes: the bytes as they are.
+	doc, err := xml.Marshal(XSDBlob{Data: []byte("hello"), Checksum: [4]byte{'a', 'b', 'c', 'd'}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<XSDBlob><data>hello</data><checksum>abcd</checksum></XSDBlob>"; string(doc) != want {
+		t.Errorf("xml.Marshal() = %s, want %s", doc, want)
+	}
+}
+
+func TestGenerateXSDRejectsMaps(t *testing.T) {
+	type withMap struct {
+		Labels map[string]string `xml:"labels"`
+	}
+	if _, err := New(context.Background(), nil).GenerateXSD(withMap{}); err == nil {
+		t.Fatalf("GenerateXSD() error = nil, want error for map field")
+	}
+}